@@ -1,12 +1,33 @@
 package videocatalog
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
 	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
 	"github.com/krelinga/video-catalog/vcrest"
 	"github.com/oapi-codegen/nullable"
 	openapi_types "github.com/oapi-codegen/runtime/types"
@@ -18,8 +39,10 @@ import (
 func TestVideoCatalogEnd2End(t *testing.T) {
 	ctx := context.Background()
 
-	serverURL := setup(t, ctx)
-	client, err := vcrest.NewClientWithResponses(serverURL)
+	receiver := newWebhookReceiver(t)
+	issuer := newTokenIssuer(t)
+	serverURL, db := setup(t, ctx, receiver.port, issuer.jwks)
+	client, err := vcrest.NewClientWithResponses(serverURL, withAPIKey(bootstrapAPIKey))
 	if err != nil {
 		t.Fatalf("failed to create client: %v", err)
 	}
@@ -43,13 +66,117 @@ func TestVideoCatalogEnd2End(t *testing.T) {
 	t.Run("ListPlans with multiple plans", func(t *testing.T) {
 		testListPlans(t, ctx, client)
 	})
+
+	t.Run("Search", func(t *testing.T) {
+		testSearch(t, ctx, client)
+	})
+
+	t.Run("Alternate titles", func(t *testing.T) {
+		testAlternateTitles(t, ctx, client)
+	})
+
+	t.Run("People and credits", func(t *testing.T) {
+		testCredits(t, ctx, client)
+	})
+
+	t.Run("Tags, genres and collections", func(t *testing.T) {
+		testTagsGenresCollections(t, ctx, client)
+	})
+
+	t.Run("Soft delete", func(t *testing.T) {
+		testSoftDelete(t, ctx, client)
+	})
+
+	t.Run("Change history", func(t *testing.T) {
+		testHistory(t, ctx, client)
+	})
+
+	t.Run("Optimistic concurrency", func(t *testing.T) {
+		testOptimisticConcurrency(t, ctx, client)
+	})
+
+	t.Run("Create with server-assigned UUIDs", func(t *testing.T) {
+		testCreateWithServerUUID(t, ctx, client)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		testBatch(t, ctx, client)
+	})
+
+	t.Run("Patch documents", func(t *testing.T) {
+		testPatchDocuments(t, ctx, client, serverURL)
+	})
+
+	t.Run("Expand", func(t *testing.T) {
+		testExpand(t, ctx, client)
+	})
+
+	t.Run("Graph", func(t *testing.T) {
+		testGraph(t, ctx, client)
+	})
+
+	t.Run("Reports", func(t *testing.T) {
+		testReports(t, ctx, client)
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		testStats(t, ctx, client)
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		testChanges(t, ctx, client)
+	})
+
+	t.Run("Webhooks", func(t *testing.T) {
+		testWebhooks(t, ctx, client, receiver)
+	})
+
+	t.Run("Event stream", func(t *testing.T) {
+		testEvents(t, ctx, client)
+	})
+
+	t.Run("RequestValidation", func(t *testing.T) {
+		testRequestValidation(t, ctx, client, serverURL)
+	})
+
+	t.Run("ErrorCodes", func(t *testing.T) {
+		testErrorCodes(t, ctx, client)
+	})
+
+	t.Run("API keys", func(t *testing.T) {
+		testAPIKeys(t, ctx, client, serverURL)
+	})
+
+	t.Run("Bearer tokens", func(t *testing.T) {
+		testBearerTokens(t, ctx, serverURL, issuer)
+	})
+
+	t.Run("Libraries", func(t *testing.T) {
+		testLibraries(t, ctx, client, serverURL)
+	})
+
+	t.Run("Watch state", func(t *testing.T) {
+		testWatchState(t, ctx, client, serverURL)
+	})
+
+	t.Run("Health", func(t *testing.T) {
+		testHealth(t, ctx, serverURL)
+	})
+
+	t.Run("BodySize", func(t *testing.T) {
+		testBodySize(t, ctx, client)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		testPurge(t, ctx, client, db)
+	})
 }
 
 func testGetNonExisting(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
 	nonExistingUUID := openapi_types.UUID(uuid.New())
 
 	// Test GET on non-existing work
-	workResp, err := client.GetWorkWithResponse(ctx, nonExistingUUID)
+	workResp, err := client.GetWorkWithResponse(ctx, nonExistingUUID, nil)
 	if err != nil {
 		t.Fatalf("GetWork failed: %v", err)
 	}
@@ -58,7 +185,7 @@ func testGetNonExisting(t *testing.T, ctx context.Context, client *vcrest.Client
 	}
 
 	// Test GET on non-existing source
-	sourceResp, err := client.GetSourceWithResponse(ctx, nonExistingUUID)
+	sourceResp, err := client.GetSourceWithResponse(ctx, nonExistingUUID, nil)
 	if err != nil {
 		t.Fatalf("GetSource failed: %v", err)
 	}
@@ -67,7 +194,7 @@ func testGetNonExisting(t *testing.T, ctx context.Context, client *vcrest.Client
 	}
 
 	// Test GET on non-existing plan
-	planResp, err := client.GetPlanWithResponse(ctx, nonExistingUUID)
+	planResp, err := client.GetPlanWithResponse(ctx, nonExistingUUID, nil)
 	if err != nil {
 		t.Fatalf("GetPlan failed: %v", err)
 	}
@@ -82,7 +209,7 @@ func testWorkCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		workUUID := openapi_types.UUID(uuid.New())
 
 		// PUT MovieWork
-		putResp, err := client.PutMovieWorkWithResponse(ctx, workUUID, vcrest.PutMovieWorkJSONRequestBody{
+		putResp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
 			Title:       nullable.NewNullableWithValue("The Matrix"),
 			ReleaseYear: nullable.NewNullableWithValue(int32(1999)),
 			TmdbId:      nullable.NewNullableWithValue(int32(603)),
@@ -95,7 +222,7 @@ func testWorkCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// GET MovieWork
-		getResp, err := client.GetWorkWithResponse(ctx, workUUID)
+		getResp, err := client.GetWorkWithResponse(ctx, workUUID, nil)
 		if err != nil {
 			t.Fatalf("GetWork failed: %v", err)
 		}
@@ -113,7 +240,7 @@ func testWorkCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// PATCH MovieWork
-		patchResp, err := client.PatchMovieWorkWithResponse(ctx, workUUID, vcrest.PatchMovieWorkJSONRequestBody{
+		patchResp, err := client.PatchMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PatchMovieWorkJSONRequestBody{
 			ReleaseYear: nullable.NewNullableWithValue(int32(1998)),
 			TmdbId:      nullable.NewNullableWithValue(int32(604)),
 		})
@@ -125,7 +252,7 @@ func testWorkCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// GET again to verify PATCH
-		getResp2, err := client.GetWorkWithResponse(ctx, workUUID)
+		getResp2, err := client.GetWorkWithResponse(ctx, workUUID, nil)
 		if err != nil {
 			t.Fatalf("GetWork after PATCH failed: %v", err)
 		}
@@ -148,7 +275,7 @@ func testWorkCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		workUUID := openapi_types.UUID(uuid.New())
 
 		// PUT MovieEdition
-		putResp, err := client.PutMovieEditionWithResponse(ctx, workUUID, vcrest.PutMovieEditionJSONRequestBody{
+		putResp, err := client.PutMovieEditionWithResponse(ctx, workUUID, nil, vcrest.PutMovieEditionJSONRequestBody{
 			EditionType: nullable.NewNullableWithValue("Director's Cut"),
 		})
 		if err != nil {
@@ -159,7 +286,7 @@ func testWorkCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// GET MovieEdition
-		getResp, err := client.GetWorkWithResponse(ctx, workUUID)
+		getResp, err := client.GetWorkWithResponse(ctx, workUUID, nil)
 		if err != nil {
 			t.Fatalf("GetWork failed: %v", err)
 		}
@@ -174,7 +301,7 @@ func testWorkCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// PATCH MovieEdition
-		patchResp, err := client.PatchMovieEditionWithResponse(ctx, workUUID, vcrest.PatchMovieEditionJSONRequestBody{
+		patchResp, err := client.PatchMovieEditionWithResponse(ctx, workUUID, nil, vcrest.PatchMovieEditionJSONRequestBody{
 			EditionType: nullable.NewNullableWithValue("Extended Edition"),
 		})
 		if err != nil {
@@ -185,7 +312,7 @@ func testWorkCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// GET again to verify PATCH
-		getResp2, err := client.GetWorkWithResponse(ctx, workUUID)
+		getResp2, err := client.GetWorkWithResponse(ctx, workUUID, nil)
 		if err != nil {
 			t.Fatalf("GetWork after PATCH failed: %v", err)
 		}
@@ -201,7 +328,7 @@ func testSourceCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWith
 		sourceUUID := openapi_types.UUID(uuid.New())
 
 		// PUT FileSource
-		putResp, err := client.PutFileSourceWithResponse(ctx, sourceUUID, vcrest.PutFileSourceJSONRequestBody{
+		putResp, err := client.PutFileSourceWithResponse(ctx, sourceUUID, nil, vcrest.PutFileSourceJSONRequestBody{
 			Path: nullable.NewNullableWithValue("/media/movies/matrix.mkv"),
 		})
 		if err != nil {
@@ -212,7 +339,7 @@ func testSourceCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWith
 		}
 
 		// GET FileSource
-		getResp, err := client.GetSourceWithResponse(ctx, sourceUUID)
+		getResp, err := client.GetSourceWithResponse(ctx, sourceUUID, nil)
 		if err != nil {
 			t.Fatalf("GetSource failed: %v", err)
 		}
@@ -227,7 +354,7 @@ func testSourceCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWith
 		}
 
 		// PATCH FileSource
-		patchResp, err := client.PatchFileSourceWithResponse(ctx, sourceUUID, vcrest.PatchFileSourceJSONRequestBody{
+		patchResp, err := client.PatchFileSourceWithResponse(ctx, sourceUUID, nil, vcrest.PatchFileSourceJSONRequestBody{
 			Path: nullable.NewNullableWithValue("/media/movies/matrix_remastered.mkv"),
 		})
 		if err != nil {
@@ -238,7 +365,7 @@ func testSourceCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWith
 		}
 
 		// GET again to verify PATCH
-		getResp2, err := client.GetSourceWithResponse(ctx, sourceUUID)
+		getResp2, err := client.GetSourceWithResponse(ctx, sourceUUID, nil)
 		if err != nil {
 			t.Fatalf("GetSource after PATCH failed: %v", err)
 		}
@@ -252,7 +379,7 @@ func testSourceCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWith
 		sourceUUID := openapi_types.UUID(uuid.New())
 
 		// PUT DiscSource
-		putResp, err := client.PutDiscSourceWithResponse(ctx, sourceUUID, vcrest.PutDiscSourceJSONRequestBody{
+		putResp, err := client.PutDiscSourceWithResponse(ctx, sourceUUID, nil, vcrest.PutDiscSourceJSONRequestBody{
 			OrigDirName:   nullable.NewNullableWithValue("MATRIX_DISC"),
 			Path:          nullable.NewNullableWithValue("/media/discs/matrix"),
 			AllFilesAdded: nullable.NewNullableWithValue(false),
@@ -265,7 +392,7 @@ func testSourceCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWith
 		}
 
 		// GET DiscSource
-		getResp, err := client.GetSourceWithResponse(ctx, sourceUUID)
+		getResp, err := client.GetSourceWithResponse(ctx, sourceUUID, nil)
 		if err != nil {
 			t.Fatalf("GetSource failed: %v", err)
 		}
@@ -280,7 +407,7 @@ func testSourceCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWith
 		}
 
 		// PATCH DiscSource
-		patchResp, err := client.PatchDiscSourceWithResponse(ctx, sourceUUID, vcrest.PatchDiscSourceJSONRequestBody{
+		patchResp, err := client.PatchDiscSourceWithResponse(ctx, sourceUUID, nil, vcrest.PatchDiscSourceJSONRequestBody{
 			AllFilesAdded: nullable.NewNullableWithValue(true),
 		})
 		if err != nil {
@@ -291,7 +418,7 @@ func testSourceCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWith
 		}
 
 		// GET again to verify PATCH
-		getResp2, err := client.GetSourceWithResponse(ctx, sourceUUID)
+		getResp2, err := client.GetSourceWithResponse(ctx, sourceUUID, nil)
 		if err != nil {
 			t.Fatalf("GetSource after PATCH failed: %v", err)
 		}
@@ -309,14 +436,14 @@ func testPlanCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 	workUUID := openapi_types.UUID(uuid.New())
 	sourceUUID := openapi_types.UUID(uuid.New())
 
-	_, err := client.PutMovieWorkWithResponse(ctx, workUUID, vcrest.PutMovieWorkJSONRequestBody{
+	_, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
 		Title: nullable.NewNullableWithValue("Test Movie"),
 	})
 	if err != nil {
 		t.Fatalf("Failed to create work: %v", err)
 	}
 
-	_, err = client.PutFileSourceWithResponse(ctx, sourceUUID, vcrest.PutFileSourceJSONRequestBody{
+	_, err = client.PutFileSourceWithResponse(ctx, sourceUUID, nil, vcrest.PutFileSourceJSONRequestBody{
 		Path: nullable.NewNullableWithValue("/test/path.mkv"),
 	})
 	if err != nil {
@@ -328,7 +455,7 @@ func testPlanCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		planUUID := openapi_types.UUID(uuid.New())
 
 		// PUT DirectPlan
-		putResp, err := client.PutDirectPlanWithResponse(ctx, planUUID, vcrest.PutDirectPlanJSONRequestBody{
+		putResp, err := client.PutDirectPlanWithResponse(ctx, planUUID, nil, vcrest.PutDirectPlanJSONRequestBody{
 			SourceUuid: nullable.NewNullableWithValue(sourceUUID),
 			WorkUuid:   nullable.NewNullableWithValue(workUUID),
 		})
@@ -340,7 +467,7 @@ func testPlanCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// GET DirectPlan
-		getResp, err := client.GetPlanWithResponse(ctx, planUUID)
+		getResp, err := client.GetPlanWithResponse(ctx, planUUID, nil)
 		if err != nil {
 			t.Fatalf("GetPlan failed: %v", err)
 		}
@@ -356,7 +483,7 @@ func testPlanCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 
 		// Create another source for PATCH test
 		newSourceUUID := openapi_types.UUID(uuid.New())
-		_, err = client.PutFileSourceWithResponse(ctx, newSourceUUID, vcrest.PutFileSourceJSONRequestBody{
+		_, err = client.PutFileSourceWithResponse(ctx, newSourceUUID, nil, vcrest.PutFileSourceJSONRequestBody{
 			Path: nullable.NewNullableWithValue("/test/path2.mkv"),
 		})
 		if err != nil {
@@ -364,7 +491,7 @@ func testPlanCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// PATCH DirectPlan
-		patchResp, err := client.PatchDirectPlanWithResponse(ctx, planUUID, vcrest.PatchDirectPlanJSONRequestBody{
+		patchResp, err := client.PatchDirectPlanWithResponse(ctx, planUUID, nil, vcrest.PatchDirectPlanJSONRequestBody{
 			SourceUuid: nullable.NewNullableWithValue(newSourceUUID),
 		})
 		if err != nil {
@@ -375,7 +502,7 @@ func testPlanCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// GET again to verify PATCH
-		getResp2, err := client.GetPlanWithResponse(ctx, planUUID)
+		getResp2, err := client.GetPlanWithResponse(ctx, planUUID, nil)
 		if err != nil {
 			t.Fatalf("GetPlan after PATCH failed: %v", err)
 		}
@@ -392,7 +519,7 @@ func testPlanCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		planUUID := openapi_types.UUID(uuid.New())
 
 		// PUT ChapterRangePlan
-		putResp, err := client.PutChapterRangePlanWithResponse(ctx, planUUID, vcrest.PutChapterRangePlanJSONRequestBody{
+		putResp, err := client.PutChapterRangePlanWithResponse(ctx, planUUID, nil, vcrest.PutChapterRangePlanJSONRequestBody{
 			SourceUuid:   nullable.NewNullableWithValue(sourceUUID),
 			WorkUuid:     nullable.NewNullableWithValue(workUUID),
 			StartChapter: nullable.NewNullableWithValue(int32(1)),
@@ -406,7 +533,7 @@ func testPlanCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// GET ChapterRangePlan
-		getResp, err := client.GetPlanWithResponse(ctx, planUUID)
+		getResp, err := client.GetPlanWithResponse(ctx, planUUID, nil)
 		if err != nil {
 			t.Fatalf("GetPlan failed: %v", err)
 		}
@@ -424,7 +551,7 @@ func testPlanCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// PATCH ChapterRangePlan
-		patchResp, err := client.PatchChapterRangePlanWithResponse(ctx, planUUID, vcrest.PatchChapterRangePlanJSONRequestBody{
+		patchResp, err := client.PatchChapterRangePlanWithResponse(ctx, planUUID, nil, vcrest.PatchChapterRangePlanJSONRequestBody{
 			StartChapter: nullable.NewNullableWithValue(int32(2)),
 			EndChapter:   nullable.NewNullableWithValue(int32(10)),
 		})
@@ -436,7 +563,7 @@ func testPlanCRUD(t *testing.T, ctx context.Context, client *vcrest.ClientWithRe
 		}
 
 		// GET again to verify PATCH
-		getResp2, err := client.GetPlanWithResponse(ctx, planUUID)
+		getResp2, err := client.GetPlanWithResponse(ctx, planUUID, nil)
 		if err != nil {
 			t.Fatalf("GetPlan after PATCH failed: %v", err)
 		}
@@ -456,28 +583,28 @@ func testListPlans(t *testing.T, ctx context.Context, client *vcrest.ClientWithR
 	source1UUID := openapi_types.UUID(uuid.New())
 	source2UUID := openapi_types.UUID(uuid.New())
 
-	_, err := client.PutMovieWorkWithResponse(ctx, work1UUID, vcrest.PutMovieWorkJSONRequestBody{
+	_, err := client.PutMovieWorkWithResponse(ctx, work1UUID, nil, vcrest.PutMovieWorkJSONRequestBody{
 		Title: nullable.NewNullableWithValue("Movie 1"),
 	})
 	if err != nil {
 		t.Fatalf("Failed to create work 1: %v", err)
 	}
 
-	_, err = client.PutMovieWorkWithResponse(ctx, work2UUID, vcrest.PutMovieWorkJSONRequestBody{
+	_, err = client.PutMovieWorkWithResponse(ctx, work2UUID, nil, vcrest.PutMovieWorkJSONRequestBody{
 		Title: nullable.NewNullableWithValue("Movie 2"),
 	})
 	if err != nil {
 		t.Fatalf("Failed to create work 2: %v", err)
 	}
 
-	_, err = client.PutFileSourceWithResponse(ctx, source1UUID, vcrest.PutFileSourceJSONRequestBody{
+	_, err = client.PutFileSourceWithResponse(ctx, source1UUID, nil, vcrest.PutFileSourceJSONRequestBody{
 		Path: nullable.NewNullableWithValue("/media/file1.mkv"),
 	})
 	if err != nil {
 		t.Fatalf("Failed to create source 1: %v", err)
 	}
 
-	_, err = client.PutFileSourceWithResponse(ctx, source2UUID, vcrest.PutFileSourceJSONRequestBody{
+	_, err = client.PutFileSourceWithResponse(ctx, source2UUID, nil, vcrest.PutFileSourceJSONRequestBody{
 		Path: nullable.NewNullableWithValue("/media/file2.mkv"),
 	})
 	if err != nil {
@@ -490,7 +617,7 @@ func testListPlans(t *testing.T, ctx context.Context, client *vcrest.ClientWithR
 	plan3UUID := openapi_types.UUID(uuid.New())
 
 	// Create direct plan
-	_, err = client.PutDirectPlanWithResponse(ctx, plan1UUID, vcrest.PutDirectPlanJSONRequestBody{
+	_, err = client.PutDirectPlanWithResponse(ctx, plan1UUID, nil, vcrest.PutDirectPlanJSONRequestBody{
 		SourceUuid: nullable.NewNullableWithValue(source1UUID),
 		WorkUuid:   nullable.NewNullableWithValue(work1UUID),
 	})
@@ -499,7 +626,7 @@ func testListPlans(t *testing.T, ctx context.Context, client *vcrest.ClientWithR
 	}
 
 	// Create chapter range plan
-	_, err = client.PutChapterRangePlanWithResponse(ctx, plan2UUID, vcrest.PutChapterRangePlanJSONRequestBody{
+	_, err = client.PutChapterRangePlanWithResponse(ctx, plan2UUID, nil, vcrest.PutChapterRangePlanJSONRequestBody{
 		SourceUuid:   nullable.NewNullableWithValue(source2UUID),
 		WorkUuid:     nullable.NewNullableWithValue(work2UUID),
 		StartChapter: nullable.NewNullableWithValue(int32(1)),
@@ -510,7 +637,7 @@ func testListPlans(t *testing.T, ctx context.Context, client *vcrest.ClientWithR
 	}
 
 	// Create another direct plan
-	_, err = client.PutDirectPlanWithResponse(ctx, plan3UUID, vcrest.PutDirectPlanJSONRequestBody{
+	_, err = client.PutDirectPlanWithResponse(ctx, plan3UUID, nil, vcrest.PutDirectPlanJSONRequestBody{
 		SourceUuid: nullable.NewNullableWithValue(source1UUID),
 		WorkUuid:   nullable.NewNullableWithValue(work2UUID),
 	})
@@ -644,67 +771,3813 @@ func testListPlans(t *testing.T, ctx context.Context, client *vcrest.ClientWithR
 	})
 }
 
-// Starts the server container and all dependencies, and returns a URL string that can be used in client connections.
-func setup(t *testing.T, ctx context.Context) string {
-	// Create docker network.
-	net, err := network.New(ctx, network.WithCheckDuplicate())
+func testSearch(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	workUUID := openapi_types.UUID(uuid.New())
+	sourceUUID := openapi_types.UUID(uuid.New())
+
+	_, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("Zyzzogeton Returns"),
+	})
 	if err != nil {
-		t.Fatalf("failed to create network: %v", err)
+		t.Fatalf("Failed to create work: %v", err)
 	}
-	networkName := net.Name
-
-	// Database configuration
-	const (
-		dbHost = "postgres"
-		dbPort = "5432"
-		dbName = "videocatalog"
-		dbUser = "videocataloguser"
-		dbPass = "videocatalogpass"
-	)
 
-	// Start Postgres container
-	postgresReq := testcontainers.ContainerRequest{
-		Image:        "postgres:16",
-		ExposedPorts: []string{"5432/tcp"},
-		Env: map[string]string{
-			"POSTGRES_DB":       dbName,
-			"POSTGRES_USER":     dbUser,
-			"POSTGRES_PASSWORD": dbPass,
-		},
-		Networks:       []string{networkName},
-		NetworkAliases: map[string][]string{networkName: {dbHost}},
-		WaitingFor:     wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
-	}
-	postgresContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: postgresReq,
-		Started:          true,
+	_, err = client.PutDiscSourceWithResponse(ctx, sourceUUID, nil, vcrest.PutDiscSourceJSONRequestBody{
+		OrigDirName:   nullable.NewNullableWithValue("ZYZZOGETON_RETURNS"),
+		Path:          nullable.NewNullableWithValue("/nas/media/ZYZZOGETON_RETURNS"),
+		AllFilesAdded: nullable.NewNullableWithValue(true),
 	})
 	if err != nil {
-		t.Fatalf("failed to start postgres container: %v", err)
+		t.Fatalf("Failed to create source: %v", err)
 	}
-	t.Cleanup(func() {
-		dumpContainerLogs(t, ctx, postgresContainer, dbHost)
+
+	t.Run("MatchesWorksAndSources", func(t *testing.T) {
+		searchResp, err := client.SearchWithResponse(ctx, &vcrest.SearchParams{
+			Q: "zyzzogeton",
+		})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if searchResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for Search, got %d: %s", searchResp.StatusCode(), string(searchResp.Body))
+		}
+
+		foundWork := false
+		foundSource := false
+		for _, result := range searchResp.JSON200.Results {
+			if result.Uuid == workUUID {
+				foundWork = true
+				if result.Work == nil || result.Work.Movie == nil {
+					t.Error("Expected movie work in search result")
+				}
+				if result.Highlight != "<b>Zyzzogeton</b> Returns" {
+					t.Errorf("Unexpected highlight: %q", result.Highlight)
+				}
+			}
+			if result.Uuid == sourceUUID {
+				foundSource = true
+				if result.Source == nil || result.Source.Disc == nil {
+					t.Error("Expected disc source in search result")
+				}
+			}
+		}
+		if !foundWork {
+			t.Error("Expected to find work in search results")
+		}
+		if !foundSource {
+			t.Error("Expected to find source in search results")
+		}
 	})
 
-	// Build and start the server container
-	serverReq := testcontainers.ContainerRequest{
-		FromDockerfile: testcontainers.FromDockerfile{
-			Context:    ".",
-			Dockerfile: "Dockerfile",
-			BuildArgs:  map[string]*string{},
-		},
-		ExposedPorts: []string{"8080/tcp"},
-		Env: map[string]string{
-			"VC_SERVER_PORT": "8080",
-			"VC_DB_HOST":     dbHost,
-			"VC_DB_PORT":     dbPort,
-			"VC_DB_NAME":     dbName,
-			"VC_DB_USER":     dbUser,
-			"VC_DB_PASSWORD": dbPass,
-		},
-		Networks:       []string{networkName},
-		NetworkAliases: map[string][]string{networkName: {"server"}},
-		WaitingFor:     wait.ForLog("Starting HTTP server on port 8080"),
+	t.Run("Pagination", func(t *testing.T) {
+		pageSize := int32(1)
+		searchResp, err := client.SearchWithResponse(ctx, &vcrest.SearchParams{
+			Q:        "zyzzogeton",
+			PageSize: &pageSize,
+		})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if searchResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for Search, got %d", searchResp.StatusCode())
+		}
+		if len(searchResp.JSON200.Results) != 1 {
+			t.Fatalf("Expected 1 result on first page, got %d", len(searchResp.JSON200.Results))
+		}
+		if searchResp.JSON200.NextPageToken == nil {
+			t.Fatal("Expected next page token")
+		}
+
+		searchResp2, err := client.SearchWithResponse(ctx, &vcrest.SearchParams{
+			Q:         "zyzzogeton",
+			PageSize:  &pageSize,
+			PageToken: searchResp.JSON200.NextPageToken,
+		})
+		if err != nil {
+			t.Fatalf("Search second page failed: %v", err)
+		}
+		if searchResp2.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for second page, got %d", searchResp2.StatusCode())
+		}
+		if len(searchResp2.JSON200.Results) != 1 {
+			t.Fatalf("Expected 1 result on second page, got %d", len(searchResp2.JSON200.Results))
+		}
+		if searchResp.JSON200.Results[0].Uuid == searchResp2.JSON200.Results[0].Uuid {
+			t.Error("Expected different results on second page")
+		}
+	})
+
+	t.Run("EmptyQuery", func(t *testing.T) {
+		searchResp, err := client.SearchWithResponse(ctx, &vcrest.SearchParams{
+			Q: " ",
+		})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if searchResp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for empty query, got %d", searchResp.StatusCode())
+		}
+	})
+}
+
+func testAlternateTitles(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	workUUID := openapi_types.UUID(uuid.New())
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		putResp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title:         nullable.NewNullableWithValue("The Qwxlurian Affair"),
+			OriginalTitle: nullable.NewNullableWithValue("L'Affaire Qwxlurienne"),
+			SortTitle:     nullable.NewNullableWithValue("Qwxlurian Affair, The"),
+			AlternateTitles: nullable.NewNullableWithValue([]vcrest.AlternateTitle{
+				{
+					Title:    nullable.NewNullableWithValue("Die Vrokthar-Affäre"),
+					Language: nullable.NewNullableWithValue("de"),
+					Region:   nullable.NewNullableWithValue("DE"),
+				},
+			}),
+		})
+		if err != nil {
+			t.Fatalf("PutMovieWork failed: %v", err)
+		}
+		if putResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201 for PUT, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+		}
+
+		getResp, err := client.GetWorkWithResponse(ctx, workUUID, nil)
+		if err != nil {
+			t.Fatalf("GetWork failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for GET, got %d", getResp.StatusCode())
+		}
+		movie := getResp.JSON200.Movie
+		if movie.OriginalTitle.MustGet() != "L'Affaire Qwxlurienne" {
+			t.Errorf("Expected original title 'L'Affaire Qwxlurienne', got '%s'", movie.OriginalTitle.MustGet())
+		}
+		if movie.SortTitle.MustGet() != "Qwxlurian Affair, The" {
+			t.Errorf("Expected sort title 'Qwxlurian Affair, The', got '%s'", movie.SortTitle.MustGet())
+		}
+		alternateTitles := movie.AlternateTitles.MustGet()
+		if len(alternateTitles) != 1 {
+			t.Fatalf("Expected 1 alternate title, got %d", len(alternateTitles))
+		}
+		if alternateTitles[0].Language.MustGet() != "de" || alternateTitles[0].Region.MustGet() != "DE" {
+			t.Errorf("Unexpected alternate title language/region: %s/%s", alternateTitles[0].Language.MustGet(), alternateTitles[0].Region.MustGet())
+		}
+
+		// PATCH clearing the sort title
+		patchResp, err := client.PatchMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PatchMovieWorkJSONRequestBody{
+			SortTitle: nullable.NewNullNullable[string](),
+		})
+		if err != nil {
+			t.Fatalf("PatchMovieWork failed: %v", err)
+		}
+		if patchResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for PATCH, got %d: %s", patchResp.StatusCode(), string(patchResp.Body))
+		}
+
+		getResp2, err := client.GetWorkWithResponse(ctx, workUUID, nil)
+		if err != nil {
+			t.Fatalf("GetWork after PATCH failed: %v", err)
+		}
+		if getResp2.JSON200.Movie.SortTitle.IsSpecified() {
+			t.Error("Expected sort title to be cleared")
+		}
+		if len(getResp2.JSON200.Movie.AlternateTitles.MustGet()) != 1 {
+			t.Error("Expected alternate titles to be unchanged")
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		putResp, err := client.PutMovieWorkWithResponse(ctx, openapi_types.UUID(uuid.New()), nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Valid Title"),
+			AlternateTitles: nullable.NewNullableWithValue([]vcrest.AlternateTitle{
+				{Title: nullable.NewNullableWithValue("")},
+			}),
+		})
+		if err != nil {
+			t.Fatalf("PutMovieWork failed: %v", err)
+		}
+		if putResp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for empty alternate title, got %d", putResp.StatusCode())
+		}
+
+		patchResp, err := client.PatchMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PatchMovieWorkJSONRequestBody{
+			OriginalTitle: nullable.NewNullableWithValue(""),
+		})
+		if err != nil {
+			t.Fatalf("PatchMovieWork failed: %v", err)
+		}
+		if patchResp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for empty original title, got %d", patchResp.StatusCode())
+		}
+	})
+
+	t.Run("SearchByAlternateTitle", func(t *testing.T) {
+		searchResp, err := client.SearchWithResponse(ctx, &vcrest.SearchParams{
+			Q: "vrokthar",
+		})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if searchResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for Search, got %d", searchResp.StatusCode())
+		}
+		found := false
+		for _, result := range searchResp.JSON200.Results {
+			if result.Uuid == workUUID {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected to find work by alternate title")
+		}
+	})
+
+	t.Run("ListWorksBySortTitle", func(t *testing.T) {
+		firstUUID := openapi_types.UUID(uuid.New())
+		secondUUID := openapi_types.UUID(uuid.New())
+		_, err := client.PutMovieWorkWithResponse(ctx, secondUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Aaaaab Movie"),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create work: %v", err)
+		}
+		_, err = client.PutMovieWorkWithResponse(ctx, firstUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title:     nullable.NewNullableWithValue("The Aaaaaa Movie"),
+			SortTitle: nullable.NewNullableWithValue("Aaaaaa Movie, The"),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create work: %v", err)
+		}
+
+		order := []openapi_types.UUID{}
+		pageSize := int32(2)
+		var pageToken *string
+		for {
+			listResp, err := client.ListWorksWithResponse(ctx, &vcrest.ListWorksParams{
+				PageSize:  &pageSize,
+				PageToken: pageToken,
+			})
+			if err != nil {
+				t.Fatalf("ListWorks failed: %v", err)
+			}
+			if listResp.StatusCode() != 200 {
+				t.Fatalf("Expected 200 for ListWorks, got %d", listResp.StatusCode())
+			}
+			for _, work := range listResp.JSON200.Works {
+				if work.Uuid == firstUUID || work.Uuid == secondUUID {
+					order = append(order, work.Uuid)
+				}
+			}
+			if listResp.JSON200.NextPageToken == nil {
+				break
+			}
+			pageToken = listResp.JSON200.NextPageToken
+		}
+		if len(order) != 2 || order[0] != firstUUID || order[1] != secondUUID {
+			t.Errorf("Expected works to be ordered by sort title, got %v", order)
+		}
+	})
+}
+
+func testCredits(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	directorUUID := openapi_types.UUID(uuid.New())
+	actorUUID := openapi_types.UUID(uuid.New())
+	work1UUID := openapi_types.UUID(uuid.New())
+	work2UUID := openapi_types.UUID(uuid.New())
+
+	t.Run("PersonCRUD", func(t *testing.T) {
+		putResp, err := client.PutPersonWithResponse(ctx, directorUUID, vcrest.PutPersonJSONRequestBody{
+			Name:   nullable.NewNullableWithValue("Stanley Kubrick"),
+			TmdbId: nullable.NewNullableWithValue(int32(240)),
+		})
+		if err != nil {
+			t.Fatalf("PutPerson failed: %v", err)
+		}
+		if putResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201 for PUT, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+		}
+
+		patchResp, err := client.PatchPersonWithResponse(ctx, directorUUID, vcrest.PatchPersonJSONRequestBody{
+			TmdbId: nullable.NewNullNullable[int32](),
+		})
+		if err != nil {
+			t.Fatalf("PatchPerson failed: %v", err)
+		}
+		if patchResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for PATCH, got %d: %s", patchResp.StatusCode(), string(patchResp.Body))
+		}
+
+		getResp, err := client.GetPersonWithResponse(ctx, directorUUID)
+		if err != nil {
+			t.Fatalf("GetPerson failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for GET, got %d", getResp.StatusCode())
+		}
+		if getResp.JSON200.Details.Name.MustGet() != "Stanley Kubrick" {
+			t.Errorf("Expected name 'Stanley Kubrick', got '%s'", getResp.JSON200.Details.Name.MustGet())
+		}
+		if getResp.JSON200.Details.TmdbId.IsSpecified() {
+			t.Error("Expected tmdbId to be cleared")
+		}
+
+		putResp, err = client.PutPersonWithResponse(ctx, actorUUID, vcrest.PutPersonJSONRequestBody{
+			Name: nullable.NewNullableWithValue("Jack Nicholson"),
+		})
+		if err != nil {
+			t.Fatalf("PutPerson failed: %v", err)
+		}
+		if putResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201 for PUT, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+		}
+	})
+
+	t.Run("WorkCredits", func(t *testing.T) {
+		_, err := client.PutMovieWorkWithResponse(ctx, work1UUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("The Shining"),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create work 1: %v", err)
+		}
+		_, err = client.PutMovieWorkWithResponse(ctx, work2UUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Barry Lyndon"),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create work 2: %v", err)
+		}
+
+		putResp, err := client.PutWorkCreditsWithResponse(ctx, work1UUID, vcrest.PutWorkCreditsJSONRequestBody{
+			Credits: []vcrest.Credit{
+				{
+					PersonUuid: nullable.NewNullableWithValue(directorUUID),
+					Role:       nullable.NewNullableWithValue("director"),
+				},
+				{
+					PersonUuid:    nullable.NewNullableWithValue(actorUUID),
+					Role:          nullable.NewNullableWithValue("actor"),
+					CharacterName: nullable.NewNullableWithValue("Jack Torrance"),
+					BillingOrder:  nullable.NewNullableWithValue(int32(1)),
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("PutWorkCredits failed: %v", err)
+		}
+		if putResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for PUT, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+		}
+
+		putResp, err = client.PutWorkCreditsWithResponse(ctx, work2UUID, vcrest.PutWorkCreditsJSONRequestBody{
+			Credits: []vcrest.Credit{
+				{
+					PersonUuid: nullable.NewNullableWithValue(directorUUID),
+					Role:       nullable.NewNullableWithValue("director"),
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("PutWorkCredits failed: %v", err)
+		}
+		if putResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for PUT, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+		}
+
+		getResp, err := client.GetWorkCreditsWithResponse(ctx, work1UUID, &vcrest.GetWorkCreditsParams{})
+		if err != nil {
+			t.Fatalf("GetWorkCredits failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for GET, got %d", getResp.StatusCode())
+		}
+		if len(getResp.JSON200.Credits) != 2 {
+			t.Fatalf("Expected 2 credits, got %d", len(getResp.JSON200.Credits))
+		}
+		// Credits are ordered by role, so the actor comes first.
+		actorCredit := getResp.JSON200.Credits[0]
+		if actorCredit.CharacterName.MustGet() != "Jack Torrance" {
+			t.Errorf("Expected character name 'Jack Torrance', got '%s'", actorCredit.CharacterName.MustGet())
+		}
+		if actorCredit.Person == nil || actorCredit.Person.Details.Name.MustGet() != "Jack Nicholson" {
+			t.Error("Expected credited person to be embedded")
+		}
+	})
+
+	t.Run("Filmography", func(t *testing.T) {
+		role := "director"
+		getResp, err := client.GetPersonCreditsWithResponse(ctx, directorUUID, &vcrest.GetPersonCreditsParams{
+			Role: &role,
+		})
+		if err != nil {
+			t.Fatalf("GetPersonCredits failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for GET, got %d", getResp.StatusCode())
+		}
+		if len(getResp.JSON200.Credits) != 2 {
+			t.Fatalf("Expected 2 credits, got %d", len(getResp.JSON200.Credits))
+		}
+		// Filmography is ordered by sort title.
+		if getResp.JSON200.Credits[0].WorkUuid.MustGet() != work2UUID {
+			t.Error("Expected 'Barry Lyndon' to be listed first")
+		}
+		if getResp.JSON200.Credits[0].Work == nil || getResp.JSON200.Credits[0].Work.Movie == nil {
+			t.Error("Expected credited work to be embedded")
+		}
+
+		role = "writer"
+		getResp, err = client.GetPersonCreditsWithResponse(ctx, directorUUID, &vcrest.GetPersonCreditsParams{
+			Role: &role,
+		})
+		if err != nil {
+			t.Fatalf("GetPersonCredits failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for GET, got %d", getResp.StatusCode())
+		}
+		if len(getResp.JSON200.Credits) != 0 {
+			t.Errorf("Expected no writer credits, got %d", len(getResp.JSON200.Credits))
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		putResp, err := client.PutWorkCreditsWithResponse(ctx, work1UUID, vcrest.PutWorkCreditsJSONRequestBody{
+			Credits: []vcrest.Credit{
+				{
+					PersonUuid: nullable.NewNullableWithValue(directorUUID),
+					Role:       nullable.NewNullableWithValue("producer"),
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("PutWorkCredits failed: %v", err)
+		}
+		if putResp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for invalid role, got %d", putResp.StatusCode())
+		}
+
+		putResp, err = client.PutWorkCreditsWithResponse(ctx, work1UUID, vcrest.PutWorkCreditsJSONRequestBody{
+			Credits: []vcrest.Credit{
+				{
+					PersonUuid: nullable.NewNullableWithValue(openapi_types.UUID(uuid.New())),
+					Role:       nullable.NewNullableWithValue("actor"),
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("PutWorkCredits failed: %v", err)
+		}
+		if putResp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for non-existing person, got %d", putResp.StatusCode())
+		}
+
+		getResp, err := client.GetWorkCreditsWithResponse(ctx, work1UUID, &vcrest.GetWorkCreditsParams{})
+		if err != nil {
+			t.Fatalf("GetWorkCredits failed: %v", err)
+		}
+		if len(getResp.JSON200.Credits) != 2 {
+			t.Errorf("Expected failed PUTs to leave credits unchanged, got %d credits", len(getResp.JSON200.Credits))
+		}
+	})
+}
+
+func testTagsGenresCollections(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	work1UUID := openapi_types.UUID(uuid.New())
+	work2UUID := openapi_types.UUID(uuid.New())
+	work3UUID := openapi_types.UUID(uuid.New())
+	sourceUUID := openapi_types.UUID(uuid.New())
+	planUUID := openapi_types.UUID(uuid.New())
+	workTag := "tag-" + uuid.NewString()
+	sourceTag := "tag-" + uuid.NewString()
+
+	for i, workUUID := range []openapi_types.UUID{work1UUID, work2UUID, work3UUID} {
+		_, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title:       nullable.NewNullableWithValue(fmt.Sprintf("Holiday Movie %d", i+1)),
+			ReleaseYear: nullable.NewNullableWithValue(int32(1990 + i)),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create work %d: %v", i+1, err)
+		}
+	}
+	_, err := client.PutFileSourceWithResponse(ctx, sourceUUID, nil, vcrest.PutFileSourceJSONRequestBody{
+		Path: nullable.NewNullableWithValue("/media/holiday.mkv"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	_, err = client.PutDirectPlanWithResponse(ctx, planUUID, nil, vcrest.PutDirectPlanJSONRequestBody{
+		SourceUuid: nullable.NewNullableWithValue(sourceUUID),
+		WorkUuid:   nullable.NewNullableWithValue(work3UUID),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create plan: %v", err)
+	}
+
+	t.Run("Tags", func(t *testing.T) {
+		putResp, err := client.PutWorkTagWithResponse(ctx, work1UUID, workTag)
+		if err != nil {
+			t.Fatalf("PutWorkTag failed: %v", err)
+		}
+		if putResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201 for PUT, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+		}
+		putResp, err = client.PutWorkTagWithResponse(ctx, work1UUID, workTag)
+		if err != nil {
+			t.Fatalf("PutWorkTag failed: %v", err)
+		}
+		if putResp.StatusCode() != 200 {
+			t.Errorf("Expected 200 for repeated PUT, got %d", putResp.StatusCode())
+		}
+		_, err = client.PutWorkTagWithResponse(ctx, work2UUID, workTag)
+		if err != nil {
+			t.Fatalf("PutWorkTag failed: %v", err)
+		}
+		sourcePutResp, err := client.PutSourceTagWithResponse(ctx, sourceUUID, sourceTag)
+		if err != nil {
+			t.Fatalf("PutSourceTag failed: %v", err)
+		}
+		if sourcePutResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201 for PUT, got %d: %s", sourcePutResp.StatusCode(), string(sourcePutResp.Body))
+		}
+
+		getResp, err := client.GetWorkTagsWithResponse(ctx, work1UUID)
+		if err != nil {
+			t.Fatalf("GetWorkTags failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for GET, got %d", getResp.StatusCode())
+		}
+		if len(getResp.JSON200.Tags) != 1 || getResp.JSON200.Tags[0] != workTag {
+			t.Errorf("Expected tags [%s], got %v", workTag, getResp.JSON200.Tags)
+		}
+
+		// Filter works by tag
+		listResp, err := client.ListWorksWithResponse(ctx, &vcrest.ListWorksParams{
+			Tag: &workTag,
+		})
+		if err != nil {
+			t.Fatalf("ListWorks failed: %v", err)
+		}
+		if listResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for ListWorks, got %d", listResp.StatusCode())
+		}
+		if len(listResp.JSON200.Works) != 2 {
+			t.Errorf("Expected 2 tagged works, got %d", len(listResp.JSON200.Works))
+		}
+
+		// Filter plans by the tag of their source
+		plansResp, err := client.ListPlansWithResponse(ctx, &vcrest.ListPlansParams{
+			Tag: &sourceTag,
+		})
+		if err != nil {
+			t.Fatalf("ListPlans failed: %v", err)
+		}
+		if plansResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for ListPlans, got %d", plansResp.StatusCode())
+		}
+		if len(plansResp.JSON200.Plans) != 1 || plansResp.JSON200.Plans[0].Uuid != planUUID {
+			t.Errorf("Expected only plan %s, got %v", planUUID, plansResp.JSON200.Plans)
+		}
+
+		deleteResp, err := client.DeleteWorkTagWithResponse(ctx, work2UUID, workTag)
+		if err != nil {
+			t.Fatalf("DeleteWorkTag failed: %v", err)
+		}
+		if deleteResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for DELETE, got %d", deleteResp.StatusCode())
+		}
+		listResp, err = client.ListWorksWithResponse(ctx, &vcrest.ListWorksParams{
+			Tag: &workTag,
+		})
+		if err != nil {
+			t.Fatalf("ListWorks failed: %v", err)
+		}
+		if len(listResp.JSON200.Works) != 1 || listResp.JSON200.Works[0].Uuid != work1UUID {
+			t.Errorf("Expected only work %s after removing tag, got %v", work1UUID, listResp.JSON200.Works)
+		}
+
+		putResp, err = client.PutWorkTagWithResponse(ctx, openapi_types.UUID(uuid.New()), workTag)
+		if err != nil {
+			t.Fatalf("PutWorkTag failed: %v", err)
+		}
+		if putResp.StatusCode() != 404 {
+			t.Errorf("Expected 404 for non-existing work, got %d", putResp.StatusCode())
+		}
+	})
+
+	t.Run("Genres", func(t *testing.T) {
+		genresResp, err := client.ListGenresWithResponse(ctx)
+		if err != nil {
+			t.Fatalf("ListGenres failed: %v", err)
+		}
+		if genresResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for ListGenres, got %d", genresResp.StatusCode())
+		}
+		if len(genresResp.JSON200.Genres) == 0 {
+			t.Fatal("Expected a non-empty genre vocabulary")
+		}
+
+		putResp, err := client.PutWorkGenreWithResponse(ctx, work1UUID, "comedy")
+		if err != nil {
+			t.Fatalf("PutWorkGenre failed: %v", err)
+		}
+		if putResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201 for PUT, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+		}
+
+		putResp, err = client.PutWorkGenreWithResponse(ctx, work1UUID, "not-a-genre")
+		if err != nil {
+			t.Fatalf("PutWorkGenre failed: %v", err)
+		}
+		if putResp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for unknown genre, got %d", putResp.StatusCode())
+		}
+
+		getResp, err := client.GetWorkGenresWithResponse(ctx, work1UUID)
+		if err != nil {
+			t.Fatalf("GetWorkGenres failed: %v", err)
+		}
+		if len(getResp.JSON200.Genres) != 1 || getResp.JSON200.Genres[0] != "comedy" {
+			t.Errorf("Expected genres [comedy], got %v", getResp.JSON200.Genres)
+		}
+
+		deleteResp, err := client.DeleteWorkGenreWithResponse(ctx, work1UUID, "comedy")
+		if err != nil {
+			t.Fatalf("DeleteWorkGenre failed: %v", err)
+		}
+		if deleteResp.StatusCode() != 200 {
+			t.Errorf("Expected 200 for DELETE, got %d", deleteResp.StatusCode())
+		}
+	})
+
+	t.Run("Collections", func(t *testing.T) {
+		collectionUUID := openapi_types.UUID(uuid.New())
+		putResp, err := client.PutCollectionWithResponse(ctx, collectionUUID, vcrest.PutCollectionJSONRequestBody{
+			Name:        nullable.NewNullableWithValue("Holiday Movies"),
+			Description: nullable.NewNullableWithValue("Movies to watch in December"),
+		})
+		if err != nil {
+			t.Fatalf("PutCollection failed: %v", err)
+		}
+		if putResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201 for PUT, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+		}
+
+		// Append all three works, then move the last one to the front.
+		for _, workUUID := range []openapi_types.UUID{work1UUID, work2UUID, work3UUID} {
+			addResp, err := client.PutCollectionWorkWithResponse(ctx, collectionUUID, workUUID, &vcrest.PutCollectionWorkParams{})
+			if err != nil {
+				t.Fatalf("PutCollectionWork failed: %v", err)
+			}
+			if addResp.StatusCode() != 201 {
+				t.Fatalf("Expected 201 for PUT, got %d: %s", addResp.StatusCode(), string(addResp.Body))
+			}
+		}
+		position := int32(0)
+		moveResp, err := client.PutCollectionWorkWithResponse(ctx, collectionUUID, work3UUID, &vcrest.PutCollectionWorkParams{
+			Position: &position,
+		})
+		if err != nil {
+			t.Fatalf("PutCollectionWork failed: %v", err)
+		}
+		if moveResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for move, got %d: %s", moveResp.StatusCode(), string(moveResp.Body))
+		}
+		deleteResp, err := client.DeleteCollectionWorkWithResponse(ctx, collectionUUID, work1UUID)
+		if err != nil {
+			t.Fatalf("DeleteCollectionWork failed: %v", err)
+		}
+		if deleteResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for DELETE, got %d", deleteResp.StatusCode())
+		}
+
+		getResp, err := client.GetCollectionWithResponse(ctx, collectionUUID)
+		if err != nil {
+			t.Fatalf("GetCollection failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for GET, got %d", getResp.StatusCode())
+		}
+		if getResp.JSON200.Details.Name.MustGet() != "Holiday Movies" {
+			t.Errorf("Expected name 'Holiday Movies', got '%s'", getResp.JSON200.Details.Name.MustGet())
+		}
+		works := getResp.JSON200.Works
+		if len(works) != 2 || works[0].Uuid != work3UUID || works[1].Uuid != work2UUID {
+			t.Errorf("Expected works [%s %s], got %v", work3UUID, work2UUID, works)
+		}
+
+		exportResp, err := client.ExportCollectionWithResponse(ctx, collectionUUID)
+		if err != nil {
+			t.Fatalf("ExportCollection failed: %v", err)
+		}
+		if exportResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for export, got %d", exportResp.StatusCode())
+		}
+		expected := "position,uuid,kind,title,releaseYear,tmdbId\n" +
+			fmt.Sprintf("0,%s,movie,Holiday Movie 3,1992,\n", work3UUID) +
+			fmt.Sprintf("1,%s,movie,Holiday Movie 2,1991,\n", work2UUID)
+		if string(exportResp.Body) != expected {
+			t.Errorf("Unexpected export:\n%s", string(exportResp.Body))
+		}
+
+		listResp, err := client.ListCollectionsWithResponse(ctx)
+		if err != nil {
+			t.Fatalf("ListCollections failed: %v", err)
+		}
+		found := false
+		for _, collection := range listResp.JSON200.Collections {
+			if collection.Uuid == collectionUUID {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected to find collection in list")
+		}
+	})
+}
+
+func testSoftDelete(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	workUUID := openapi_types.UUID(uuid.New())
+	sourceUUID := openapi_types.UUID(uuid.New())
+	planUUID := openapi_types.UUID(uuid.New())
+	includeDeleted := true
+	pageSize := int32(500)
+
+	_, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("Trash Movie"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create work: %v", err)
+	}
+	_, err = client.PutFileSourceWithResponse(ctx, sourceUUID, nil, vcrest.PutFileSourceJSONRequestBody{
+		Path: nullable.NewNullableWithValue("/media/trash.mkv"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	_, err = client.PutDirectPlanWithResponse(ctx, planUUID, nil, vcrest.PutDirectPlanJSONRequestBody{
+		SourceUuid: nullable.NewNullableWithValue(sourceUUID),
+		WorkUuid:   nullable.NewNullableWithValue(workUUID),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create plan: %v", err)
+	}
+
+	t.Run("Work", func(t *testing.T) {
+		deleteResp, err := client.DeleteWorkWithResponse(ctx, workUUID)
+		if err != nil {
+			t.Fatalf("DeleteWork failed: %v", err)
+		}
+		if deleteResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for DELETE, got %d: %s", deleteResp.StatusCode(), string(deleteResp.Body))
+		}
+
+		getResp, err := client.GetWorkWithResponse(ctx, workUUID, nil)
+		if err != nil {
+			t.Fatalf("GetWork failed: %v", err)
+		}
+		if getResp.StatusCode() != 404 {
+			t.Errorf("Expected 404 for deleted work, got %d", getResp.StatusCode())
+		}
+
+		getResp, err = client.GetWorkWithResponse(ctx, workUUID, &vcrest.GetWorkParams{
+			IncludeDeleted: &includeDeleted,
+		})
+		if err != nil {
+			t.Fatalf("GetWork failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 with includeDeleted, got %d", getResp.StatusCode())
+		}
+		if getResp.JSON200.DeletedAt == nil {
+			t.Error("Expected deletedAt to be set")
+		}
+
+		listResp, err := client.ListWorksWithResponse(ctx, &vcrest.ListWorksParams{
+			PageSize: &pageSize,
+		})
+		if err != nil {
+			t.Fatalf("ListWorks failed: %v", err)
+		}
+		for _, work := range listResp.JSON200.Works {
+			if work.Uuid == workUUID {
+				t.Error("Expected deleted work to be excluded from list")
+			}
+		}
+
+		patchResp, err := client.PatchMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PatchMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Renamed"),
+		})
+		if err != nil {
+			t.Fatalf("PatchMovieWork failed: %v", err)
+		}
+		if patchResp.StatusCode() != 404 {
+			t.Errorf("Expected 404 for PATCH of deleted work, got %d", patchResp.StatusCode())
+		}
+
+		deleteResp, err = client.DeleteWorkWithResponse(ctx, workUUID)
+		if err != nil {
+			t.Fatalf("DeleteWork failed: %v", err)
+		}
+		if deleteResp.StatusCode() != 404 {
+			t.Errorf("Expected 404 for repeated DELETE, got %d", deleteResp.StatusCode())
+		}
+
+		restoreResp, err := client.RestoreWorkWithResponse(ctx, workUUID)
+		if err != nil {
+			t.Fatalf("RestoreWork failed: %v", err)
+		}
+		if restoreResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for restore, got %d: %s", restoreResp.StatusCode(), string(restoreResp.Body))
+		}
+		restoreResp, err = client.RestoreWorkWithResponse(ctx, workUUID)
+		if err != nil {
+			t.Fatalf("RestoreWork failed: %v", err)
+		}
+		if restoreResp.StatusCode() != 409 {
+			t.Errorf("Expected 409 for restoring a live work, got %d", restoreResp.StatusCode())
+		}
+
+		getResp, err = client.GetWorkWithResponse(ctx, workUUID, nil)
+		if err != nil {
+			t.Fatalf("GetWork failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for restored work, got %d", getResp.StatusCode())
+		}
+		if getResp.JSON200.DeletedAt != nil {
+			t.Error("Expected deletedAt to be cleared")
+		}
+	})
+
+	t.Run("Source", func(t *testing.T) {
+		deleteResp, err := client.DeleteSourceWithResponse(ctx, sourceUUID)
+		if err != nil {
+			t.Fatalf("DeleteSource failed: %v", err)
+		}
+		if deleteResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for DELETE, got %d", deleteResp.StatusCode())
+		}
+		getResp, err := client.GetSourceWithResponse(ctx, sourceUUID, nil)
+		if err != nil {
+			t.Fatalf("GetSource failed: %v", err)
+		}
+		if getResp.StatusCode() != 404 {
+			t.Errorf("Expected 404 for deleted source, got %d", getResp.StatusCode())
+		}
+
+		// Putting a deleted source again brings it back.
+		putResp, err := client.PutFileSourceWithResponse(ctx, sourceUUID, nil, vcrest.PutFileSourceJSONRequestBody{
+			Path: nullable.NewNullableWithValue("/media/trash.mkv"),
+		})
+		if err != nil {
+			t.Fatalf("PutFileSource failed: %v", err)
+		}
+		if putResp.StatusCode() != 200 {
+			t.Errorf("Expected 200 for PUT, got %d", putResp.StatusCode())
+		}
+		getResp, err = client.GetSourceWithResponse(ctx, sourceUUID, nil)
+		if err != nil {
+			t.Fatalf("GetSource failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Errorf("Expected 200 for re-created source, got %d", getResp.StatusCode())
+		}
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		deleteResp, err := client.DeletePlanWithResponse(ctx, planUUID)
+		if err != nil {
+			t.Fatalf("DeletePlan failed: %v", err)
+		}
+		if deleteResp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for DELETE, got %d", deleteResp.StatusCode())
+		}
+
+		listResp, err := client.ListPlansWithResponse(ctx, &vcrest.ListPlansParams{
+			WorkUuid: &workUUID,
+		})
+		if err != nil {
+			t.Fatalf("ListPlans failed: %v", err)
+		}
+		if len(listResp.JSON200.Plans) != 0 {
+			t.Errorf("Expected no plans, got %d", len(listResp.JSON200.Plans))
+		}
+		listResp, err = client.ListPlansWithResponse(ctx, &vcrest.ListPlansParams{
+			WorkUuid:       &workUUID,
+			IncludeDeleted: &includeDeleted,
+		})
+		if err != nil {
+			t.Fatalf("ListPlans failed: %v", err)
+		}
+		if len(listResp.JSON200.Plans) != 1 || listResp.JSON200.Plans[0].DeletedAt == nil {
+			t.Errorf("Expected one deleted plan, got %v", listResp.JSON200.Plans)
+		}
+
+		restoreResp, err := client.RestorePlanWithResponse(ctx, planUUID)
+		if err != nil {
+			t.Fatalf("RestorePlan failed: %v", err)
+		}
+		if restoreResp.StatusCode() != 200 {
+			t.Errorf("Expected 200 for restore, got %d", restoreResp.StatusCode())
+		}
+	})
+}
+
+func testHistory(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	workUUID := openapi_types.UUID(uuid.New())
+	onBehalfOf := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("X-On-Behalf-Of", "history-tester")
+		req.Header.Set("X-Request-Id", "history-request")
+		return nil
+	}
+
+	putResp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("First Title"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create work: %v", err)
+	}
+	if putResp.HTTPResponse.Header.Get("X-Request-Id") == "" {
+		t.Error("Expected a generated X-Request-Id response header")
+	}
+	_, err = client.PatchMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PatchMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("Second Title"),
+	}, onBehalfOf)
+	if err != nil {
+		t.Fatalf("Failed to patch work: %v", err)
+	}
+
+	historyResp, err := client.GetWorkHistoryWithResponse(ctx, workUUID, nil)
+	if err != nil {
+		t.Fatalf("GetWorkHistory failed: %v", err)
+	}
+	if historyResp.StatusCode() != 200 {
+		t.Fatalf("Expected 200 for history, got %d: %s", historyResp.StatusCode(), string(historyResp.Body))
+	}
+	entries := historyResp.JSON200.Entries
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 history entries, got %d", len(entries))
+	}
+	update, create := entries[0], entries[1]
+	if update.Operation != "update" || create.Operation != "create" {
+		t.Errorf("Expected operations [update create], got [%s %s]", update.Operation, create.Operation)
+	}
+	if create.Before != nil {
+		t.Error("Expected no before version for create")
+	}
+	if update.Before.Movie.Title.MustGet() != "First Title" || update.After.Movie.Title.MustGet() != "Second Title" {
+		t.Errorf("Unexpected update versions: %v -> %v", update.Before.Movie.Title, update.After.Movie.Title)
+	}
+	// The actor is the caller that the API key belongs to, whoever the client says it acts for.
+	if update.Actor == nil || *update.Actor != "apikey:bootstrap" {
+		t.Errorf("Expected actor 'apikey:bootstrap', got %v", update.Actor)
+	}
+	if update.OnBehalfOf == nil || *update.OnBehalfOf != "history-tester" {
+		t.Errorf("Expected on behalf of 'history-tester', got %v", update.OnBehalfOf)
+	}
+	if update.RequestId == nil || *update.RequestId != "history-request" {
+		t.Errorf("Expected request ID 'history-request', got %v", update.RequestId)
+	}
+
+	// Paging
+	pageSize := int32(1)
+	pageResp, err := client.GetWorkHistoryWithResponse(ctx, workUUID, &vcrest.GetWorkHistoryParams{
+		PageSize: &pageSize,
+	})
+	if err != nil {
+		t.Fatalf("GetWorkHistory failed: %v", err)
+	}
+	if len(pageResp.JSON200.Entries) != 1 || pageResp.JSON200.NextPageToken == nil {
+		t.Fatalf("Expected one entry and a next page token")
+	}
+	pageResp, err = client.GetWorkHistoryWithResponse(ctx, workUUID, &vcrest.GetWorkHistoryParams{
+		PageSize:  &pageSize,
+		PageToken: pageResp.JSON200.NextPageToken,
+	})
+	if err != nil {
+		t.Fatalf("GetWorkHistory failed: %v", err)
+	}
+	if len(pageResp.JSON200.Entries) != 1 || pageResp.JSON200.Entries[0].Id != create.Id {
+		t.Errorf("Expected second page to hold the create entry")
+	}
+
+	// Revert to the original version
+	revertResp, err := client.RevertWorkWithResponse(ctx, workUUID, create.Id)
+	if err != nil {
+		t.Fatalf("RevertWork failed: %v", err)
+	}
+	if revertResp.StatusCode() != 200 {
+		t.Fatalf("Expected 200 for revert, got %d: %s", revertResp.StatusCode(), string(revertResp.Body))
+	}
+	getResp, err := client.GetWorkWithResponse(ctx, workUUID, nil)
+	if err != nil {
+		t.Fatalf("GetWork failed: %v", err)
+	}
+	if getResp.JSON200.Movie.Title.MustGet() != "First Title" {
+		t.Errorf("Expected reverted title 'First Title', got '%s'", getResp.JSON200.Movie.Title.MustGet())
+	}
+	historyResp, err = client.GetWorkHistoryWithResponse(ctx, workUUID, nil)
+	if err != nil {
+		t.Fatalf("GetWorkHistory failed: %v", err)
+	}
+	if len(historyResp.JSON200.Entries) != 3 || historyResp.JSON200.Entries[0].Operation != "revert" {
+		t.Errorf("Expected the revert to be recorded, got %v", historyResp.JSON200.Entries)
+	}
+
+	// A history entry of another entity cannot be used.
+	otherUUID := openapi_types.UUID(uuid.New())
+	_, err = client.PutMovieWorkWithResponse(ctx, otherUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("Other Title"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create work: %v", err)
+	}
+	revertResp, err = client.RevertWorkWithResponse(ctx, otherUUID, create.Id)
+	if err != nil {
+		t.Fatalf("RevertWork failed: %v", err)
+	}
+	if revertResp.StatusCode() != 404 {
+		t.Errorf("Expected 404 for foreign history entry, got %d", revertResp.StatusCode())
+	}
+
+	historyResp, err = client.GetWorkHistoryWithResponse(ctx, openapi_types.UUID(uuid.New()), nil)
+	if err != nil {
+		t.Fatalf("GetWorkHistory failed: %v", err)
+	}
+	if historyResp.StatusCode() != 404 {
+		t.Errorf("Expected 404 for non-existing work, got %d", historyResp.StatusCode())
+	}
+}
+
+func testOptimisticConcurrency(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	sourceUUID := openapi_types.UUID(uuid.New())
+	createOnly := "*"
+	disc := vcrest.PutDiscSourceJSONRequestBody{
+		OrigDirName:   nullable.NewNullableWithValue("CONCURRENT_DISC"),
+		Path:          nullable.NewNullableWithValue("/media/discs/concurrent"),
+		AllFilesAdded: nullable.NewNullableWithValue(false),
+	}
+
+	putResp, err := client.PutDiscSourceWithResponse(ctx, sourceUUID, &vcrest.PutDiscSourceParams{
+		IfNoneMatch: &createOnly,
+	}, disc)
+	if err != nil {
+		t.Fatalf("PutDiscSource failed: %v", err)
+	}
+	if putResp.StatusCode() != 201 {
+		t.Fatalf("Expected 201 for create-only PUT, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+	}
+	etag := putResp.HTTPResponse.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag header on PUT")
+	}
+
+	putResp, err = client.PutDiscSourceWithResponse(ctx, sourceUUID, &vcrest.PutDiscSourceParams{
+		IfNoneMatch: &createOnly,
+	}, disc)
+	if err != nil {
+		t.Fatalf("PutDiscSource failed: %v", err)
+	}
+	if putResp.StatusCode() != 412 {
+		t.Errorf("Expected 412 for create-only PUT of existing source, got %d", putResp.StatusCode())
+	}
+
+	getResp, err := client.GetSourceWithResponse(ctx, sourceUUID, nil)
+	if err != nil {
+		t.Fatalf("GetSource failed: %v", err)
+	}
+	if got := getResp.HTTPResponse.Header.Get("ETag"); got != etag {
+		t.Errorf("Expected GET ETag %s, got %s", etag, got)
+	}
+
+	// Two writers start from the same version; only the first one wins.
+	patchResp, err := client.PatchDiscSourceWithResponse(ctx, sourceUUID, &vcrest.PatchDiscSourceParams{
+		IfMatch: &etag,
+	}, vcrest.PatchDiscSourceJSONRequestBody{
+		AllFilesAdded: nullable.NewNullableWithValue(true),
+	})
+	if err != nil {
+		t.Fatalf("PatchDiscSource failed: %v", err)
+	}
+	if patchResp.StatusCode() != 200 {
+		t.Fatalf("Expected 200 for PATCH, got %d: %s", patchResp.StatusCode(), string(patchResp.Body))
+	}
+	newETag := patchResp.HTTPResponse.Header.Get("ETag")
+	if newETag == "" || newETag == etag {
+		t.Errorf("Expected a new ETag after PATCH, got %q", newETag)
+	}
+
+	patchResp, err = client.PatchDiscSourceWithResponse(ctx, sourceUUID, &vcrest.PatchDiscSourceParams{
+		IfMatch: &etag,
+	}, vcrest.PatchDiscSourceJSONRequestBody{
+		Path: nullable.NewNullableWithValue("/media/discs/other"),
+	})
+	if err != nil {
+		t.Fatalf("PatchDiscSource failed: %v", err)
+	}
+	if patchResp.StatusCode() != 412 {
+		t.Errorf("Expected 412 for PATCH with stale ETag, got %d", patchResp.StatusCode())
+	}
+
+	putResp, err = client.PutDiscSourceWithResponse(ctx, sourceUUID, &vcrest.PutDiscSourceParams{
+		IfMatch: &newETag,
+	}, disc)
+	if err != nil {
+		t.Fatalf("PutDiscSource failed: %v", err)
+	}
+	if putResp.StatusCode() != 200 {
+		t.Errorf("Expected 200 for PUT with current ETag, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+	}
+
+	missingResp, err := client.PutDiscSourceWithResponse(ctx, openapi_types.UUID(uuid.New()), &vcrest.PutDiscSourceParams{
+		IfMatch: &createOnly,
+	}, disc)
+	if err != nil {
+		t.Fatalf("PutDiscSource failed: %v", err)
+	}
+	if missingResp.StatusCode() != 412 {
+		t.Errorf("Expected 412 for If-Match on a missing source, got %d", missingResp.StatusCode())
+	}
+}
+
+func testCreateWithServerUUID(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	t.Run("Without idempotency key", func(t *testing.T) {
+		createResp, err := client.CreateMovieWorkWithResponse(ctx, nil, vcrest.CreateMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Posted Movie"),
+		})
+		if err != nil {
+			t.Fatalf("CreateMovieWork failed: %v", err)
+		}
+		if createResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201, got %d: %s", createResp.StatusCode(), string(createResp.Body))
+		}
+		workUUID := createResp.JSON201.Uuid
+		if location := createResp.HTTPResponse.Header.Get("Location"); location != "/works/"+workUUID.String() {
+			t.Errorf("Expected Location /works/%s, got %s", workUUID, location)
+		}
+		if createResp.HTTPResponse.Header.Get("ETag") == "" {
+			t.Error("Expected an ETag header")
+		}
+		if createResp.JSON201.Movie.Title.MustGet() != "Posted Movie" {
+			t.Errorf("Unexpected title in response: %v", createResp.JSON201.Movie.Title)
+		}
+
+		getResp, err := client.GetWorkWithResponse(ctx, workUUID, nil)
+		if err != nil {
+			t.Fatalf("GetWork failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Errorf("Expected 200 for created work, got %d", getResp.StatusCode())
+		}
+
+		createResp, err = client.CreateMovieWorkWithResponse(ctx, nil, vcrest.CreateMovieWorkJSONRequestBody{})
+		if err != nil {
+			t.Fatalf("CreateMovieWork failed: %v", err)
+		}
+		if createResp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for missing title, got %d", createResp.StatusCode())
+		}
+	})
+
+	t.Run("With idempotency key", func(t *testing.T) {
+		key := uuid.NewString()
+		source := vcrest.CreateFileSourceJSONRequestBody{
+			Path: nullable.NewNullableWithValue("/media/posted.mkv"),
+		}
+		firstResp, err := client.CreateFileSourceWithResponse(ctx, &vcrest.CreateFileSourceParams{
+			IdempotencyKey: &key,
+		}, source)
+		if err != nil {
+			t.Fatalf("CreateFileSource failed: %v", err)
+		}
+		if firstResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201, got %d: %s", firstResp.StatusCode(), string(firstResp.Body))
+		}
+
+		retryResp, err := client.CreateFileSourceWithResponse(ctx, &vcrest.CreateFileSourceParams{
+			IdempotencyKey: &key,
+		}, source)
+		if err != nil {
+			t.Fatalf("CreateFileSource failed: %v", err)
+		}
+		if retryResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201 for retry, got %d: %s", retryResp.StatusCode(), string(retryResp.Body))
+		}
+		if retryResp.JSON201.Uuid != firstResp.JSON201.Uuid {
+			t.Errorf("Expected retry to return source %s, got %s", firstResp.JSON201.Uuid, retryResp.JSON201.Uuid)
+		}
+
+		otherResp, err := client.CreateFileSourceWithResponse(ctx, &vcrest.CreateFileSourceParams{
+			IdempotencyKey: &key,
+		}, vcrest.CreateFileSourceJSONRequestBody{
+			Path: nullable.NewNullableWithValue("/media/other.mkv"),
+		})
+		if err != nil {
+			t.Fatalf("CreateFileSource failed: %v", err)
+		}
+		if otherResp.StatusCode() != 422 {
+			t.Errorf("Expected 422 for reused key, got %d", otherResp.StatusCode())
+		}
+
+		// Keys are scoped to the operation.
+		workUUID := openapi_types.UUID(uuid.New())
+		_, err = client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Planned Movie"),
+		})
+		if err != nil {
+			t.Fatalf("PutMovieWork failed: %v", err)
+		}
+		planResp, err := client.CreateDirectPlanWithResponse(ctx, &vcrest.CreateDirectPlanParams{
+			IdempotencyKey: &key,
+		}, vcrest.CreateDirectPlanJSONRequestBody{
+			SourceUuid: nullable.NewNullableWithValue(firstResp.JSON201.Uuid),
+			WorkUuid:   nullable.NewNullableWithValue(workUUID),
+		})
+		if err != nil {
+			t.Fatalf("CreateDirectPlan failed: %v", err)
+		}
+		if planResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201 for plan, got %d: %s", planResp.StatusCode(), string(planResp.Body))
+		}
+		if location := planResp.HTTPResponse.Header.Get("Location"); location != "/plans/"+planResp.JSON201.Uuid.String() {
+			t.Errorf("Unexpected Location %s", location)
+		}
+	})
+}
+
+func testBatch(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	workUUID := uuid.New()
+	sourceUUID := uuid.New()
+	workPath := fmt.Sprintf("/works/%s/movie", workUUID)
+	sourcePath := fmt.Sprintf("/sources/%s/disc", sourceUUID)
+
+	// A successful batch applies every operation.
+	batchResp, err := client.ApplyBatchWithResponse(ctx, vcrest.ApplyBatchJSONRequestBody{
+		Operations: []vcrest.BatchOperation{
+			{
+				Method: "PUT",
+				Path:   workPath,
+				Body: map[string]any{
+					"title":       "Batch Movie",
+					"releaseYear": 2001,
+				},
+			},
+			{
+				Method: "PUT",
+				Path:   sourcePath,
+				Body: map[string]any{
+					"origDirName": "BATCH_DISC",
+				},
+			},
+			{
+				Method: "PATCH",
+				Path:   workPath,
+				Body: map[string]any{
+					"title": "Batch Movie (Renamed)",
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyBatch failed: %v", err)
+	}
+	if batchResp.JSON200 == nil {
+		t.Fatalf("Expected 200 for batch, got %d: %s", batchResp.StatusCode(), string(batchResp.Body))
+	}
+	results := batchResp.JSON200.Results
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	for i, want := range []int32{201, 201, 200} {
+		if results[i].Status != want {
+			t.Errorf("Expected status %d for operation %d, got %d", want, i, results[i].Status)
+		}
+		if results[i].Etag == nil {
+			t.Errorf("Expected an ETag for operation %d", i)
+		}
+	}
+
+	workResp, err := client.GetWorkWithResponse(ctx, workUUID, nil)
+	if err != nil {
+		t.Fatalf("GetWork failed: %v", err)
+	}
+	if workResp.JSON200 == nil || workResp.JSON200.Movie == nil {
+		t.Fatalf("Expected the batch to create the work, got %d", workResp.StatusCode())
+	}
+	if got := workResp.JSON200.Movie.Title.MustGet(); got != "Batch Movie (Renamed)" {
+		t.Errorf("Expected title 'Batch Movie (Renamed)', got '%s'", got)
+	}
+
+	// A failing operation rolls back the whole batch.
+	batchResp, err = client.ApplyBatchWithResponse(ctx, vcrest.ApplyBatchJSONRequestBody{
+		Operations: []vcrest.BatchOperation{
+			{
+				Method: "PATCH",
+				Path:   workPath,
+				Body: map[string]any{
+					"title": "Should Not Stick",
+				},
+			},
+			{
+				Method: "DELETE",
+				Path:   "/sources/" + sourceUUID.String(),
+			},
+			{
+				Method: "DELETE",
+				Path:   "/works/" + uuid.NewString(),
+			},
+			{
+				Method: "DELETE",
+				Path:   "/works/" + workUUID.String(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyBatch failed: %v", err)
+	}
+	if batchResp.JSON422 == nil {
+		t.Fatalf("Expected 422 for failing batch, got %d: %s", batchResp.StatusCode(), string(batchResp.Body))
+	}
+	results = batchResp.JSON422.Results
+	if len(results) != 3 {
+		t.Fatalf("Expected results to stop at the failing operation, got %d results", len(results))
+	}
+	if results[2].Status != 404 || results[2].Error == nil {
+		t.Errorf("Expected 404 with an error for the failing operation, got %+v", results[2])
+	}
+
+	workResp, err = client.GetWorkWithResponse(ctx, workUUID, nil)
+	if err != nil {
+		t.Fatalf("GetWork failed: %v", err)
+	}
+	if workResp.JSON200 == nil || workResp.JSON200.Movie == nil {
+		t.Fatalf("Expected work to still exist, got %d", workResp.StatusCode())
+	}
+	if got := workResp.JSON200.Movie.Title.MustGet(); got != "Batch Movie (Renamed)" {
+		t.Errorf("Expected rolled back title 'Batch Movie (Renamed)', got '%s'", got)
+	}
+	sourceResp, err := client.GetSourceWithResponse(ctx, sourceUUID, nil)
+	if err != nil {
+		t.Fatalf("GetSource failed: %v", err)
+	}
+	if sourceResp.StatusCode() != 200 {
+		t.Errorf("Expected source delete to be rolled back, got %d", sourceResp.StatusCode())
+	}
+
+	// Only changes may be batched.
+	batchResp, err = client.ApplyBatchWithResponse(ctx, vcrest.ApplyBatchJSONRequestBody{
+		Operations: []vcrest.BatchOperation{
+			{Method: "GET", Path: workPath},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyBatch failed: %v", err)
+	}
+	if batchResp.StatusCode() != 400 {
+		t.Errorf("Expected 400 for GET in batch, got %d", batchResp.StatusCode())
+	}
+}
+
+func testPatchDocuments(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses, serverURL string) {
+	workUUID := openapi_types.UUID(uuid.New())
+	putResp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title:       nullable.NewNullableWithValue("Patch Documents"),
+		SortTitle:   nullable.NewNullableWithValue("Documents, Patch"),
+		ReleaseYear: nullable.NewNullableWithValue(int32(1999)),
+	})
+	if err != nil {
+		t.Fatalf("PutMovieWork failed: %v", err)
+	}
+	if putResp.StatusCode() != 201 {
+		t.Fatalf("Expected 201 for PUT, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+	}
+
+	patch := func(contentType, body string, params *vcrest.PatchMovieWorkParams) *vcrest.PatchMovieWorkResponse {
+		t.Helper()
+		resp, err := client.PatchMovieWorkWithBodyWithResponse(ctx, workUUID, params, contentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("PatchMovieWork failed: %v", err)
+		}
+		return resp
+	}
+	getMovie := func() *vcrest.Movie {
+		t.Helper()
+		resp, err := client.GetWorkWithResponse(ctx, workUUID, nil)
+		if err != nil {
+			t.Fatalf("GetWork failed: %v", err)
+		}
+		if resp.JSON200 == nil || resp.JSON200.Movie == nil {
+			t.Fatalf("Expected movie work, got %d", resp.StatusCode())
+		}
+		return resp.JSON200.Movie
+	}
+
+	t.Run("Merge patch", func(t *testing.T) {
+		resp := patch("application/merge-patch+json", `{"title": "Patched Documents", "sortTitle": null}`, nil)
+		if resp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for merge patch, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		if resp.HTTPResponse.Header.Get("ETag") == "" {
+			t.Error("Expected an ETag header on PATCH")
+		}
+		movie := getMovie()
+		if got := movie.Title.MustGet(); got != "Patched Documents" {
+			t.Errorf("Expected title 'Patched Documents', got '%s'", got)
+		}
+		if movie.SortTitle.IsSpecified() && !movie.SortTitle.IsNull() {
+			t.Errorf("Expected sort title to be removed, got '%s'", movie.SortTitle.MustGet())
+		}
+		if got := movie.ReleaseYear.MustGet(); got != 1999 {
+			t.Errorf("Expected release year to be kept, got %d", got)
+		}
+	})
+
+	t.Run("JSON patch", func(t *testing.T) {
+		resp := patch("application/json-patch+json", `[
+			{"op": "test", "path": "/title", "value": "Patched Documents"},
+			{"op": "replace", "path": "/releaseYear", "value": 2000}
+		]`, nil)
+		if resp.StatusCode() != 200 {
+			t.Fatalf("Expected 200 for JSON patch, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		if got := getMovie().ReleaseYear.MustGet(); got != 2000 {
+			t.Errorf("Expected release year 2000, got %d", got)
+		}
+
+		resp = patch("application/json-patch+json", `[
+			{"op": "test", "path": "/title", "value": "Something Else"},
+			{"op": "replace", "path": "/releaseYear", "value": 2001}
+		]`, nil)
+		if resp.StatusCode() != 409 {
+			t.Errorf("Expected 409 for failed test op, got %d", resp.StatusCode())
+		}
+		if got := getMovie().ReleaseYear.MustGet(); got != 2000 {
+			t.Errorf("Expected release year to be unchanged, got %d", got)
+		}
+
+		resp = patch("application/json-patch+json", `[{"op": "frobnicate", "path": "/title"}]`, nil)
+		if resp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for unknown op, got %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("Validated like PUT", func(t *testing.T) {
+		resp := patch("application/merge-patch+json", `{"title": null}`, nil)
+		if resp.StatusCode() != 400 {
+			t.Errorf("Expected 400 when removing the title, got %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("Preconditions", func(t *testing.T) {
+		stale := `"1"`
+		resp := patch("application/merge-patch+json", `{"releaseYear": 2002}`, &vcrest.PatchMovieWorkParams{
+			IfMatch: &stale,
+		})
+		if resp.StatusCode() != 412 {
+			t.Errorf("Expected 412 for stale If-Match, got %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("Wrong kind", func(t *testing.T) {
+		resp, err := client.PatchMovieEditionWithBodyWithResponse(ctx, workUUID, nil, "application/merge-patch+json", strings.NewReader(`{"editionType": "Director's Cut"}`))
+		if err != nil {
+			t.Fatalf("PatchMovieEdition failed: %v", err)
+		}
+		if resp.StatusCode() != 409 {
+			t.Errorf("Expected 409 for patching a movie as a movie edition, got %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		anonymous, err := vcrest.NewClientWithResponses(serverURL)
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		// Existing and missing works get the same answer, so that callers without credentials cannot tell
+		// which UUIDs exist.
+		for _, id := range []openapi_types.UUID{workUUID, openapi_types.UUID(uuid.New())} {
+			resp, err := anonymous.PatchMovieWorkWithBodyWithResponse(ctx, id, nil, "application/merge-patch+json", strings.NewReader(`{"title": "Anonymous"}`))
+			if err != nil {
+				t.Fatalf("PatchMovieWork failed: %v", err)
+			}
+			if resp.StatusCode() != 401 {
+				t.Errorf("Expected 401 for patching %s without credentials, got %d: %s", id, resp.StatusCode(), string(resp.Body))
+			}
+		}
+		if got := getMovie().Title.MustGet(); got == "Anonymous" {
+			t.Error("Expected the unauthenticated patch not to change the title")
+		}
+	})
+
+	t.Run("Read only", func(t *testing.T) {
+		keyResp, err := client.CreateApiKeyWithResponse(ctx, vcrest.CreateApiKeyJSONRequestBody{Name: "patch reader", Scopes: []string{"read"}})
+		if err != nil {
+			t.Fatalf("CreateApiKey failed: %v", err)
+		}
+		if keyResp.JSON201 == nil {
+			t.Fatalf("Expected 201, got %d: %s", keyResp.StatusCode(), string(keyResp.Body))
+		}
+		reader, err := vcrest.NewClientWithResponses(serverURL, withAPIKey(keyResp.JSON201.Key))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		for _, id := range []openapi_types.UUID{workUUID, openapi_types.UUID(uuid.New())} {
+			resp, err := reader.PatchMovieWorkWithBodyWithResponse(ctx, id, nil, "application/merge-patch+json", strings.NewReader(`{"title": "Read only"}`))
+			if err != nil {
+				t.Fatalf("PatchMovieWork failed: %v", err)
+			}
+			if resp.StatusCode() != 403 {
+				t.Errorf("Expected 403 for patching %s with a read key, got %d: %s", id, resp.StatusCode(), string(resp.Body))
+			}
+		}
+		if got := getMovie().Title.MustGet(); got == "Read only" {
+			t.Error("Expected the read-only patch not to change the title")
+		}
+	})
+}
+
+func testExpand(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	movieUUID := openapi_types.UUID(uuid.New())
+	editionUUID := openapi_types.UUID(uuid.New())
+	discUUID := openapi_types.UUID(uuid.New())
+	fileUUID := openapi_types.UUID(uuid.New())
+	planUUID := openapi_types.UUID(uuid.New())
+
+	if resp, err := client.PutMovieWorkWithResponse(ctx, movieUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("Expanded Movie"),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutMovieWork failed: %v %v", err, resp)
+	}
+	if resp, err := client.PutMovieEditionWithResponse(ctx, editionUUID, nil, vcrest.PutMovieEditionJSONRequestBody{
+		EditionType: nullable.NewNullableWithValue("Extended"),
+		MovieUuid:   nullable.NewNullableWithValue(movieUUID),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutMovieEdition failed: %v %v", err, resp)
+	}
+	if resp, err := client.PutDiscSourceWithResponse(ctx, discUUID, nil, vcrest.PutDiscSourceJSONRequestBody{
+		OrigDirName:   nullable.NewNullableWithValue("EXPANDED_DISC"),
+		Path:          nullable.NewNullableWithValue("/media/discs/expanded"),
+		AllFilesAdded: nullable.NewNullableWithValue(true),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutDiscSource failed: %v %v", err, resp)
+	}
+	if resp, err := client.PutFileSourceWithResponse(ctx, fileUUID, nil, vcrest.PutFileSourceJSONRequestBody{
+		Path:     nullable.NewNullableWithValue("/media/discs/expanded/title_t00.mkv"),
+		DiscUuid: nullable.NewNullableWithValue(discUUID),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutFileSource failed: %v %v", err, resp)
+	}
+	if resp, err := client.PutDirectPlanWithResponse(ctx, planUUID, nil, vcrest.PutDirectPlanJSONRequestBody{
+		SourceUuid: nullable.NewNullableWithValue(fileUUID),
+		WorkUuid:   nullable.NewNullableWithValue(editionUUID),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutDirectPlan failed: %v %v", err, resp)
+	}
+
+	t.Run("Work parent and children", func(t *testing.T) {
+		expand := "parent"
+		resp, err := client.GetWorkWithResponse(ctx, editionUUID, &vcrest.GetWorkParams{Expand: &expand})
+		if err != nil {
+			t.Fatalf("GetWork failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		if got := resp.JSON200.MovieEdition.MovieUuid.MustGet(); got != movieUUID {
+			t.Errorf("Expected movie UUID %s, got %s", movieUUID, got)
+		}
+		parent := resp.JSON200.Parent
+		if parent == nil || parent.Uuid != movieUUID || parent.Movie == nil {
+			t.Fatalf("Expected the movie as parent, got %+v", parent)
+		}
+		if got := parent.Movie.Title.MustGet(); got != "Expanded Movie" {
+			t.Errorf("Expected parent title 'Expanded Movie', got '%s'", got)
+		}
+
+		expand = "children"
+		resp, err = client.GetWorkWithResponse(ctx, movieUUID, &vcrest.GetWorkParams{Expand: &expand})
+		if err != nil {
+			t.Fatalf("GetWork failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		if len(resp.JSON200.Children) != 1 || resp.JSON200.Children[0].Uuid != editionUUID {
+			t.Errorf("Expected the edition as the only child, got %+v", resp.JSON200.Children)
+		}
+		if resp.JSON200.Parent != nil {
+			t.Errorf("Expected no parent when not expanded, got %+v", resp.JSON200.Parent)
+		}
+	})
+
+	t.Run("Source parent and children", func(t *testing.T) {
+		expand := "parent,children"
+		resp, err := client.GetSourceWithResponse(ctx, fileUUID, &vcrest.GetSourceParams{Expand: &expand})
+		if err != nil {
+			t.Fatalf("GetSource failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		if resp.JSON200.Parent == nil || resp.JSON200.Parent.Uuid != discUUID {
+			t.Errorf("Expected the disc as parent, got %+v", resp.JSON200.Parent)
+		}
+
+		resp, err = client.GetSourceWithResponse(ctx, discUUID, &vcrest.GetSourceParams{Expand: &expand})
+		if err != nil {
+			t.Fatalf("GetSource failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		if len(resp.JSON200.Children) != 1 || resp.JSON200.Children[0].Uuid != fileUUID {
+			t.Errorf("Expected the file as the only child, got %+v", resp.JSON200.Children)
+		}
+	})
+
+	t.Run("Plan source and work", func(t *testing.T) {
+		expand := "source,work"
+		resp, err := client.GetPlanWithResponse(ctx, planUUID, &vcrest.GetPlanParams{Expand: &expand})
+		if err != nil {
+			t.Fatalf("GetPlan failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		if resp.JSON200.Source == nil || resp.JSON200.Source.File == nil {
+			t.Errorf("Expected the file source to be embedded, got %+v", resp.JSON200.Source)
+		}
+		if resp.JSON200.Work == nil || resp.JSON200.Work.MovieEdition == nil {
+			t.Errorf("Expected the edition work to be embedded, got %+v", resp.JSON200.Work)
+		}
+
+		listResp, err := client.ListPlansWithResponse(ctx, &vcrest.ListPlansParams{
+			WorkUuid: &editionUUID,
+			Expand:   &expand,
+		})
+		if err != nil {
+			t.Fatalf("ListPlans failed: %v", err)
+		}
+		if listResp.JSON200 == nil || len(listResp.JSON200.Plans) != 1 {
+			t.Fatalf("Expected one plan, got %d: %s", listResp.StatusCode(), string(listResp.Body))
+		}
+		plan := listResp.JSON200.Plans[0]
+		if plan.Source == nil || plan.Source.Uuid != fileUUID || plan.Work == nil || plan.Work.Uuid != editionUUID {
+			t.Errorf("Expected source and work to be embedded in listed plan, got %+v", plan)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		expand := "children"
+		resp, err := client.GetPlanWithResponse(ctx, planUUID, &vcrest.GetPlanParams{Expand: &expand})
+		if err != nil {
+			t.Fatalf("GetPlan failed: %v", err)
+		}
+		if resp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for unsupported expansion, got %d", resp.StatusCode())
+		}
+
+		putResp, err := client.PutMovieEditionWithResponse(ctx, openapi_types.UUID(uuid.New()), nil, vcrest.PutMovieEditionJSONRequestBody{
+			EditionType: nullable.NewNullableWithValue("Extended"),
+			MovieUuid:   nullable.NewNullableWithValue(editionUUID),
+		})
+		if err != nil {
+			t.Fatalf("PutMovieEdition failed: %v", err)
+		}
+		if putResp.StatusCode() != 409 {
+			t.Errorf("Expected 409 for an edition whose parent is not a movie, got %d", putResp.StatusCode())
+		}
+	})
+}
+
+func testGraph(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	discUUID := openapi_types.UUID(uuid.New())
+	file1UUID := openapi_types.UUID(uuid.New())
+	file2UUID := openapi_types.UUID(uuid.New())
+	movieUUID := openapi_types.UUID(uuid.New())
+	editionUUID := openapi_types.UUID(uuid.New())
+	planUUID := openapi_types.UUID(uuid.New())
+
+	if resp, err := client.PutDiscSourceWithResponse(ctx, discUUID, nil, vcrest.PutDiscSourceJSONRequestBody{
+		OrigDirName:   nullable.NewNullableWithValue("BOX_SET_DISC_1"),
+		Path:          nullable.NewNullableWithValue("/media/discs/box_set_1"),
+		AllFilesAdded: nullable.NewNullableWithValue(true),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutDiscSource failed: %v %v", err, resp)
+	}
+	for i, fileUUID := range []openapi_types.UUID{file1UUID, file2UUID} {
+		if resp, err := client.PutFileSourceWithResponse(ctx, fileUUID, nil, vcrest.PutFileSourceJSONRequestBody{
+			Path:     nullable.NewNullableWithValue(fmt.Sprintf("/media/discs/box_set_1/title_t%02d.mkv", i)),
+			DiscUuid: nullable.NewNullableWithValue(discUUID),
+		}); err != nil || resp.StatusCode() != 201 {
+			t.Fatalf("PutFileSource failed: %v %v", err, resp)
+		}
+	}
+	if resp, err := client.PutMovieWorkWithResponse(ctx, movieUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("Graph Movie"),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutMovieWork failed: %v %v", err, resp)
+	}
+	if resp, err := client.PutMovieEditionWithResponse(ctx, editionUUID, nil, vcrest.PutMovieEditionJSONRequestBody{
+		EditionType: nullable.NewNullableWithValue("Box Set"),
+		MovieUuid:   nullable.NewNullableWithValue(movieUUID),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutMovieEdition failed: %v %v", err, resp)
+	}
+	if resp, err := client.PutDirectPlanWithResponse(ctx, planUUID, nil, vcrest.PutDirectPlanJSONRequestBody{
+		SourceUuid: nullable.NewNullableWithValue(file1UUID),
+		WorkUuid:   nullable.NewNullableWithValue(editionUUID),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutDirectPlan failed: %v %v", err, resp)
+	}
+
+	getGraph := func(root openapi_types.UUID, depth int32) *vcrest.Graph {
+		t.Helper()
+		resp, err := client.GetGraphWithResponse(ctx, &vcrest.GetGraphParams{Root: root, Depth: &depth})
+		if err != nil {
+			t.Fatalf("GetGraph failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		return resp.JSON200
+	}
+	depths := func(graph *vcrest.Graph) map[openapi_types.UUID]int32 {
+		result := map[openapi_types.UUID]int32{}
+		for _, node := range graph.Nodes {
+			result[node.Uuid] = node.Depth
+		}
+		return result
+	}
+
+	t.Run("Disc to edition", func(t *testing.T) {
+		graph := getGraph(discUUID, 3)
+		want := map[openapi_types.UUID]int32{
+			discUUID:    0,
+			file1UUID:   1,
+			file2UUID:   1,
+			planUUID:    2,
+			editionUUID: 3,
+		}
+		if got := depths(graph); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected node depths %v, got %v", want, got)
+		}
+		wantEdges := map[vcrest.GraphEdge]bool{
+			{From: discUUID, To: file1UUID, Type: "parent"}:   true,
+			{From: discUUID, To: file2UUID, Type: "parent"}:   true,
+			{From: file1UUID, To: planUUID, Type: "input"}:    true,
+			{From: planUUID, To: editionUUID, Type: "output"}: true,
+		}
+		if len(graph.Edges) != len(wantEdges) {
+			t.Errorf("Expected %d edges, got %d: %+v", len(wantEdges), len(graph.Edges), graph.Edges)
+		}
+		for _, edge := range graph.Edges {
+			if !wantEdges[edge] {
+				t.Errorf("Unexpected edge %+v", edge)
+			}
+		}
+		for _, node := range graph.Nodes {
+			if node.Uuid == editionUUID && (node.Type != "work" || node.Work == nil || node.Work.MovieEdition == nil) {
+				t.Errorf("Expected edition node to embed the work, got %+v", node)
+			}
+		}
+	})
+
+	t.Run("Depth limits the graph", func(t *testing.T) {
+		graph := getGraph(discUUID, 1)
+		if len(graph.Nodes) != 3 || len(graph.Edges) != 2 {
+			t.Errorf("Expected 3 nodes and 2 edges at depth 1, got %d and %d", len(graph.Nodes), len(graph.Edges))
+		}
+
+		graph = getGraph(discUUID, 4)
+		if got, ok := depths(graph)[movieUUID]; !ok || got != 4 {
+			t.Errorf("Expected the movie at depth 4, got %d (present: %v)", got, ok)
+		}
+	})
+
+	t.Run("Starting from a work", func(t *testing.T) {
+		graph := getGraph(editionUUID, 2)
+		want := map[openapi_types.UUID]int32{
+			editionUUID: 0,
+			movieUUID:   1,
+			planUUID:    1,
+			file1UUID:   2,
+		}
+		if got := depths(graph); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected node depths %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		resp, err := client.GetGraphWithResponse(ctx, &vcrest.GetGraphParams{Root: openapi_types.UUID(uuid.New())})
+		if err != nil {
+			t.Fatalf("GetGraph failed: %v", err)
+		}
+		if resp.StatusCode() != 404 {
+			t.Errorf("Expected 404 for unknown root, got %d", resp.StatusCode())
+		}
+	})
+}
+
+func testReports(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	discUUID := openapi_types.UUID(uuid.New())
+	fileUUID := openapi_types.UUID(uuid.New())
+	looseFileUUID := openapi_types.UUID(uuid.New())
+	plannedUUID := openapi_types.UUID(uuid.New())
+	unplannedUUID := openapi_types.UUID(uuid.New())
+	planUUID := openapi_types.UUID(uuid.New())
+
+	if resp, err := client.PutDiscSourceWithResponse(ctx, discUUID, nil, vcrest.PutDiscSourceJSONRequestBody{
+		OrigDirName:   nullable.NewNullableWithValue("REPORT_DISC"),
+		Path:          nullable.NewNullableWithValue("/media/discs/report"),
+		AllFilesAdded: nullable.NewNullableWithValue(false),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutDiscSource failed: %v %v", err, resp)
+	}
+	if resp, err := client.PutFileSourceWithResponse(ctx, fileUUID, nil, vcrest.PutFileSourceJSONRequestBody{
+		Path:     nullable.NewNullableWithValue("/media/discs/report/title_t00.mkv"),
+		DiscUuid: nullable.NewNullableWithValue(discUUID),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutFileSource failed: %v %v", err, resp)
+	}
+	if resp, err := client.PutFileSourceWithResponse(ctx, looseFileUUID, nil, vcrest.PutFileSourceJSONRequestBody{
+		Path: nullable.NewNullableWithValue("/media/files/report_loose.mkv"),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutFileSource failed: %v %v", err, resp)
+	}
+	for _, workUUID := range []openapi_types.UUID{plannedUUID, unplannedUUID} {
+		if resp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Report Movie"),
+		}); err != nil || resp.StatusCode() != 201 {
+			t.Fatalf("PutMovieWork failed: %v %v", err, resp)
+		}
+	}
+	if resp, err := client.PutDirectPlanWithResponse(ctx, planUUID, nil, vcrest.PutDirectPlanJSONRequestBody{
+		SourceUuid: nullable.NewNullableWithValue(fileUUID),
+		WorkUuid:   nullable.NewNullableWithValue(plannedUUID),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutDirectPlan failed: %v %v", err, resp)
+	}
+
+	// Reports cover the whole catalog, so read every page with a small page size to exercise paging.
+	pageSize := int32(2)
+	workReport := func(fetch func(token *string) (*vcrest.WorkPage, error)) map[openapi_types.UUID]bool {
+		t.Helper()
+		result := map[openapi_types.UUID]bool{}
+		var token *string
+		for {
+			page, err := fetch(token)
+			if err != nil {
+				t.Fatalf("Report failed: %v", err)
+			}
+			for _, work := range page.Works {
+				result[work.Uuid] = true
+			}
+			if page.NextPageToken == nil {
+				return result
+			}
+			token = page.NextPageToken
+		}
+	}
+	sourceReport := func(fetch func(token *string) (*vcrest.SourcePage, error)) map[openapi_types.UUID]bool {
+		t.Helper()
+		result := map[openapi_types.UUID]bool{}
+		var token *string
+		for {
+			page, err := fetch(token)
+			if err != nil {
+				t.Fatalf("Report failed: %v", err)
+			}
+			for _, source := range page.Sources {
+				result[source.Uuid] = true
+			}
+			if page.NextPageToken == nil {
+				return result
+			}
+			token = page.NextPageToken
+		}
+	}
+	unplannedWorks := func(token *string) (*vcrest.WorkPage, error) {
+		resp, err := client.ReportUnplannedWorksWithResponse(ctx, &vcrest.ReportUnplannedWorksParams{PageSize: &pageSize, PageToken: token})
+		if err != nil {
+			return nil, err
+		} else if resp.JSON200 == nil {
+			return nil, fmt.Errorf("expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		return resp.JSON200, nil
+	}
+	incompleteWorks := func(token *string) (*vcrest.WorkPage, error) {
+		resp, err := client.ReportIncompleteWorksWithResponse(ctx, &vcrest.ReportIncompleteWorksParams{PageSize: &pageSize, PageToken: token})
+		if err != nil {
+			return nil, err
+		} else if resp.JSON200 == nil {
+			return nil, fmt.Errorf("expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		return resp.JSON200, nil
+	}
+	unplannedSources := func(token *string) (*vcrest.SourcePage, error) {
+		resp, err := client.ReportUnplannedSourcesWithResponse(ctx, &vcrest.ReportUnplannedSourcesParams{PageSize: &pageSize, PageToken: token})
+		if err != nil {
+			return nil, err
+		} else if resp.JSON200 == nil {
+			return nil, fmt.Errorf("expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		return resp.JSON200, nil
+	}
+	incompleteDiscs := func(olderThanDays int32) func(token *string) (*vcrest.SourcePage, error) {
+		return func(token *string) (*vcrest.SourcePage, error) {
+			resp, err := client.ReportIncompleteDiscsWithResponse(ctx, &vcrest.ReportIncompleteDiscsParams{OlderThanDays: &olderThanDays, PageSize: &pageSize, PageToken: token})
+			if err != nil {
+				return nil, err
+			} else if resp.JSON200 == nil {
+				return nil, fmt.Errorf("expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+			}
+			return resp.JSON200, nil
+		}
+	}
+
+	t.Run("Unplanned works", func(t *testing.T) {
+		got := workReport(unplannedWorks)
+		if !got[unplannedUUID] || got[plannedUUID] {
+			t.Errorf("Expected only the unplanned work, got unplanned=%v planned=%v", got[unplannedUUID], got[plannedUUID])
+		}
+	})
+
+	t.Run("Unplanned sources", func(t *testing.T) {
+		got := sourceReport(unplannedSources)
+		if !got[looseFileUUID] {
+			t.Errorf("Expected the loose file to be unplanned")
+		}
+		if got[fileUUID] || got[discUUID] {
+			t.Errorf("Expected the planned file and its disc to be planned, got file=%v disc=%v", got[fileUUID], got[discUUID])
+		}
+	})
+
+	t.Run("Incomplete discs", func(t *testing.T) {
+		if got := sourceReport(incompleteDiscs(0)); !got[discUUID] {
+			t.Errorf("Expected the disc to be listed")
+		}
+		if got := sourceReport(incompleteDiscs(7)); got[discUUID] {
+			t.Errorf("Expected a new disc not to be listed when older than 7 days")
+		}
+		negative := int32(-1)
+		resp, err := client.ReportIncompleteDiscsWithResponse(ctx, &vcrest.ReportIncompleteDiscsParams{OlderThanDays: &negative})
+		if err != nil {
+			t.Fatalf("ReportIncompleteDiscs failed: %v", err)
+		}
+		if resp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for a negative age, got %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("Completing plans", func(t *testing.T) {
+		if got := workReport(incompleteWorks); !got[plannedUUID] || got[unplannedUUID] {
+			t.Errorf("Expected only the planned work to be incomplete, got planned=%v unplanned=%v", got[plannedUUID], got[unplannedUUID])
+		}
+
+		resp, err := client.CompletePlanWithResponse(ctx, planUUID)
+		if err != nil || resp.StatusCode() != 200 {
+			t.Fatalf("CompletePlan failed: %v %v", err, resp)
+		}
+		getResp, err := client.GetPlanWithResponse(ctx, planUUID, nil)
+		if err != nil || getResp.JSON200 == nil {
+			t.Fatalf("GetPlan failed: %v %v", err, getResp)
+		}
+		if getResp.JSON200.CompletedAt == nil {
+			t.Errorf("Expected the plan to have a completion time")
+		}
+		if got := workReport(incompleteWorks); got[plannedUUID] {
+			t.Errorf("Expected the work not to be incomplete once its plan is complete")
+		}
+
+		reopenResp, err := client.ReopenPlanWithResponse(ctx, planUUID)
+		if err != nil || reopenResp.StatusCode() != 200 {
+			t.Fatalf("ReopenPlan failed: %v %v", err, reopenResp)
+		}
+		if got := workReport(incompleteWorks); !got[plannedUUID] {
+			t.Errorf("Expected the work to be incomplete once its plan is reopened")
+		}
+
+		notFound, err := client.CompletePlanWithResponse(ctx, openapi_types.UUID(uuid.New()))
+		if err != nil {
+			t.Fatalf("CompletePlan failed: %v", err)
+		}
+		if notFound.StatusCode() != 404 {
+			t.Errorf("Expected 404 for a missing plan, got %d", notFound.StatusCode())
+		}
+	})
+
+	t.Run("Invalid page token", func(t *testing.T) {
+		token := "not-a-token"
+		resp, err := client.ReportUnplannedWorksWithResponse(ctx, &vcrest.ReportUnplannedWorksParams{PageToken: &token})
+		if err != nil {
+			t.Fatalf("ReportUnplannedWorks failed: %v", err)
+		}
+		if resp.StatusCode() != 400 {
+			t.Errorf("Expected 400, got %d", resp.StatusCode())
+		}
+	})
+}
+
+func testStats(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	// The statistics are refreshed in the background, so only their shape can be checked here.
+	resp, err := client.GetStatsWithResponse(ctx)
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if resp.JSON200 == nil {
+		t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+	}
+	stats := resp.JSON200
+	if stats.RefreshedAt.IsZero() {
+		t.Errorf("Expected refreshedAt to be set")
+	}
+	if stats.WorkKinds == nil || stats.SourceKinds == nil || stats.PlanKinds == nil || stats.WorksByDecade == nil {
+		t.Errorf("Expected all count lists to be present, got %+v", stats)
+	}
+	for i := 1; i < len(stats.WorksByDecade); i++ {
+		if stats.WorksByDecade[i-1].Decade >= stats.WorksByDecade[i].Decade {
+			t.Errorf("Expected decades in increasing order, got %+v", stats.WorksByDecade)
+		}
+	}
+}
+
+func testChanges(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	// readAll reads the feed from the cursor until it is caught up, returning the changes and the final cursor.
+	readAll := func(since *string) ([]vcrest.Change, string) {
+		t.Helper()
+		pageSize := int32(3)
+		var changes []vcrest.Change
+		for {
+			resp, err := client.ListChangesWithResponse(ctx, &vcrest.ListChangesParams{Since: since, PageSize: &pageSize})
+			if err != nil {
+				t.Fatalf("ListChanges failed: %v", err)
+			}
+			if resp.JSON200 == nil {
+				t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+			}
+			changes = append(changes, resp.JSON200.Changes...)
+			cursor := resp.JSON200.NextCursor
+			since = &cursor
+			if len(resp.JSON200.Changes) == 0 {
+				return changes, cursor
+			}
+		}
+	}
+
+	_, cursor := readAll(nil)
+
+	workUUID := openapi_types.UUID(uuid.New())
+	if resp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("Change Feed Movie"),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutMovieWork failed: %v %v", err, resp)
+	}
+	if resp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("Change Feed Movie, Renamed"),
+	}); err != nil || resp.StatusCode() != 200 {
+		t.Fatalf("PutMovieWork failed: %v %v", err, resp)
+	}
+
+	t.Run("Upserts", func(t *testing.T) {
+		changes, next := readAll(&cursor)
+		var upserts int
+		for _, change := range changes {
+			if change.Uuid != workUUID {
+				continue
+			}
+			if change.Type != "work" || change.Operation != "upsert" {
+				t.Errorf("Expected a work upsert, got %s %s", change.Type, change.Operation)
+			}
+			if change.Work == nil || change.Work.Movie == nil || change.Work.Movie.Title.MustGet() != "Change Feed Movie, Renamed" {
+				t.Errorf("Expected the current work to be embedded, got %+v", change.Work)
+			}
+			upserts++
+		}
+		if upserts != 2 {
+			t.Errorf("Expected 2 upserts of the work, got %d", upserts)
+		}
+		for i := 1; i < len(changes); i++ {
+			if changes[i-1].Seq == changes[i].Seq {
+				t.Errorf("Expected each change once, got sequence number %d twice", changes[i].Seq)
+			}
+		}
+		cursor = next
+	})
+
+	t.Run("Deletes", func(t *testing.T) {
+		if resp, err := client.DeleteWorkWithResponse(ctx, workUUID); err != nil || resp.StatusCode() != 200 {
+			t.Fatalf("DeleteWork failed: %v %v", err, resp)
+		}
+		changes, _ := readAll(&cursor)
+		var deletes int
+		for _, change := range changes {
+			if change.Uuid == workUUID && change.Operation == "delete" {
+				deletes++
+				if change.Work != nil {
+					t.Errorf("Expected no work to be embedded in a delete")
+				}
+			}
+		}
+		if deletes != 1 {
+			t.Errorf("Expected 1 delete of the work, got %d", deletes)
+		}
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		since := "not-a-cursor"
+		resp, err := client.ListChangesWithResponse(ctx, &vcrest.ListChangesParams{Since: &since})
+		if err != nil {
+			t.Fatalf("ListChanges failed: %v", err)
+		}
+		if resp.StatusCode() != 400 {
+			t.Errorf("Expected 400, got %d", resp.StatusCode())
+		}
+	})
+}
+
+func testWebhooks(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses, receiver *webhookReceiver) {
+	const secret = "webhook-test-secret"
+
+	t.Run("Invalid subscriptions", func(t *testing.T) {
+		for name, body := range map[string]vcrest.CreateWebhookJSONRequestBody{
+			"Relative URL":   {Url: "/hooks", Secret: secret},
+			"Missing secret": {Url: receiver.url, Secret: ""},
+			"Unknown event":  {Url: receiver.url, Secret: secret, Events: []string{"work.renamed"}},
+		} {
+			resp, err := client.CreateWebhookWithResponse(ctx, body)
+			if err != nil {
+				t.Fatalf("CreateWebhook failed: %v", err)
+			}
+			if resp.StatusCode() != 400 {
+				t.Errorf("%s: expected 400, got %d", name, resp.StatusCode())
+			}
+		}
+	})
+
+	createResp, err := client.CreateWebhookWithResponse(ctx, vcrest.CreateWebhookJSONRequestBody{
+		Url:    receiver.url,
+		Secret: secret,
+		Events: []string{"work.created", "plan.completed"},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook failed: %v", err)
+	}
+	if createResp.JSON201 == nil {
+		t.Fatalf("Expected 201, got %d: %s", createResp.StatusCode(), string(createResp.Body))
+	}
+	webhookUUID := createResp.JSON201.Uuid
+
+	createWork := func(title string) openapi_types.UUID {
+		t.Helper()
+		workUUID := openapi_types.UUID(uuid.New())
+		if resp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue(title),
+		}); err != nil || resp.StatusCode() != 201 {
+			t.Fatalf("PutMovieWork failed: %v %v", err, resp)
+		}
+		return workUUID
+	}
+
+	t.Run("Signed delivery", func(t *testing.T) {
+		workUUID := createWork("Webhook Movie")
+		// Updates are not subscribed to, so this must not be delivered.
+		if resp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Webhook Movie, Renamed"),
+		}); err != nil || resp.StatusCode() != 200 {
+			t.Fatalf("PutMovieWork failed: %v %v", err, resp)
+		}
+
+		got := receiver.waitFor(t, workUUID, "work.created")
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(got.header.Get("X-Webhook-Timestamp") + "."))
+		mac.Write(got.body)
+		if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); got.header.Get("X-Webhook-Signature") != want {
+			t.Errorf("Expected signature %q, got %q", want, got.header.Get("X-Webhook-Signature"))
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(got.body, &payload); err != nil {
+			t.Fatalf("Failed to unmarshal payload: %v", err)
+		}
+		if payload["type"] != "work" || payload["kind"] != "movie" {
+			t.Errorf("Unexpected payload: %s", string(got.body))
+		}
+		if body, ok := payload["body"].(map[string]any); !ok || body["title"] != "Webhook Movie" {
+			t.Errorf("Expected the body of the created work, got %s", string(got.body))
+		}
+	})
+
+	t.Run("Retries and delivery log", func(t *testing.T) {
+		receiver.failNext(1)
+		workUUID := createWork("Webhook Retry Movie")
+		receiver.waitFor(t, workUUID, "work.created")
+
+		resp, err := client.ListWebhookDeliveriesWithResponse(ctx, webhookUUID, nil)
+		if err != nil {
+			t.Fatalf("ListWebhookDeliveries failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		var found bool
+		for _, delivery := range resp.JSON200.Deliveries {
+			if delivery.Event != "work.created" {
+				t.Errorf("Expected only subscribed events in the log, got %s", delivery.Event)
+			}
+			payload, _ := delivery.Payload.(map[string]any)
+			if payload["uuid"] != workUUID.String() {
+				continue
+			}
+			found = true
+			if delivery.Status != "succeeded" || delivery.Attempts != 2 {
+				t.Errorf("Expected a delivery that succeeded on the second attempt, got %s after %d", delivery.Status, delivery.Attempts)
+			}
+			if delivery.LastStatusCode == nil || *delivery.LastStatusCode != 200 {
+				t.Errorf("Expected the last attempt to return 200, got %v", delivery.LastStatusCode)
+			}
+		}
+		if !found {
+			t.Errorf("Expected the delivery in the log")
+		}
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		if resp, err := client.DeleteWebhookWithResponse(ctx, webhookUUID); err != nil || resp.StatusCode() != 200 {
+			t.Fatalf("DeleteWebhook failed: %v %v", err, resp)
+		}
+		resp, err := client.GetWebhookWithResponse(ctx, webhookUUID)
+		if err != nil {
+			t.Fatalf("GetWebhook failed: %v", err)
+		}
+		if resp.StatusCode() != 404 {
+			t.Errorf("Expected 404 after deleting the webhook, got %d", resp.StatusCode())
+		}
+	})
+}
+
+// receivedWebhook is a delivery seen by a webhookReceiver.
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver is an HTTP server on the host that the server container can deliver webhooks to.
+type webhookReceiver struct {
+	server *httptest.Server
+	port   int
+	url    string // URL of the receiver as seen from the server container.
+
+	mu       sync.Mutex
+	received []receivedWebhook
+	failures int // Number of upcoming deliveries to reject.
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	r := &webhookReceiver{}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.failures > 0 {
+			r.failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r.received = append(r.received, receivedWebhook{header: req.Header, body: body})
+	}))
+	t.Cleanup(r.server.Close)
+
+	serverURL, err := url.Parse(r.server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse receiver URL: %v", err)
+	}
+	r.port, err = strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatalf("failed to parse receiver port: %v", err)
+	}
+	r.url = fmt.Sprintf("http://%s:%d/hooks", testcontainers.HostInternal, r.port)
+	return r
+}
+
+// failNext makes the receiver reject the next n deliveries.
+func (r *webhookReceiver) failNext(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = n
+}
+
+// waitFor waits for the delivery of an event about the given entity.
+func (r *webhookReceiver) waitFor(t *testing.T, entityUUID openapi_types.UUID, event string) receivedWebhook {
+	t.Helper()
+	deadline := time.Now().Add(time.Minute)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		for _, got := range r.received {
+			var payload struct {
+				Event string `json:"event"`
+				Uuid  string `json:"uuid"`
+			}
+			if json.Unmarshal(got.body, &payload) == nil && payload.Event == event && payload.Uuid == entityUUID.String() {
+				r.mu.Unlock()
+				return got
+			}
+		}
+		r.mu.Unlock()
+		time.Sleep(250 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %s of %s", event, entityUUID)
+	return receivedWebhook{}
+}
+
+func testEvents(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	type event struct {
+		id     string
+		change vcrest.Change
+	}
+	// openStream starts reading an event stream, returning a function that waits for the next event.
+	openStream := func(params *vcrest.StreamEventsParams) (func() event, func()) {
+		t.Helper()
+		streamCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		resp, err := client.StreamEvents(streamCtx, params)
+		if err != nil {
+			cancel()
+			t.Fatalf("StreamEvents failed: %v", err)
+		}
+		if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "text/event-stream" {
+			cancel()
+			t.Fatalf("Expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		reader := bufio.NewReader(resp.Body)
+		next := func() event {
+			t.Helper()
+			var e event
+			var data string
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					t.Fatalf("Failed to read event stream: %v", err)
+				}
+				line = strings.TrimSuffix(line, "\n")
+				switch {
+				case line == "" && data != "":
+					if err := json.Unmarshal([]byte(data), &e.change); err != nil {
+						t.Fatalf("Failed to unmarshal event data %q: %v", data, err)
+					}
+					return e
+				case strings.HasPrefix(line, "id: "):
+					e.id = strings.TrimPrefix(line, "id: ")
+				case strings.HasPrefix(line, "data: "):
+					data = strings.TrimPrefix(line, "data: ")
+				}
+			}
+		}
+		return next, func() {
+			cancel()
+			resp.Body.Close()
+		}
+	}
+	putDisc := func(name string) openapi_types.UUID {
+		t.Helper()
+		discUUID := openapi_types.UUID(uuid.New())
+		if resp, err := client.PutDiscSourceWithResponse(ctx, discUUID, nil, vcrest.PutDiscSourceJSONRequestBody{
+			OrigDirName:   nullable.NewNullableWithValue(name),
+			Path:          nullable.NewNullableWithValue("/media/discs/" + name),
+			AllFilesAdded: nullable.NewNullableWithValue(false),
+		}); err != nil || resp.StatusCode() != 201 {
+			t.Fatalf("PutDiscSource failed: %v %v", err, resp)
+		}
+		return discUUID
+	}
+
+	sourceType, discKind := "source", "disc"
+	next, closeStream := openStream(&vcrest.StreamEventsParams{Type: &sourceType, Kind: &discKind})
+	if resp, err := client.PutMovieWorkWithResponse(ctx, openapi_types.UUID(uuid.New()), nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("Filtered Out"),
+	}); err != nil || resp.StatusCode() != 201 {
+		t.Fatalf("PutMovieWork failed: %v %v", err, resp)
+	}
+	disc1UUID := putDisc("EVENTS_DISC_1")
+	first := next()
+	closeStream()
+	if first.change.Uuid != disc1UUID || first.change.Operation != "upsert" {
+		t.Fatalf("Expected the upsert of the first disc, got %s of %s", first.change.Operation, first.change.Uuid)
+	}
+	if first.change.Source == nil || first.change.Source.Disc == nil {
+		t.Errorf("Expected the disc to be embedded, got %+v", first.change.Source)
+	}
+
+	t.Run("Resume", func(t *testing.T) {
+		// Changes made while disconnected are sent on reconnection.
+		if resp, err := client.PutFileSourceWithResponse(ctx, openapi_types.UUID(uuid.New()), nil, vcrest.PutFileSourceJSONRequestBody{
+			Path:     nullable.NewNullableWithValue("/media/discs/EVENTS_DISC_1/title_t00.mkv"),
+			DiscUuid: nullable.NewNullableWithValue(disc1UUID),
+		}); err != nil || resp.StatusCode() != 201 {
+			t.Fatalf("PutFileSource failed: %v %v", err, resp)
+		}
+		disc2UUID := putDisc("EVENTS_DISC_2")
+
+		next, closeStream := openStream(&vcrest.StreamEventsParams{Type: &sourceType, Kind: &discKind, LastEventID: &first.id})
+		defer closeStream()
+		if got := next(); got.change.Uuid != disc2UUID {
+			t.Errorf("Expected the second disc after resuming, got %s", got.change.Uuid)
+		}
+	})
+
+	t.Run("Invalid type", func(t *testing.T) {
+		invalid := "person"
+		resp, err := client.StreamEventsWithResponse(ctx, &vcrest.StreamEventsParams{Type: &invalid})
+		if err != nil {
+			t.Fatalf("StreamEvents failed: %v", err)
+		}
+		if resp.StatusCode() != 400 {
+			t.Errorf("Expected 400, got %d", resp.StatusCode())
+		}
+	})
+}
+
+func testRequestValidation(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses, serverURL string) {
+	id := uuid.New()
+
+	// expect400 checks that a request was rejected because of the given field, or because of the whole
+	// request if field is empty.
+	expect400 := func(name string, status int, body []byte, field, code string) {
+		t.Helper()
+		if status != 400 {
+			t.Errorf("%s: expected 400, got %d: %s", name, status, string(body))
+			return
+		}
+		var apiErr vcrest.Error
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			t.Errorf("%s: expected an Error body, got %s", name, string(body))
+			return
+		}
+		if field == "" {
+			if apiErr.Code != code || len(apiErr.Details) != 0 {
+				t.Errorf("%s: expected code %s without details, got %+v", name, code, apiErr)
+			}
+			return
+		}
+		want := []vcrest.FieldError{{Field: field, Code: code}}
+		if apiErr.Code != "INVALID_FIELD" || len(apiErr.Details) != 1 ||
+			apiErr.Details[0].Field != want[0].Field || apiErr.Details[0].Code != want[0].Code {
+			t.Errorf("%s: expected INVALID_FIELD with details %+v, got %+v", name, want, apiErr)
+		}
+	}
+
+	malformedPath := func(ctx context.Context, req *http.Request) error {
+		req.URL.Path = "/works/not-a-uuid"
+		return nil
+	}
+	getResp, err := client.GetWorkWithResponse(ctx, id, nil, malformedPath)
+	if err != nil {
+		t.Fatalf("GetWork failed: %v", err)
+	}
+	expect400("malformed UUID", getResp.StatusCode(), getResp.Body, "uuid", "INVALID_UUID")
+
+	wrongQueryType := func(ctx context.Context, req *http.Request) error {
+		req.URL.RawQuery = "pageSize=many"
+		return nil
+	}
+	listResp, err := client.ListWorksWithResponse(ctx, nil, wrongQueryType)
+	if err != nil {
+		t.Fatalf("ListWorks failed: %v", err)
+	}
+	expect400("wrong query parameter type", listResp.StatusCode(), listResp.Body, "pageSize", "WRONG_TYPE")
+
+	putRaw := func(body string) *vcrest.PutMovieWorkResponse {
+		t.Helper()
+		resp, err := client.PutMovieWorkWithBodyWithResponse(ctx, id, nil, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("PutMovieWork failed: %v", err)
+		}
+		return resp
+	}
+	resp := putRaw(`{"title": 42}`)
+	expect400("wrong field type", resp.StatusCode(), resp.Body, "title", "WRONG_TYPE")
+	resp = putRaw(`{"title": "Inception", "rating": 5}`)
+	expect400("unknown field", resp.StatusCode(), resp.Body, "rating", "UNKNOWN_FIELD")
+	resp = putRaw(`{"title": "Inception", "alternateTitles": [{"title": null}]}`)
+	expect400("nested field", resp.StatusCode(), resp.Body, "alternateTitles[0].title", "NULL")
+	resp = putRaw(`{"title": `)
+	expect400("malformed JSON", resp.StatusCode(), resp.Body, "", "INVALID_REQUEST")
+
+	// None of the rejected requests should have reached the handler.
+	getResp, err = client.GetWorkWithResponse(ctx, id, nil)
+	if err != nil {
+		t.Fatalf("GetWork failed: %v", err)
+	}
+	if getResp.StatusCode() != 404 {
+		t.Errorf("Expected 404 for work that was never created, got %d", getResp.StatusCode())
+	}
+
+	// Callers without credentials are rejected before their requests are validated, so that the errors do
+	// not tell them anything about the API.
+	anonymous, err := vcrest.NewClientWithResponses(serverURL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	anonymousResp, err := anonymous.PutMovieWorkWithBodyWithResponse(ctx, id, nil, "application/json", strings.NewReader(`{"title": 42}`))
+	if err != nil {
+		t.Fatalf("PutMovieWork failed: %v", err)
+	}
+	var apiErr vcrest.Error
+	if anonymousResp.StatusCode() != 401 || json.Unmarshal(anonymousResp.Body, &apiErr) != nil || apiErr.Code != "UNAUTHENTICATED" {
+		t.Errorf("Expected 401 UNAUTHENTICATED for an invalid request without credentials, got %d: %s", anonymousResp.StatusCode(), string(anonymousResp.Body))
+	}
+	anonymousGet, err := anonymous.GetWorkWithResponse(ctx, id, nil, malformedPath)
+	if err != nil {
+		t.Fatalf("GetWork failed: %v", err)
+	}
+	if anonymousGet.StatusCode() != 401 {
+		t.Errorf("Expected 401 for a malformed path without credentials, got %d: %s", anonymousGet.StatusCode(), string(anonymousGet.Body))
+	}
+
+	// Requests that match no operation are rejected rather than left to the mux, and HEAD requests are
+	// served like GET requests.
+	for _, tc := range []struct {
+		method, path string
+		status       int
+		code         string
+	}{
+		{http.MethodGet, "/no-such-path", 404, "NOT_FOUND"},
+		{http.MethodPut, "/works", 405, "INVALID_REQUEST"},
+		{http.MethodDelete, "/search", 405, "INVALID_REQUEST"},
+		{http.MethodHead, "/works/" + id.String(), 404, ""},
+		{http.MethodHead, "/works/not-a-uuid", 400, ""},
+	} {
+		req, err := http.NewRequestWithContext(ctx, tc.method, serverURL+tc.path, nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		req.Header.Set("X-API-Key", bootstrapAPIKey)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", tc.method, tc.path, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to read response: %v", err)
+		}
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s %s: expected %d, got %d: %s", tc.method, tc.path, tc.status, resp.StatusCode, string(body))
+			continue
+		}
+		var apiErr vcrest.Error
+		if tc.code != "" && (json.Unmarshal(body, &apiErr) != nil || apiErr.Code != tc.code) {
+			t.Errorf("%s %s: expected code %s, got %s", tc.method, tc.path, tc.code, string(body))
+		}
+	}
+}
+
+func testErrorCodes(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	// expectCode checks that an error response has the given code.
+	expectCode := func(name string, apiErr *vcrest.Error, status int, body []byte, code string) {
+		t.Helper()
+		if apiErr == nil {
+			t.Errorf("%s: expected an error with code %s, got %d: %s", name, code, status, string(body))
+		} else if apiErr.Code != code {
+			t.Errorf("%s: expected code %s, got %+v", name, code, *apiErr)
+		}
+	}
+
+	getResp, err := client.GetWorkWithResponse(ctx, uuid.New(), nil)
+	if err != nil {
+		t.Fatalf("GetWork failed: %v", err)
+	}
+	expectCode("missing work", getResp.JSON404, getResp.StatusCode(), getResp.Body, "NOT_FOUND")
+
+	movieUUID := uuid.New()
+	putResp, err := client.PutMovieWorkWithResponse(ctx, movieUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("The Error Code"),
+	})
+	if err != nil || putResp.StatusCode() != 201 {
+		t.Fatalf("PutMovieWork failed: %v %v", err, putResp)
+	}
+
+	putResp, err = client.PutMovieWorkWithResponse(ctx, movieUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue(""),
+	})
+	if err != nil {
+		t.Fatalf("PutMovieWork failed: %v", err)
+	}
+	expectCode("empty title", putResp.JSON400, putResp.StatusCode(), putResp.Body, "INVALID_FIELD")
+	if putResp.JSON400 != nil {
+		details := putResp.JSON400.Details
+		if len(details) != 1 || details[0].Field != "title" || details[0].Code != "EMPTY" {
+			t.Errorf("Expected details for the empty title, got %+v", details)
+		}
+	}
+
+	staleETag := `"0"`
+	putResp, err = client.PutMovieWorkWithResponse(ctx, movieUUID, &vcrest.PutMovieWorkParams{IfMatch: &staleETag}, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("The Error Code"),
+	})
+	if err != nil {
+		t.Fatalf("PutMovieWork failed: %v", err)
+	}
+	expectCode("stale ETag", putResp.JSON412, putResp.StatusCode(), putResp.Body, "PRECONDITION_FAILED")
+
+	editionResp, err := client.PutMovieEditionWithResponse(ctx, movieUUID, nil, vcrest.PutMovieEditionJSONRequestBody{
+		EditionType: nullable.NewNullableWithValue("Director's Cut"),
+	})
+	if err != nil {
+		t.Fatalf("PutMovieEdition failed: %v", err)
+	}
+	expectCode("different kind", editionResp.JSON409, editionResp.StatusCode(), editionResp.Body, "KIND_CONFLICT")
+
+	editionResp, err = client.PutMovieEditionWithResponse(ctx, uuid.New(), nil, vcrest.PutMovieEditionJSONRequestBody{
+		EditionType: nullable.NewNullableWithValue("Director's Cut"),
+		MovieUuid:   nullable.NewNullableWithValue(uuid.New()),
+	})
+	if err != nil {
+		t.Fatalf("PutMovieEdition failed: %v", err)
+	}
+	expectCode("missing movie", editionResp.JSON409, editionResp.StatusCode(), editionResp.Body, "REFERENCE_MISSING")
+	if editionResp.JSON409 != nil {
+		details := editionResp.JSON409.Details
+		if len(details) != 1 || details[0].Field != "movieUuid" || details[0].Code != "REFERENCE_MISSING" {
+			t.Errorf("Expected details for the missing movie, got %+v", details)
+		}
+	}
+}
+
+// bootstrapAPIKey is the admin key the server is started with, which the tests use to issue other keys.
+const bootstrapAPIKey = "vck_e2e-bootstrap-key"
+
+// serverVersion is the release that the server image is built as.
+const serverVersion = "v0.0.0-e2e"
+
+// withAPIKey makes a client authenticate its requests with an API key.
+func withAPIKey(key string) vcrest.ClientOption {
+	return vcrest.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("X-API-Key", key)
+		return nil
+	})
+}
+
+func testAPIKeys(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses, serverURL string) {
+	newClient := func(opts ...vcrest.ClientOption) *vcrest.ClientWithResponses {
+		t.Helper()
+		c, err := vcrest.NewClientWithResponses(serverURL, opts...)
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		return c
+	}
+	createKey := func(name string, scopes ...string) *vcrest.CreatedApiKey {
+		t.Helper()
+		resp, err := client.CreateApiKeyWithResponse(ctx, vcrest.CreateApiKeyJSONRequestBody{Name: name, Scopes: scopes})
+		if err != nil {
+			t.Fatalf("CreateApiKey failed: %v", err)
+		}
+		if resp.JSON201 == nil {
+			t.Fatalf("Expected 201, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		return resp.JSON201
+	}
+	// expectCode checks that a request was rejected with the given status and error code.
+	expectCode := func(name string, status int, body []byte, wantStatus int, wantCode string) {
+		t.Helper()
+		var apiErr vcrest.Error
+		if status != wantStatus || json.Unmarshal(body, &apiErr) != nil || apiErr.Code != wantCode {
+			t.Errorf("%s: expected %d %s, got %d: %s", name, wantStatus, wantCode, status, string(body))
+		}
+	}
+	putWork := func(c *vcrest.ClientWithResponses) *vcrest.PutMovieWorkResponse {
+		t.Helper()
+		resp, err := c.PutMovieWorkWithResponse(ctx, openapi_types.UUID(uuid.New()), nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("API key test"),
+		})
+		if err != nil {
+			t.Fatalf("PutMovieWork failed: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("Missing or unknown key", func(t *testing.T) {
+		resp, err := newClient().ListWorksWithResponse(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListWorks failed: %v", err)
+		}
+		expectCode("No key", resp.StatusCode(), resp.Body, 401, "UNAUTHENTICATED")
+
+		resp, err = newClient(withAPIKey("vck_not-a-key")).ListWorksWithResponse(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListWorks failed: %v", err)
+		}
+		expectCode("Unknown key", resp.StatusCode(), resp.Body, 401, "UNAUTHENTICATED")
+	})
+
+	t.Run("HEAD without credentials", func(t *testing.T) {
+		workUUID := openapi_types.UUID(uuid.New())
+		putResp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("HEAD test"),
+		})
+		if err != nil {
+			t.Fatalf("PutMovieWork failed: %v", err)
+		}
+		if putResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+		}
+
+		// The mux would serve these with the GET handlers, so they need the same credentials.
+		for path, want := range map[string]int{
+			"/works/" + workUUID.String(): 401,
+			"/api-keys":                   401,
+			"/webhooks":                   401,
+			"/no-such-path":               401,
+			"/healthz":                    200,
+		} {
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, serverURL+path, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("HEAD %s failed: %v", path, err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != want {
+				t.Errorf("HEAD %s: expected %d, got %d", path, want, resp.StatusCode)
+			}
+			if resp.Header.Get("ETag") != "" {
+				t.Errorf("HEAD %s: expected no ETag, got %q", path, resp.Header.Get("ETag"))
+			}
+		}
+	})
+
+	t.Run("Invalid scopes", func(t *testing.T) {
+		resp, err := client.CreateApiKeyWithResponse(ctx, vcrest.CreateApiKeyJSONRequestBody{Name: "bad", Scopes: []string{"delete"}})
+		if err != nil {
+			t.Fatalf("CreateApiKey failed: %v", err)
+		}
+		if resp.StatusCode() != 400 {
+			t.Errorf("Expected 400, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+	})
+
+	t.Run("Scopes", func(t *testing.T) {
+		reader := newClient(withAPIKey(createKey("reader", "read").Key))
+		writer := newClient(withAPIKey(createKey("writer", "write").Key))
+
+		listResp, err := reader.ListWorksWithResponse(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListWorks failed: %v", err)
+		}
+		if listResp.StatusCode() != 200 {
+			t.Errorf("Expected read key to list works, got %d: %s", listResp.StatusCode(), string(listResp.Body))
+		}
+		resp := putWork(reader)
+		expectCode("Read key writing", resp.StatusCode(), resp.Body, 403, "FORBIDDEN")
+
+		if resp := putWork(writer); resp.StatusCode() != 201 {
+			t.Errorf("Expected write key to create a work, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		keysResp, err := writer.ListApiKeysWithResponse(ctx)
+		if err != nil {
+			t.Fatalf("ListApiKeys failed: %v", err)
+		}
+		expectCode("Write key listing keys", keysResp.StatusCode(), keysResp.Body, 403, "FORBIDDEN")
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		created := createKey("revoked", "write")
+		if created.ApiKey.RevokedAt != nil {
+			t.Errorf("Expected new key not to be revoked, got %v", created.ApiKey.RevokedAt)
+		}
+		revokable := newClient(withAPIKey(created.Key))
+		if resp := putWork(revokable); resp.StatusCode() != 201 {
+			t.Fatalf("Expected key to work before it is revoked, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+
+		revokeResp, err := client.RevokeApiKeyWithResponse(ctx, created.ApiKey.Uuid)
+		if err != nil {
+			t.Fatalf("RevokeApiKey failed: %v", err)
+		}
+		if revokeResp.JSON200 == nil || revokeResp.JSON200.RevokedAt == nil {
+			t.Fatalf("Expected revoked key, got %d: %s", revokeResp.StatusCode(), string(revokeResp.Body))
+		}
+		resp := putWork(revokable)
+		expectCode("Revoked key", resp.StatusCode(), resp.Body, 401, "UNAUTHENTICATED")
+
+		getResp, err := client.GetApiKeyWithResponse(ctx, created.ApiKey.Uuid)
+		if err != nil {
+			t.Fatalf("GetApiKey failed: %v", err)
+		}
+		if getResp.JSON200 == nil || getResp.JSON200.RevokedAt == nil || getResp.JSON200.LastUsedAt == nil {
+			t.Errorf("Expected key to record its last use and revocation, got %d: %s", getResp.StatusCode(), string(getResp.Body))
+		}
+
+		listResp, err := client.ListApiKeysWithResponse(ctx)
+		if err != nil {
+			t.Fatalf("ListApiKeys failed: %v", err)
+		}
+		if listResp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", listResp.StatusCode(), string(listResp.Body))
+		}
+		found := false
+		for _, k := range listResp.JSON200.ApiKeys {
+			if k.Uuid == created.ApiKey.Uuid {
+				found = k.RevokedAt != nil
+			}
+		}
+		if !found {
+			t.Errorf("Expected revoked key in list, got %+v", listResp.JSON200.ApiKeys)
+		}
+
+		notFound, err := client.RevokeApiKeyWithResponse(ctx, openapi_types.UUID(uuid.New()))
+		if err != nil {
+			t.Fatalf("RevokeApiKey failed: %v", err)
+		}
+		expectCode("Unknown key", notFound.StatusCode(), notFound.Body, 404, "NOT_FOUND")
+	})
+}
+
+// The identity provider that the server is configured to trust bearer tokens from.
+const (
+	tokenIssuerURL = "https://idp.example.com"
+	tokenAudience  = "video-catalog"
+)
+
+// tokenIssuer stands in for an identity provider, signing bearer tokens with a key that the server reads
+// from a JWKS file.
+type tokenIssuer struct {
+	signer jose.Signer
+	jwks   []byte
+}
+
+func newTokenIssuer(t *testing.T) *tokenIssuer {
+	signer, jwks := newTokenSigner(t, "e2e")
+	return &tokenIssuer{signer: signer, jwks: jwks}
+}
+
+// newTokenSigner returns a signer with a new key, and a JWKS document with the public half of the key.
+func newTokenSigner(t *testing.T, keyID string) (jose.Signer, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate signing key: %v", err)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyID))
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       key.Public(),
+		KeyID:     keyID,
+		Algorithm: string(jose.ES256),
+		Use:       "sig",
+	}}})
+	if err != nil {
+		t.Fatalf("failed to marshal JWKS: %v", err)
+	}
+	return signer, jwks
+}
+
+// signToken returns a bearer token for a new user with the given roles, which expires after ttl.
+func signToken(t *testing.T, signer jose.Signer, user string, roles []string, ttl time.Duration) string {
+	t.Helper()
+	return signSubjectToken(t, signer, uuid.NewString(), user, roles, ttl)
+}
+
+// signSubjectToken returns a bearer token for the user with the given subject and preferred username.
+func signSubjectToken(t *testing.T, signer jose.Signer, subject, user string, roles []string, ttl time.Duration) string {
+	t.Helper()
+	now := time.Now()
+	token, err := jwt.Signed(signer).Claims(jwt.Claims{
+		Issuer:   tokenIssuerURL,
+		Subject:  subject,
+		Audience: jwt.Audience{tokenAudience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(ttl)),
+	}).Claims(map[string]any{
+		"preferred_username": user,
+		"roles":              roles,
+	}).Serialize()
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+// withBearerToken makes a client authenticate its requests with a bearer token.
+func withBearerToken(token string) vcrest.ClientOption {
+	return vcrest.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+func testBearerTokens(t *testing.T, ctx context.Context, serverURL string, issuer *tokenIssuer) {
+	newClient := func(token string) *vcrest.ClientWithResponses {
+		t.Helper()
+		c, err := vcrest.NewClientWithResponses(serverURL, withBearerToken(token))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		return c
+	}
+	expectCode := func(name string, status int, body []byte, wantStatus int, wantCode string) {
+		t.Helper()
+		var apiErr vcrest.Error
+		if status != wantStatus || json.Unmarshal(body, &apiErr) != nil || apiErr.Code != wantCode {
+			t.Errorf("%s: expected %d %s, got %d: %s", name, wantStatus, wantCode, status, string(body))
+		}
+	}
+	putWork := func(c *vcrest.ClientWithResponses) *vcrest.PutMovieWorkResponse {
+		t.Helper()
+		resp, err := c.PutMovieWorkWithResponse(ctx, openapi_types.UUID(uuid.New()), nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Bearer token test"),
+		})
+		if err != nil {
+			t.Fatalf("PutMovieWork failed: %v", err)
+		}
+		return resp
+	}
+	listWorks := func(c *vcrest.ClientWithResponses) *vcrest.ListWorksResponse {
+		t.Helper()
+		resp, err := c.ListWorksWithResponse(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListWorks failed: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("Viewer", func(t *testing.T) {
+		viewer := newClient(signToken(t, issuer.signer, "viewer", []string{"viewer"}, time.Hour))
+		if resp := listWorks(viewer); resp.StatusCode() != 200 {
+			t.Errorf("Expected viewer to list works, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		resp := putWork(viewer)
+		expectCode("Viewer writing", resp.StatusCode(), resp.Body, 403, "FORBIDDEN")
+	})
+
+	t.Run("Editor", func(t *testing.T) {
+		editor := newClient(signToken(t, issuer.signer, "editor", []string{"editor", "unrelated"}, time.Hour))
+		if resp := putWork(editor); resp.StatusCode() != 201 {
+			t.Errorf("Expected editor to create a work, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		resp, err := editor.ListApiKeysWithResponse(ctx)
+		if err != nil {
+			t.Fatalf("ListApiKeys failed: %v", err)
+		}
+		expectCode("Editor listing keys", resp.StatusCode(), resp.Body, 403, "FORBIDDEN")
+	})
+
+	t.Run("Identity", func(t *testing.T) {
+		currentUser := func(token string) vcrest.User {
+			t.Helper()
+			resp, err := newClient(token).GetCurrentUserWithResponse(ctx)
+			if err != nil {
+				t.Fatalf("GetCurrentUser failed: %v", err)
+			}
+			if resp.JSON200 == nil {
+				t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+			}
+			return *resp.JSON200
+		}
+		// Users are identified by their subject, whatever username they go by.
+		subject := uuid.NewString()
+		before := currentUser(signSubjectToken(t, issuer.signer, subject, "renamed-before", []string{"viewer"}, time.Hour))
+		after := currentUser(signSubjectToken(t, issuer.signer, subject, "renamed-after", []string{"viewer"}, time.Hour))
+		if before.Id != tokenIssuerURL+"|"+subject || after.Id != before.Id {
+			t.Errorf("Expected ID %s|%s for both usernames, got %s and %s", tokenIssuerURL, subject, before.Id, after.Id)
+		}
+		if before.Name != "renamed-before" || after.Name != "renamed-after" {
+			t.Errorf("Expected the preferred usernames as names, got %s and %s", before.Name, after.Name)
+		}
+		namesake := currentUser(signToken(t, issuer.signer, "renamed-before", []string{"viewer"}, time.Hour))
+		if namesake.Id == before.Id {
+			t.Errorf("Expected users with the same username to have different IDs, both got %s", before.Id)
+		}
+	})
+
+	t.Run("No known roles", func(t *testing.T) {
+		resp := listWorks(newClient(signToken(t, issuer.signer, "nobody", []string{"unrelated"}, time.Hour)))
+		expectCode("No roles", resp.StatusCode(), resp.Body, 403, "FORBIDDEN")
+	})
+
+	t.Run("Invalid tokens", func(t *testing.T) {
+		otherSigner, _ := newTokenSigner(t, "e2e")
+		for name, token := range map[string]string{
+			"Expired":       signToken(t, issuer.signer, "viewer", []string{"viewer"}, -time.Hour),
+			"Untrusted key": signToken(t, otherSigner, "viewer", []string{"viewer"}, time.Hour),
+			"Not a JWT":     "not-a-token",
+			"No subject":    signSubjectToken(t, issuer.signer, "", "viewer", []string{"viewer"}, time.Hour),
+		} {
+			resp := listWorks(newClient(token))
+			expectCode(name, resp.StatusCode(), resp.Body, 401, "UNAUTHENTICATED")
+		}
+	})
+}
+
+// withLibraryHeader scopes the requests of a client to a library using the X-Library header.
+func withLibraryHeader(id openapi_types.UUID) vcrest.ClientOption {
+	return vcrest.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("X-Library", id.String())
+		return nil
+	})
+}
+
+func testLibraries(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses, serverURL string) {
+	createLibrary := func(name string) *vcrest.Library {
+		t.Helper()
+		resp, err := client.CreateLibraryWithResponse(ctx, vcrest.CreateLibraryJSONRequestBody{Name: name})
+		if err != nil {
+			t.Fatalf("CreateLibrary failed: %v", err)
+		}
+		if resp.JSON201 == nil {
+			t.Fatalf("Expected 201, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		return resp.JSON201
+	}
+	expectCode := func(name string, status int, body []byte, wantStatus int, wantCode string) {
+		t.Helper()
+		var apiErr vcrest.Error
+		if status != wantStatus || json.Unmarshal(body, &apiErr) != nil || apiErr.Code != wantCode {
+			t.Errorf("%s: expected %d %s, got %d: %s", name, wantStatus, wantCode, status, string(body))
+		}
+	}
+
+	films := createLibrary("Films " + uuid.NewString())
+	shows := createLibrary("Shows " + uuid.NewString())
+
+	// The library of films is addressed by path, the library of shows by header.
+	filmsClient, err := vcrest.NewClientWithResponses(serverURL+"/libraries/"+films.Uuid.String(), withAPIKey(bootstrapAPIKey))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	showsClient, err := vcrest.NewClientWithResponses(serverURL, withAPIKey(bootstrapAPIKey), withLibraryHeader(shows.Uuid))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	t.Run("Get and list", func(t *testing.T) {
+		getResp, err := client.GetLibraryWithResponse(ctx, films.Uuid)
+		if err != nil {
+			t.Fatalf("GetLibrary failed: %v", err)
+		}
+		if getResp.JSON200 == nil || getResp.JSON200.Name != films.Name {
+			t.Errorf("Expected library %q, got %d: %s", films.Name, getResp.StatusCode(), string(getResp.Body))
+		}
+
+		missingResp, err := client.GetLibraryWithResponse(ctx, openapi_types.UUID(uuid.New()))
+		if err != nil {
+			t.Fatalf("GetLibrary failed: %v", err)
+		}
+		expectCode("Get unknown library", missingResp.StatusCode(), missingResp.Body, 404, "NOT_FOUND")
+
+		listResp, err := client.ListLibrariesWithResponse(ctx)
+		if err != nil {
+			t.Fatalf("ListLibraries failed: %v", err)
+		}
+		if listResp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", listResp.StatusCode(), string(listResp.Body))
+		}
+		names := map[string]bool{}
+		for _, library := range listResp.JSON200.Libraries {
+			names[library.Name] = true
+		}
+		for _, name := range []string{"default", films.Name, shows.Name} {
+			if !names[name] {
+				t.Errorf("Expected library %q in list, got %+v", name, listResp.JSON200.Libraries)
+			}
+		}
+	})
+
+	t.Run("Duplicate name", func(t *testing.T) {
+		resp, err := client.CreateLibraryWithResponse(ctx, vcrest.CreateLibraryJSONRequestBody{Name: films.Name})
+		if err != nil {
+			t.Fatalf("CreateLibrary failed: %v", err)
+		}
+		expectCode("Duplicate library name", resp.StatusCode(), resp.Body, 400, "INVALID_FIELD")
+	})
+
+	t.Run("Unknown library", func(t *testing.T) {
+		unknown, err := vcrest.NewClientWithResponses(serverURL, withAPIKey(bootstrapAPIKey), withLibraryHeader(openapi_types.UUID(uuid.New())))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		resp, err := unknown.ListWorksWithResponse(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListWorks failed: %v", err)
+		}
+		expectCode("Unknown library", resp.StatusCode(), resp.Body, 404, "NOT_FOUND")
+	})
+
+	t.Run("Isolation", func(t *testing.T) {
+		workUUID := openapi_types.UUID(uuid.New())
+		putResp, err := filmsClient.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Library Test Film"),
+		})
+		if err != nil {
+			t.Fatalf("PutMovieWork failed: %v", err)
+		}
+		if putResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201, got %d: %s", putResp.StatusCode(), string(putResp.Body))
+		}
+		sourceUUID := openapi_types.UUID(uuid.New())
+		sourceResp, err := filmsClient.PutFileSourceWithResponse(ctx, sourceUUID, nil, vcrest.PutFileSourceJSONRequestBody{
+			Path: nullable.NewNullableWithValue("/media/library-test.mkv"),
+		})
+		if err != nil {
+			t.Fatalf("PutFileSource failed: %v", err)
+		}
+		if sourceResp.StatusCode() != 201 {
+			t.Fatalf("Expected 201, got %d: %s", sourceResp.StatusCode(), string(sourceResp.Body))
+		}
+
+		getResp, err := filmsClient.GetWorkWithResponse(ctx, workUUID, nil)
+		if err != nil {
+			t.Fatalf("GetWork failed: %v", err)
+		}
+		if getResp.StatusCode() != 200 {
+			t.Errorf("Expected 200 in own library, got %d: %s", getResp.StatusCode(), string(getResp.Body))
+		}
+		for name, c := range map[string]*vcrest.ClientWithResponses{"other": showsClient, "default": client} {
+			getResp, err := c.GetWorkWithResponse(ctx, workUUID, nil)
+			if err != nil {
+				t.Fatalf("GetWork failed: %v", err)
+			}
+			if getResp.StatusCode() != 404 {
+				t.Errorf("Expected 404 in %s library, got %d: %s", name, getResp.StatusCode(), string(getResp.Body))
+			}
+
+			listResp, err := c.ListWorksWithResponse(ctx, nil)
+			if err != nil {
+				t.Fatalf("ListWorks failed: %v", err)
+			}
+			if listResp.JSON200 == nil {
+				t.Fatalf("Expected 200, got %d: %s", listResp.StatusCode(), string(listResp.Body))
+			}
+			for _, work := range listResp.JSON200.Works {
+				if work.Uuid == workUUID {
+					t.Errorf("Work from another library listed in %s library", name)
+				}
+			}
+		}
+
+		// The same UUID cannot be reused in another library.
+		conflictResp, err := showsClient.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Library Test Show"),
+		})
+		if err != nil {
+			t.Fatalf("PutMovieWork failed: %v", err)
+		}
+		expectCode("Put in other library", conflictResp.StatusCode(), conflictResp.Body, 409, "LIBRARY_CONFLICT")
+
+		deleteResp, err := showsClient.DeleteWorkWithResponse(ctx, workUUID)
+		if err != nil {
+			t.Fatalf("DeleteWork failed: %v", err)
+		}
+		if deleteResp.StatusCode() != 404 {
+			t.Errorf("Expected 404 deleting from other library, got %d: %s", deleteResp.StatusCode(), string(deleteResp.Body))
+		}
+
+		// Plans cannot link sources and works from another library.
+		planResp, err := showsClient.PutDirectPlanWithResponse(ctx, openapi_types.UUID(uuid.New()), nil, vcrest.PutDirectPlanJSONRequestBody{
+			SourceUuid: nullable.NewNullableWithValue(sourceUUID),
+			WorkUuid:   nullable.NewNullableWithValue(workUUID),
+		})
+		if err != nil {
+			t.Fatalf("PutDirectPlan failed: %v", err)
+		}
+		expectCode("Plan across libraries", planResp.StatusCode(), planResp.Body, 409, "REFERENCE_MISSING")
+
+		planResp, err = filmsClient.PutDirectPlanWithResponse(ctx, openapi_types.UUID(uuid.New()), nil, vcrest.PutDirectPlanJSONRequestBody{
+			SourceUuid: nullable.NewNullableWithValue(sourceUUID),
+			WorkUuid:   nullable.NewNullableWithValue(workUUID),
+		})
+		if err != nil {
+			t.Fatalf("PutDirectPlan failed: %v", err)
+		}
+		if planResp.StatusCode() != 201 {
+			t.Errorf("Expected 201 for plan within library, got %d: %s", planResp.StatusCode(), string(planResp.Body))
+		}
+	})
+
+	t.Run("Idempotency keys", func(t *testing.T) {
+		keyResp, err := client.CreateApiKeyWithResponse(ctx, vcrest.CreateApiKeyJSONRequestBody{Name: "other writer", Scopes: []string{"write"}})
+		if err != nil {
+			t.Fatalf("CreateApiKey failed: %v", err)
+		}
+		if keyResp.JSON201 == nil {
+			t.Fatalf("Expected 201, got %d: %s", keyResp.StatusCode(), string(keyResp.Body))
+		}
+		otherWriter, err := vcrest.NewClientWithResponses(serverURL+"/libraries/"+films.Uuid.String(), withAPIKey(keyResp.JSON201.Key))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		// The same key and request, sent to another library or by another caller, creates another source
+		// rather than replaying the first response.
+		key := uuid.NewString()
+		source := vcrest.CreateFileSourceJSONRequestBody{
+			Path: nullable.NewNullableWithValue("/media/keyed.mkv"),
+		}
+		seen := map[openapi_types.UUID]string{}
+		for name, c := range map[string]*vcrest.ClientWithResponses{
+			"films":        filmsClient,
+			"shows":        showsClient,
+			"other writer": otherWriter,
+		} {
+			resp, err := c.CreateFileSourceWithResponse(ctx, &vcrest.CreateFileSourceParams{IdempotencyKey: &key}, source)
+			if err != nil {
+				t.Fatalf("CreateFileSource failed: %v", err)
+			}
+			if resp.JSON201 == nil {
+				t.Fatalf("%s: expected 201, got %d: %s", name, resp.StatusCode(), string(resp.Body))
+			}
+			if other, ok := seen[resp.JSON201.Uuid]; ok {
+				t.Errorf("%s: expected a new source, got the one created for %s", name, other)
+			}
+			seen[resp.JSON201.Uuid] = name
+		}
+	})
+}
+
+func testWatchState(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses, serverURL string) {
+	putMovie := func(title string) openapi_types.UUID {
+		t.Helper()
+		id := openapi_types.UUID(uuid.New())
+		resp, err := client.PutMovieWorkWithResponse(ctx, id, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue(title),
+		})
+		if err != nil {
+			t.Fatalf("PutMovieWork failed: %v", err)
+		}
+		if resp.StatusCode() != 201 {
+			t.Fatalf("Expected 201, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		return id
+	}
+	getState := func(c *vcrest.ClientWithResponses, id openapi_types.UUID) *vcrest.WatchState {
+		t.Helper()
+		resp, err := c.GetWatchStateWithResponse(ctx, id)
+		if err != nil {
+			t.Fatalf("GetWatchState failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		return resp.JSON200
+	}
+	// listMine returns the watch states of all the works that ListMyWorks returns for the given filters.
+	listMine := func(c *vcrest.ClientWithResponses, params vcrest.ListMyWorksParams) map[openapi_types.UUID]vcrest.WatchState {
+		t.Helper()
+		result := map[openapi_types.UUID]vcrest.WatchState{}
+		for {
+			resp, err := c.ListMyWorksWithResponse(ctx, &params)
+			if err != nil {
+				t.Fatalf("ListMyWorks failed: %v", err)
+			}
+			if resp.JSON200 == nil {
+				t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+			}
+			for _, w := range resp.JSON200.Works {
+				result[w.Work.Uuid] = w.WatchState
+			}
+			if resp.JSON200.NextPageToken == nil {
+				return result
+			}
+			params.PageToken = resp.JSON200.NextPageToken
+		}
+	}
+
+	keyResp, err := client.CreateApiKeyWithResponse(ctx, vcrest.CreateApiKeyJSONRequestBody{Name: "watcher", Scopes: []string{"read"}})
+	if err != nil {
+		t.Fatalf("CreateApiKey failed: %v", err)
+	}
+	if keyResp.JSON201 == nil {
+		t.Fatalf("Expected 201, got %d: %s", keyResp.StatusCode(), string(keyResp.Body))
+	}
+	watcher, err := vcrest.NewClientWithResponses(serverURL, withAPIKey(keyResp.JSON201.Key))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	no, yes := false, true
+	four, six := int32(4), int32(6)
+
+	seen := putMovie("Watch State Seen")
+	ripped := putMovie("Watch State Ripped")
+	sourceUUID := openapi_types.UUID(uuid.New())
+	sourceResp, err := client.PutFileSourceWithResponse(ctx, sourceUUID, nil, vcrest.PutFileSourceJSONRequestBody{
+		Path: nullable.NewNullableWithValue("/media/watch-state-ripped.mkv"),
+	})
+	if err != nil {
+		t.Fatalf("PutFileSource failed: %v", err)
+	}
+	if sourceResp.StatusCode() != 201 {
+		t.Fatalf("Expected 201, got %d: %s", sourceResp.StatusCode(), string(sourceResp.Body))
+	}
+	planUUID := openapi_types.UUID(uuid.New())
+	planResp, err := client.PutDirectPlanWithResponse(ctx, planUUID, nil, vcrest.PutDirectPlanJSONRequestBody{
+		SourceUuid: nullable.NewNullableWithValue(sourceUUID),
+		WorkUuid:   nullable.NewNullableWithValue(ripped),
+	})
+	if err != nil {
+		t.Fatalf("PutDirectPlan failed: %v", err)
+	}
+	if planResp.StatusCode() != 201 {
+		t.Fatalf("Expected 201, got %d: %s", planResp.StatusCode(), string(planResp.Body))
+	}
+	completeResp, err := client.CompletePlanWithResponse(ctx, planUUID)
+	if err != nil {
+		t.Fatalf("CompletePlan failed: %v", err)
+	}
+	if completeResp.StatusCode() != 200 {
+		t.Fatalf("Expected 200, got %d: %s", completeResp.StatusCode(), string(completeResp.Body))
+	}
+
+	t.Run("Current user", func(t *testing.T) {
+		resp, err := watcher.GetCurrentUserWithResponse(ctx)
+		if err != nil {
+			t.Fatalf("GetCurrentUser failed: %v", err)
+		}
+		if resp.JSON200 == nil || resp.JSON200.Name != "watcher" || len(resp.JSON200.Scopes) != 1 || resp.JSON200.Scopes[0] != "read" {
+			t.Errorf("Expected watcher with read scope, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		if resp.JSON200 != nil && resp.JSON200.Id != "apikey:"+keyResp.JSON201.ApiKey.Uuid.String() {
+			t.Errorf("Expected the ID of the watcher key, got %s", resp.JSON200.Id)
+		}
+	})
+
+	t.Run("Unwatched by default", func(t *testing.T) {
+		state := getState(watcher, seen)
+		if state.Watched || state.PlayCount != 0 || state.LastWatchedAt != nil || state.Rating != nil || state.OnWatchlist || state.UpdatedAt != nil {
+			t.Errorf("Expected empty watch state, got %+v", state)
+		}
+
+		resp, err := watcher.GetWatchStateWithResponse(ctx, openapi_types.UUID(uuid.New()))
+		if err != nil {
+			t.Fatalf("GetWatchState failed: %v", err)
+		}
+		if resp.StatusCode() != 404 {
+			t.Errorf("Expected 404 for unknown work, got %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("Plays", func(t *testing.T) {
+		earlier := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+		resp, err := watcher.RecordPlayWithResponse(ctx, seen, vcrest.RecordPlayJSONRequestBody{})
+		if err != nil {
+			t.Fatalf("RecordPlay failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		latest := resp.JSON200.LastWatchedAt
+
+		// An earlier play counts, but does not move the last watched time back.
+		resp, err = watcher.RecordPlayWithResponse(ctx, seen, vcrest.RecordPlayJSONRequestBody{WatchedAt: &earlier})
+		if err != nil {
+			t.Fatalf("RecordPlay failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		state := resp.JSON200
+		if !state.Watched || state.PlayCount != 2 || latest == nil || state.LastWatchedAt == nil || !state.LastWatchedAt.Equal(*latest) {
+			t.Errorf("Expected two plays last watched at %v, got %+v", latest, state)
+		}
+
+		// Watch state is personal, even to another key with the same name.
+		if other := getState(client, seen); other.Watched || other.PlayCount != 0 {
+			t.Errorf("Expected work unwatched by another user, got %+v", other)
+		}
+		namesakeResp, err := client.CreateApiKeyWithResponse(ctx, vcrest.CreateApiKeyJSONRequestBody{Name: "watcher", Scopes: []string{"read"}})
+		if err != nil {
+			t.Fatalf("CreateApiKey failed: %v", err)
+		}
+		if namesakeResp.JSON201 == nil {
+			t.Fatalf("Expected 201, got %d: %s", namesakeResp.StatusCode(), string(namesakeResp.Body))
+		}
+		namesake, err := vcrest.NewClientWithResponses(serverURL, withAPIKey(namesakeResp.JSON201.Key))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		if other := getState(namesake, seen); other.Watched || other.PlayCount != 0 {
+			t.Errorf("Expected work unwatched by a key with the same name, got %+v", other)
+		}
+	})
+
+	t.Run("Put", func(t *testing.T) {
+		invalid, err := watcher.PutWatchStateWithResponse(ctx, seen, vcrest.PutWatchStateJSONRequestBody{Watched: true, PlayCount: 1, Rating: &six})
+		if err != nil {
+			t.Fatalf("PutWatchState failed: %v", err)
+		}
+		if invalid.StatusCode() != 400 {
+			t.Errorf("Expected 400 for rating out of range, got %d: %s", invalid.StatusCode(), string(invalid.Body))
+		}
+
+		resp, err := watcher.PutWatchStateWithResponse(ctx, seen, vcrest.PutWatchStateJSONRequestBody{Watched: true, PlayCount: 3, Rating: &four})
+		if err != nil {
+			t.Fatalf("PutWatchState failed: %v", err)
+		}
+		if resp.JSON200 == nil || resp.JSON200.PlayCount != 3 || resp.JSON200.Rating == nil || *resp.JSON200.Rating != 4 || resp.JSON200.LastWatchedAt != nil {
+			t.Errorf("Expected replaced watch state, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+
+		resp, err = watcher.PutWatchStateWithResponse(ctx, ripped, vcrest.PutWatchStateJSONRequestBody{OnWatchlist: true})
+		if err != nil {
+			t.Fatalf("PutWatchState failed: %v", err)
+		}
+		if resp.JSON200 == nil || !resp.JSON200.OnWatchlist || resp.JSON200.Watched {
+			t.Errorf("Expected work on watchlist, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+	})
+
+	t.Run("Queries", func(t *testing.T) {
+		kind := "movie"
+		unwatched := listMine(watcher, vcrest.ListMyWorksParams{Watched: &no, Kind: &kind, Completed: &yes})
+		if _, ok := unwatched[ripped]; !ok {
+			t.Errorf("Expected unwatched ripped movie in %v", unwatched)
+		}
+		if _, ok := unwatched[seen]; ok {
+			t.Errorf("Expected watched movie not to be listed as unwatched")
+		}
+
+		watchlist := listMine(watcher, vcrest.ListMyWorksParams{OnWatchlist: &yes})
+		if len(watchlist) != 1 || !watchlist[ripped].OnWatchlist {
+			t.Errorf("Expected only the ripped movie on the watchlist, got %v", watchlist)
+		}
+
+		rated := listMine(watcher, vcrest.ListMyWorksParams{MinRating: &four})
+		if state, ok := rated[seen]; len(rated) != 1 || !ok || state.PlayCount != 3 {
+			t.Errorf("Expected only the seen movie rated 4 stars, got %v", rated)
+		}
+
+		badKind := "series"
+		resp, err := watcher.ListMyWorksWithResponse(ctx, &vcrest.ListMyWorksParams{Kind: &badKind})
+		if err != nil {
+			t.Fatalf("ListMyWorks failed: %v", err)
+		}
+		if resp.StatusCode() != 400 {
+			t.Errorf("Expected 400 for unknown kind, got %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		resp, err := watcher.DeleteWatchStateWithResponse(ctx, seen)
+		if err != nil {
+			t.Fatalf("DeleteWatchState failed: %v", err)
+		}
+		if resp.StatusCode() != 204 {
+			t.Fatalf("Expected 204, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		if state := getState(watcher, seen); state.Watched || state.PlayCount != 0 || state.Rating != nil {
+			t.Errorf("Expected forgotten watch state, got %+v", state)
+		}
+	})
+}
+
+func testHealth(t *testing.T, ctx context.Context, serverURL string) {
+	// The probes are made without credentials, as an orchestrator would make them.
+	client, err := vcrest.NewClientWithResponses(serverURL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	t.Run("Health", func(t *testing.T) {
+		resp, err := client.GetHealthWithResponse(ctx)
+		if err != nil {
+			t.Fatalf("GetHealth failed: %v", err)
+		}
+		if resp.JSON200 == nil || resp.JSON200.Status != "ok" {
+			t.Errorf("Expected 200 ok, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+	})
+
+	t.Run("Readiness", func(t *testing.T) {
+		resp, err := client.GetReadinessWithResponse(ctx)
+		if err != nil {
+			t.Fatalf("GetReadiness failed: %v", err)
+		}
+		if resp.JSON200 == nil || !resp.JSON200.Ready {
+			t.Fatalf("Expected 200 ready, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		names := []string{}
+		for _, check := range resp.JSON200.Checks {
+			if !check.Ok {
+				t.Errorf("Expected check %s to pass", check.Name)
+			}
+			names = append(names, check.Name)
+		}
+		if !reflect.DeepEqual(names, []string{"database", "schema", "jobs"}) {
+			t.Errorf("Expected database, schema and jobs checks, got %v", names)
+		}
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		resp, err := client.GetVersionWithResponse(ctx)
+		if err != nil {
+			t.Fatalf("GetVersion failed: %v", err)
+		}
+		if resp.JSON200 == nil {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+		if resp.JSON200.Version != serverVersion || resp.JSON200.Commit == "" {
+			t.Errorf("Expected version %s with a commit, got %+v", serverVersion, resp.JSON200)
+		}
+		migrations, err := filepath.Glob("internal/migrations/*.up.sql")
+		if err != nil {
+			t.Fatalf("failed to list migrations: %v", err)
+		}
+		if resp.JSON200.SchemaVersion != int64(len(migrations)) {
+			t.Errorf("Expected schema version %d, got %d", len(migrations), resp.JSON200.SchemaVersion)
+		}
+	})
+}
+
+// testPurge runs the purge of the trash directly against the database, since the server only purges entities that
+// have been in the trash for longer than the retention.  Each purge is rolled back, so that other tests see the
+// trash as they left it; entities that must be gone for good are deleted from the database one by one.
+func testPurge(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses, db *internal.DatabaseConfig) {
+	pool, err := internal.NewDBPool(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	defer pool.Close()
+
+	// purge empties the whole trash and reports which of the given works and sources are left.
+	purge := func(t *testing.T, ids ...openapi_types.UUID) map[openapi_types.UUID]bool {
+		t.Helper()
+		txn, err := pool.Begin(ctx)
+		if err != nil {
+			t.Fatalf("failed to begin transaction: %v", err)
+		}
+		defer txn.Rollback(ctx)
+		if _, err := internal.PurgeDeleted(ctx, txn, time.Now().Add(time.Minute)); err != nil {
+			t.Fatalf("PurgeDeleted failed: %v", err)
+		}
+		left := map[openapi_types.UUID]bool{}
+		for _, id := range ids {
+			var exists bool
+			err := txn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM works WHERE uuid = $1) OR EXISTS (SELECT 1 FROM sources WHERE uuid = $1)`, uuid.UUID(id)).Scan(&exists)
+			if err != nil {
+				t.Fatalf("failed to query entity: %v", err)
+			}
+			left[id] = exists
+		}
+		return left
+	}
+
+	workUUID := openapi_types.UUID(uuid.New())
+	workResp, err := client.PutMovieWorkWithResponse(ctx, workUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+		Title: nullable.NewNullableWithValue("Purge test"),
+	})
+	if err != nil {
+		t.Fatalf("PutMovieWork failed: %v", err)
+	}
+	if workResp.StatusCode() != 201 {
+		t.Fatalf("Expected 201, got %d: %s", workResp.StatusCode(), string(workResp.Body))
+	}
+	sourceUUID := openapi_types.UUID(uuid.New())
+	sourceResp, err := client.PutFileSourceWithResponse(ctx, sourceUUID, nil, vcrest.PutFileSourceJSONRequestBody{
+		Path: nullable.NewNullableWithValue("/media/purge-test.mkv"),
+	})
+	if err != nil {
+		t.Fatalf("PutFileSource failed: %v", err)
+	}
+	if sourceResp.StatusCode() != 201 {
+		t.Fatalf("Expected 201, got %d: %s", sourceResp.StatusCode(), string(sourceResp.Body))
+	}
+	planUUID := openapi_types.UUID(uuid.New())
+	planResp, err := client.PutDirectPlanWithResponse(ctx, planUUID, nil, vcrest.PutDirectPlanJSONRequestBody{
+		SourceUuid: nullable.NewNullableWithValue(sourceUUID),
+		WorkUuid:   nullable.NewNullableWithValue(workUUID),
+	})
+	if err != nil {
+		t.Fatalf("PutDirectPlan failed: %v", err)
+	}
+	if planResp.StatusCode() != 201 {
+		t.Fatalf("Expected 201, got %d: %s", planResp.StatusCode(), string(planResp.Body))
+	}
+	deleteWork, err := client.DeleteWorkWithResponse(ctx, workUUID)
+	if err != nil {
+		t.Fatalf("DeleteWork failed: %v", err)
+	}
+	if deleteWork.StatusCode() != 200 {
+		t.Fatalf("Expected 200, got %d: %s", deleteWork.StatusCode(), string(deleteWork.Body))
+	}
+	deleteSource, err := client.DeleteSourceWithResponse(ctx, sourceUUID)
+	if err != nil {
+		t.Fatalf("DeleteSource failed: %v", err)
+	}
+	if deleteSource.StatusCode() != 200 {
+		t.Fatalf("Expected 200, got %d: %s", deleteSource.StatusCode(), string(deleteSource.Body))
+	}
+
+	t.Run("Referenced by a live plan", func(t *testing.T) {
+		left := purge(t, workUUID, sourceUUID)
+		if !left[workUUID] || !left[sourceUUID] {
+			t.Errorf("Expected the trashed work and source of a live plan to be kept, got %v", left)
+		}
+		getResp, err := client.GetPlanWithResponse(ctx, planUUID, nil)
+		if err != nil {
+			t.Fatalf("GetPlan failed: %v", err)
+		}
+		if getResp.JSON200 == nil {
+			t.Errorf("Expected the live plan to be intact, got %d: %s", getResp.StatusCode(), string(getResp.Body))
+		}
+	})
+
+	t.Run("Referenced by a trashed plan", func(t *testing.T) {
+		deletePlan, err := client.DeletePlanWithResponse(ctx, planUUID)
+		if err != nil {
+			t.Fatalf("DeletePlan failed: %v", err)
+		}
+		if deletePlan.StatusCode() != 200 {
+			t.Fatalf("Expected 200, got %d: %s", deletePlan.StatusCode(), string(deletePlan.Body))
+		}
+		// The plan is purged first, which frees its work and source.
+		left := purge(t, workUUID, sourceUUID)
+		if left[workUUID] || left[sourceUUID] {
+			t.Errorf("Expected the work and source to be purged along with their plan, got %v", left)
+		}
+	})
+
+	t.Run("Revert to a purged parent", func(t *testing.T) {
+		movieUUID := openapi_types.UUID(uuid.New())
+		editionUUID := openapi_types.UUID(uuid.New())
+		if resp, err := client.PutMovieWorkWithResponse(ctx, movieUUID, nil, vcrest.PutMovieWorkJSONRequestBody{
+			Title: nullable.NewNullableWithValue("Purged Parent"),
+		}); err != nil || resp.StatusCode() != 201 {
+			t.Fatalf("PutMovieWork failed: %v %v", err, resp)
+		}
+		if resp, err := client.PutMovieEditionWithResponse(ctx, editionUUID, nil, vcrest.PutMovieEditionJSONRequestBody{
+			EditionType: nullable.NewNullableWithValue("Extended"),
+			MovieUuid:   nullable.NewNullableWithValue(movieUUID),
+		}); err != nil || resp.StatusCode() != 201 {
+			t.Fatalf("PutMovieEdition failed: %v %v", err, resp)
+		}
+		if resp, err := client.PutMovieEditionWithResponse(ctx, editionUUID, nil, vcrest.PutMovieEditionJSONRequestBody{
+			EditionType: nullable.NewNullableWithValue("Extended"),
+		}); err != nil || resp.StatusCode() != 200 {
+			t.Fatalf("PutMovieEdition failed: %v %v", err, resp)
+		}
+		historyResp, err := client.GetWorkHistoryWithResponse(ctx, editionUUID, nil)
+		if err != nil {
+			t.Fatalf("GetWorkHistory failed: %v", err)
+		}
+		if historyResp.JSON200 == nil || len(historyResp.JSON200.Entries) != 2 {
+			t.Fatalf("Expected 2 history entries, got %d: %s", historyResp.StatusCode(), string(historyResp.Body))
+		}
+		created := historyResp.JSON200.Entries[1]
+
+		// The movie no longer has children, so the purge removes it once it is in the trash.
+		if resp, err := client.DeleteWorkWithResponse(ctx, movieUUID); err != nil || resp.StatusCode() != 200 {
+			t.Fatalf("DeleteWork failed: %v %v", err, resp)
+		}
+		if _, err := pool.Exec(ctx, `DELETE FROM works WHERE uuid = $1`, uuid.UUID(movieUUID)); err != nil {
+			t.Fatalf("failed to purge movie: %v", err)
+		}
+
+		resp, err := client.RevertWorkWithResponse(ctx, editionUUID, created.Id)
+		if err != nil {
+			t.Fatalf("RevertWork failed: %v", err)
+		}
+		var apiErr vcrest.Error
+		if resp.StatusCode() != 409 || json.Unmarshal(resp.Body, &apiErr) != nil || apiErr.Code != "REFERENCE_MISSING" {
+			t.Errorf("Expected 409 REFERENCE_MISSING for reverting to a purged parent, got %d: %s", resp.StatusCode(), string(resp.Body))
+		}
+	})
+}
+
+// maxBodyBytes is the request body size limit that the server container is started with.
+const maxBodyBytes = 1 << 20
+
+func testBodySize(t *testing.T, ctx context.Context, client *vcrest.ClientWithResponses) {
+	body, err := json.Marshal(vcrest.LibraryInput{Name: strings.Repeat("x", 2*maxBodyBytes)})
+	if err != nil {
+		t.Fatalf("failed to marshal library: %v", err)
+	}
+	expectTooLarge := func(t *testing.T, status int, respBody []byte) {
+		t.Helper()
+		var apiErr vcrest.Error
+		if status != http.StatusRequestEntityTooLarge || json.Unmarshal(respBody, &apiErr) != nil || apiErr.Code != "REQUEST_TOO_LARGE" {
+			t.Errorf("Expected 413 REQUEST_TOO_LARGE, got %d: %s", status, string(respBody))
+		}
+	}
+
+	t.Run("WithLength", func(t *testing.T) {
+		resp, err := client.CreateLibraryWithBodyWithResponse(ctx, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("CreateLibrary failed: %v", err)
+		}
+		expectTooLarge(t, resp.StatusCode(), resp.Body)
+	})
+
+	t.Run("Chunked", func(t *testing.T) {
+		// Hiding the length of the body makes the client send it in chunks, so the limit is only hit while
+		// the server reads it.
+		resp, err := client.CreateLibraryWithBodyWithResponse(ctx, "application/json", io.MultiReader(bytes.NewReader(body)))
+		if err != nil {
+			t.Fatalf("CreateLibrary failed: %v", err)
+		}
+		expectTooLarge(t, resp.StatusCode(), resp.Body)
+	})
+}
+
+// Starts the server container and all dependencies, and returns a URL string that can be used in client connections,
+// along with the configuration for connecting to the database from the host.  The server container can reach hostPort
+// on the host, for delivering webhooks, and trusts bearer tokens signed with the keys in jwks.
+func setup(t *testing.T, ctx context.Context, hostPort int, jwks []byte) (string, *internal.DatabaseConfig) {
+	// Create docker network.
+	net, err := network.New(ctx, network.WithCheckDuplicate())
+	if err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	networkName := net.Name
+
+	// Database configuration
+	const (
+		dbHost = "postgres"
+		dbPort = "5432"
+		dbName = "videocatalog"
+		dbUser = "videocataloguser"
+		dbPass = "videocatalogpass"
+	)
+
+	// Start Postgres container
+	postgresReq := testcontainers.ContainerRequest{
+		Image:        "postgres:16",
+		ExposedPorts: []string{"5432/tcp"},
+		Env: map[string]string{
+			"POSTGRES_DB":       dbName,
+			"POSTGRES_USER":     dbUser,
+			"POSTGRES_PASSWORD": dbPass,
+		},
+		Networks:       []string{networkName},
+		NetworkAliases: map[string][]string{networkName: {dbHost}},
+		WaitingFor:     wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+	}
+	postgresContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: postgresReq,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to start postgres container: %v", err)
+	}
+	t.Cleanup(func() {
+		dumpContainerLogs(t, ctx, postgresContainer, dbHost)
+	})
+	dbMappedPort, err := postgresContainer.MappedPort(ctx, "5432")
+	if err != nil {
+		t.Fatalf("failed to get postgres mapped port: %v", err)
+	}
+	dbMappedHost, err := postgresContainer.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get postgres host: %v", err)
+	}
+	db := &internal.DatabaseConfig{
+		Host:     dbMappedHost,
+		Port:     dbMappedPort.Int(),
+		User:     dbUser,
+		Password: dbPass,
+		Name:     dbName,
+	}
+
+	// Build and start the server container
+	version := serverVersion
+	serverReq := testcontainers.ContainerRequest{
+		FromDockerfile: testcontainers.FromDockerfile{
+			Context:    ".",
+			Dockerfile: "Dockerfile",
+			BuildArgs:  map[string]*string{"VERSION": &version},
+		},
+		ExposedPorts: []string{"8080/tcp"},
+		Env: map[string]string{
+			"VC_SERVER_PORT": "8080",
+			"VC_DB_HOST":     dbHost,
+			"VC_DB_PORT":     dbPort,
+			"VC_DB_NAME":     dbName,
+			"VC_DB_USER":     dbUser,
+			"VC_DB_PASSWORD": dbPass,
+			// Check every response the tests receive against the API specification.
+			"VC_VALIDATE_RESPONSES": "true",
+			"VC_BOOTSTRAP_API_KEY":  bootstrapAPIKey,
+			"VC_OIDC_ISSUER":        tokenIssuerURL,
+			"VC_OIDC_AUDIENCE":      tokenAudience,
+			"VC_OIDC_JWKS":          "/app/jwks.json",
+			"VC_MAX_BODY_BYTES":     strconv.Itoa(maxBodyBytes),
+		},
+		Files: []testcontainers.ContainerFile{{
+			Reader:            bytes.NewReader(jwks),
+			ContainerFilePath: "/app/jwks.json",
+			FileMode:          0o644,
+		}},
+		Networks:        []string{networkName},
+		NetworkAliases:  map[string][]string{networkName: {"server"}},
+		HostAccessPorts: []int{hostPort},
+		WaitingFor:      wait.ForHTTP("/readyz").WithPort("8080/tcp"),
 	}
 	serverContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
 		ContainerRequest: serverReq,
@@ -727,7 +4600,7 @@ func setup(t *testing.T, ctx context.Context) string {
 		t.Fatalf("failed to get server host: %v", err)
 	}
 
-	return fmt.Sprintf("http://%s:%s", serverHost, mappedPort.Port())
+	return fmt.Sprintf("http://%s:%s", serverHost, mappedPort.Port()), db
 }
 
 // dumpContainerLogs reads and logs all output from a container
@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// Scopes of an API key.  Each scope allows everything that the scopes before it allow.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// scopeRanks orders the scopes, so that a key with one scope is allowed to do what lower scopes allow.
+var scopeRanks = map[string]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+// ErrUnauthenticated is returned when a request does not carry a valid, unrevoked API key or a valid
+// bearer token.
+var ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+// apiKeyPrefix starts every API key, so that leaked keys are easy to recognize.
+const apiKeyPrefix = "vck_"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// ID identifies the caller for good: "apikey:" followed by the UUID of an API key, or the issuer and
+	// subject of a bearer token separated by "|".  Unlike the name, no other caller can take it, so it is
+	// recorded as the actor of the caller's changes.
+	ID string
+	// Name is what the caller is shown as: the name of an API key, or the preferred username of a bearer
+	// token.  Several callers can have the same name.
+	Name   string
+	Scopes []string
+}
+
+// apiKeyPrincipalID returns the ID of the principal that uses the API key with the given UUID.
+func apiKeyPrincipalID(id string) string {
+	return "apikey:" + id
+}
+
+// Allows reports whether the principal has a scope that covers the given one.
+func (p Principal) Allows(scope string) bool {
+	required, ok := scopeRanks[scope]
+	if !ok {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if scopeRanks[s] >= required {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated caller.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated caller carried by ctx, if any.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// ValidateAPIKey checks that an API key can be issued.
+func ValidateAPIKey(in *vcrest.ApiKeyInput) error {
+	if in.Name == "" {
+		return NewFieldError("name", ErrEmpty)
+	}
+	if len(in.Scopes) == 0 {
+		return NewFieldError("scopes", ErrEmpty)
+	}
+	for i, scope := range in.Scopes {
+		if _, ok := scopeRanks[scope]; !ok {
+			return NewFieldError(fmt.Sprintf("scopes[%d]", i), fmt.Errorf("%w, expected read, write or admin", ErrInvalid))
+		}
+	}
+	return nil
+}
+
+// hashAPIKey returns the hash under which an API key is stored.  Keys are long and random, so a fast
+// unsalted hash is enough.
+func hashAPIKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// CreateAPIKey issues a new API key, returning it along with its API representation.
+func CreateAPIKey(ctx context.Context, q Querier, in *vcrest.ApiKeyInput) (*vcrest.CreatedApiKey, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	scopes := slices.Compact(slices.Sorted(slices.Values(in.Scopes)))
+	created := &vcrest.CreatedApiKey{
+		ApiKey: vcrest.ApiKey{
+			Uuid:   openapi_types.UUID(uuid.New()),
+			Name:   in.Name,
+			Scopes: scopes,
+		},
+		Key: key,
+	}
+	err := q.QueryRow(ctx, `
+		INSERT INTO api_keys (uuid, name, key_hash, scopes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at`, uuid.UUID(created.ApiKey.Uuid), in.Name, hashAPIKey(key), scopes).Scan(&created.ApiKey.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert API key: %w", err)
+	}
+	return created, nil
+}
+
+// GetAPIKey returns the API representation of an API key.  Returns ErrNotFound if the key does not exist.
+func GetAPIKey(ctx context.Context, q Querier, id uuid.UUID) (*vcrest.ApiKey, error) {
+	apiKey := &vcrest.ApiKey{
+		Uuid: openapi_types.UUID(id),
+	}
+	err := q.QueryRow(ctx, `
+		SELECT name, scopes, created_at, last_used_at, revoked_at
+		FROM api_keys
+		WHERE uuid = $1`, id).Scan(&apiKey.Name, &apiKey.Scopes, &apiKey.CreatedAt, &apiKey.LastUsedAt, &apiKey.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query API key: %w", err)
+	}
+	return apiKey, nil
+}
+
+// ListAPIKeys returns the API representations of all API keys, including revoked ones, ordered by UUID.
+func ListAPIKeys(ctx context.Context, tx pgx.Tx) ([]vcrest.ApiKey, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT uuid, name, scopes, created_at, last_used_at, revoked_at
+		FROM api_keys
+		ORDER BY uuid`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+
+	apiKeys := []vcrest.ApiKey{}
+	var apiKey vcrest.ApiKey
+	var id uuid.UUID
+	_, err = pgx.ForEachRow(rows, []any{&id, &apiKey.Name, &apiKey.Scopes, &apiKey.CreatedAt, &apiKey.LastUsedAt, &apiKey.RevokedAt}, func() error {
+		apiKey.Uuid = openapi_types.UUID(id)
+		apiKeys = append(apiKeys, apiKey)
+		// Start the next row afresh, so that it does not share pointers with this one.
+		apiKey = vcrest.ApiKey{}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan API keys: %w", err)
+	}
+	return apiKeys, nil
+}
+
+// RevokeAPIKey stops an API key from being used and returns its API representation.  Revoking a key
+// that is already revoked keeps the original revocation time.  Returns ErrNotFound if the key does not exist.
+func RevokeAPIKey(ctx context.Context, q Querier, id uuid.UUID) (*vcrest.ApiKey, error) {
+	apiKey := &vcrest.ApiKey{
+		Uuid: openapi_types.UUID(id),
+	}
+	err := q.QueryRow(ctx, `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, now())
+		WHERE uuid = $1
+		RETURNING name, scopes, created_at, last_used_at, revoked_at`, id).Scan(&apiKey.Name, &apiKey.Scopes, &apiKey.CreatedAt, &apiKey.LastUsedAt, &apiKey.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return apiKey, nil
+}
+
+// AuthenticateAPIKey returns the principal that an API key belongs to, and notes that the key was used.
+// The last use is only updated once a minute, so that busy keys do not cause a write on every request.
+// Returns ErrUnauthenticated if the key does not exist or has been revoked.
+func AuthenticateAPIKey(ctx context.Context, q Querier, key string) (Principal, error) {
+	var p Principal
+	var id uuid.UUID
+	err := q.QueryRow(ctx, `
+		WITH key AS (
+			SELECT uuid, name, scopes, last_used_at
+			FROM api_keys
+			WHERE key_hash = $1 AND revoked_at IS NULL
+		), touched AS (
+			UPDATE api_keys
+			SET last_used_at = now()
+			FROM key
+			WHERE api_keys.uuid = key.uuid
+				AND (key.last_used_at IS NULL OR key.last_used_at < now() - INTERVAL '1 minute')
+		)
+		SELECT uuid, name, scopes FROM key`, hashAPIKey(key)).Scan(&id, &p.Name, &p.Scopes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Principal{}, ErrUnauthenticated
+	} else if err != nil {
+		return Principal{}, fmt.Errorf("failed to authenticate API key: %w", err)
+	}
+	p.ID = apiKeyPrincipalID(id.String())
+	return p, nil
+}
@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// tokenAlgorithms are the signature algorithms accepted for bearer tokens.  Symmetric algorithms are
+// left out, since the keys are public.
+var tokenAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+const (
+	// tokenLeeway allows for clock skew between the server and the identity provider.
+	tokenLeeway = time.Minute
+	// keyRefreshInterval limits how often the keys are reloaded because a token names a key that is not
+	// known, so that tokens with made-up key IDs cannot make the server hammer the identity provider.
+	keyRefreshInterval = time.Minute
+	jwksTimeout        = 10 * time.Second
+)
+
+// TokenVerifier checks bearer tokens issued by an identity provider, and maps their roles to scopes.
+type TokenVerifier struct {
+	config *OIDCConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	keys     *jose.JSONWebKeySet
+	loadedAt time.Time
+}
+
+func NewTokenVerifier(config *OIDCConfig) *TokenVerifier {
+	return &TokenVerifier{
+		config: config,
+		client: &http.Client{Timeout: jwksTimeout},
+	}
+}
+
+// Verify returns the principal that a bearer token was issued to.  Returns ErrUnauthenticated if the
+// token is malformed, is not signed by the identity provider, has expired, or was issued for someone else.
+func (v *TokenVerifier) Verify(ctx context.Context, token string) (Principal, error) {
+	parsed, err := jwt.ParseSigned(token, tokenAlgorithms)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed bearer token: %v", ErrUnauthenticated, err)
+	}
+	key, err := v.key(ctx, parsed.Headers[0].KeyID)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	var claims jwt.Claims
+	var other map[string]any
+	if err := parsed.Claims(key, &claims, &other); err != nil {
+		return Principal{}, fmt.Errorf("%w: bearer token signature: %v", ErrUnauthenticated, err)
+	}
+	expected := jwt.Expected{Issuer: v.config.Issuer, Time: time.Now()}
+	if v.config.Audience != "" {
+		expected.AnyAudience = jwt.Audience{v.config.Audience}
+	}
+	if err := claims.ValidateWithLeeway(expected, tokenLeeway); err != nil {
+		return Principal{}, fmt.Errorf("%w: bearer token: %v", ErrUnauthenticated, err)
+	}
+	if claims.Expiry == nil {
+		return Principal{}, fmt.Errorf("%w: bearer token does not expire", ErrUnauthenticated)
+	}
+
+	// The subject is the only claim that the issuer keeps unique and unchanging for a user, so it alone
+	// identifies the caller.  The preferred username is only shown, since users can often change it.
+	if claims.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: bearer token has no subject", ErrUnauthenticated)
+	}
+	p := Principal{ID: claims.Issuer + "|" + claims.Subject, Name: claims.Subject}
+	if name, ok := other["preferred_username"].(string); ok && name != "" {
+		p.Name = name
+	}
+	for _, role := range claimStrings(other, v.config.RolesClaim) {
+		if scope, ok := v.config.RoleScopes[role]; ok && !slices.Contains(p.Scopes, scope) {
+			p.Scopes = append(p.Scopes, scope)
+		}
+	}
+	return p, nil
+}
+
+// claimStrings returns the strings in a claim, which may be a single string or a list of them.  Dots in
+// path separate the names of nested claims.
+func claimStrings(claims map[string]any, path string) []string {
+	var value any = claims
+	for name := range strings.SplitSeq(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var result []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// key returns the public key that a token was signed with, reloading the keys if kid is not among them
+// so that keys rotated by the identity provider are picked up.
+func (v *TokenVerifier) key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key := findKey(v.keys, kid); key != nil {
+		return key, nil
+	}
+	if time.Since(v.loadedAt) < keyRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown bearer token key %q", ErrUnauthenticated, kid)
+	}
+	keys, err := v.loadKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v.keys, v.loadedAt = keys, time.Now()
+	if key := findKey(v.keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown bearer token key %q", ErrUnauthenticated, kid)
+}
+
+// findKey returns the signing key with the given ID, or the only signing key if the token does not name
+// one.  Returns nil if there is no such key.
+func findKey(keys *jose.JSONWebKeySet, kid string) *jose.JSONWebKey {
+	if keys == nil {
+		return nil
+	}
+	var candidates []jose.JSONWebKey
+	for _, key := range keys.Keys {
+		if key.Use != "enc" && (kid == "" || key.KeyID == kid) {
+			candidates = append(candidates, key)
+		}
+	}
+	if len(candidates) != 1 {
+		return nil
+	}
+	return &candidates[0]
+}
+
+// loadKeys reads the keys from the configured file or URL, or from the URL in the issuer's OpenID
+// configuration.
+func (v *TokenVerifier) loadKeys(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	location := v.config.JWKS
+	if location == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.fetchJSON(ctx, strings.TrimSuffix(v.config.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return nil, fmt.Errorf("failed to discover JWKS URL: %w", err)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, errors.New("OpenID configuration of the issuer has no jwks_uri")
+		}
+		location = discovery.JWKSURI
+	}
+
+	keys := &jose.JSONWebKeySet{}
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		if err := v.fetchJSON(ctx, location, keys); err != nil {
+			return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+		}
+		return keys, nil
+	}
+	data, err := os.ReadFile(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	if err := json.Unmarshal(data, keys); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file: %w", err)
+	}
+	return keys, nil
+}
+
+func (v *TokenVerifier) fetchJSON(ctx context.Context, url string, dest any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, dest)
+}
@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// Operations recorded in the change feed.
+const (
+	ChangeUpsert = "upsert"
+	ChangeDelete = "delete"
+)
+
+// ChangeCursor is a position in the change feed.  The feed is ordered by writing transaction and then by
+// sequence number, because sequence numbers are assigned before their transactions commit and so are not
+// in commit order on their own.  The zero value is the start of the feed.
+type ChangeCursor struct {
+	TxID uint64
+	Seq  int64
+}
+
+// ChangeEntry is a change read from the feed, along with the cursor that follows it.
+type ChangeEntry struct {
+	Cursor ChangeCursor
+	Change vcrest.Change
+}
+
+// visibleChanges limits a query of the changes table to changes made by transactions older than every
+// transaction still in progress, so that no change can later appear before one that has been read.
+const visibleChanges = `txid < pg_snapshot_xmin(pg_current_snapshot())`
+
+// LatestChangeCursor returns the cursor that follows the last change in the feed.
+func LatestChangeCursor(ctx context.Context, q Querier) (ChangeCursor, error) {
+	var cursor ChangeCursor
+	var txid string
+	err := q.QueryRow(ctx, `
+		SELECT txid::text, seq
+		FROM changes
+		WHERE `+visibleChanges+`
+		ORDER BY txid DESC, seq DESC
+		LIMIT 1`).Scan(&txid, &cursor.Seq)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ChangeCursor{}, nil
+	} else if err != nil {
+		return ChangeCursor{}, fmt.Errorf("failed to query changes: %w", err)
+	}
+	cursor.TxID, err = strconv.ParseUint(txid, 10, 64)
+	if err != nil {
+		return ChangeCursor{}, fmt.Errorf("invalid transaction ID %q: %w", txid, err)
+	}
+	return cursor, nil
+}
+
+// LoadChanges returns up to limit changes to entities in the library of ctx after the cursor, with the
+// current representation of each entity that was upserted.
+func LoadChanges(ctx context.Context, tx pgx.Tx, after ChangeCursor, limit int) ([]ChangeEntry, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT seq, txid::text, entity_type, entity_uuid, kind, library_uuid, operation, created_at
+		FROM changes
+		WHERE (txid, seq) > ($1::text::xid8, $2)
+			AND `+visibleChanges+`
+			AND `+LibraryCondition("library_uuid", 4)+`
+		ORDER BY txid, seq
+		LIMIT $3`, strconv.FormatUint(after.TxID, 10), after.Seq, limit, LibraryFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query changes: %w", err)
+	}
+
+	entries := []ChangeEntry{}
+	var change vcrest.Change
+	var txid string
+	var entityUUID, libraryUUID uuid.UUID
+	var kind *string
+	var changedAt time.Time
+	_, err = pgx.ForEachRow(rows, []any{&change.Seq, &txid, &change.Type, &entityUUID, &kind, &libraryUUID, &change.Operation, &changedAt}, func() error {
+		change.Uuid = openapi_types.UUID(entityUUID)
+		change.LibraryUuid = openapi_types.UUID(libraryUUID)
+		change.Kind = kind
+		change.ChangedAt = changedAt
+		txID, err := strconv.ParseUint(txid, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid transaction ID %q: %w", txid, err)
+		}
+		entries = append(entries, ChangeEntry{
+			Cursor: ChangeCursor{TxID: txID, Seq: change.Seq},
+			Change: change,
+		})
+		kind = nil
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan changes: %w", err)
+	}
+
+	// Embed the entities that were upserted, reading each type with one query.  An entity that has since
+	// been moved to the trash is left out; a later delete in the feed says so.
+	var workIDs, sourceIDs, planIDs []uuid.UUID
+	for _, entry := range entries {
+		change := entry.Change
+		if change.Operation != ChangeUpsert {
+			continue
+		}
+		switch change.Type {
+		case "work":
+			workIDs = append(workIDs, uuid.UUID(change.Uuid))
+		case "source":
+			sourceIDs = append(sourceIDs, uuid.UUID(change.Uuid))
+		case "plan":
+			planIDs = append(planIDs, uuid.UUID(change.Uuid))
+		}
+	}
+	works := map[openapi_types.UUID]*vcrest.Work{}
+	if len(workIDs) > 0 {
+		loaded, err := loadWorks(ctx, tx, "uuid", workIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, work := range loaded {
+			works[work.Uuid] = work
+		}
+	}
+	sources := map[openapi_types.UUID]*vcrest.Source{}
+	if len(sourceIDs) > 0 {
+		loaded, err := loadSources(ctx, tx, "uuid", sourceIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, source := range loaded {
+			sources[source.Uuid] = source
+		}
+	}
+	plans := map[openapi_types.UUID]*vcrest.Plan{}
+	if len(planIDs) > 0 {
+		loaded, err := loadPlans(ctx, tx, "uuid", planIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, plan := range loaded {
+			plans[plan.Uuid] = plan
+		}
+	}
+	for i := range entries {
+		change := &entries[i].Change
+		if change.Operation != ChangeUpsert {
+			continue
+		}
+		change.Work = works[change.Uuid]
+		change.Source = sources[change.Uuid]
+		change.Plan = plans[change.Uuid]
+	}
+	return entries, nil
+}
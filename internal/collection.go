@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/vcrest"
+	"github.com/oapi-codegen/nullable"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type Collection struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+// ToAPI converts the Collection to its API representation.
+func (c *Collection) ToAPI() *vcrest.CollectionDetails {
+	result := &vcrest.CollectionDetails{
+		Name: nullable.NewNullableWithValue(c.Name),
+	}
+	if c.Description != nil {
+		result.Description = nullable.NewNullableWithValue(*c.Description)
+	}
+	return result
+}
+
+// CollectionToAPI converts a row from the collections table to its API representation.
+// The works in the collection are not included.
+func CollectionToAPI(id uuid.UUID, body json.RawMessage) (*vcrest.Collection, error) {
+	var collectionBody Collection
+	if err := json.Unmarshal(body, &collectionBody); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal collection body: %w", err)
+	}
+	return &vcrest.Collection{
+		Uuid:    openapi_types.UUID(id),
+		Details: collectionBody.ToAPI(),
+	}, nil
+}
+
+// lockCollection locks the collection row so that concurrent changes to the order of its works
+// are serialized.  Returns ErrNotFound if the collection does not exist.
+func lockCollection(ctx context.Context, tx pgx.Tx, collectionUUID uuid.UUID) error {
+	var one int
+	err := tx.QueryRow(ctx, `SELECT 1 FROM collections WHERE uuid = $1 FOR UPDATE`, collectionUUID).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: collection %s", ErrNotFound, collectionUUID)
+	} else if err != nil {
+		return fmt.Errorf("failed to lock collection: %w", err)
+	}
+	return nil
+}
+
+// PutCollectionWork adds a work to a collection at the given zero-based position, or moves it
+// there if it is already a member.  Positions past the end of the collection are clamped.  If
+// position is nil, new members are added at the end and existing members are left in place.
+// Returns true if the work was added.  Returns ErrNotFound if the collection does not exist or the work does
+// not exist in the library of ctx.
+func PutCollectionWork(ctx context.Context, tx pgx.Tx, collectionUUID, workUUID uuid.UUID, position *int32) (bool, error) {
+	if err := lockCollection(ctx, tx, collectionUUID); err != nil {
+		return false, err
+	}
+
+	var workExists bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM works WHERE uuid = $1 AND deleted_at IS NULL AND `+LibraryCondition("library_uuid", 2)+`)`,
+		workUUID, LibraryFromContext(ctx)).Scan(&workExists)
+	if err != nil {
+		return false, fmt.Errorf("failed to query work: %w", err)
+	} else if !workExists {
+		return false, fmt.Errorf("%w: work %s", ErrNotFound, workUUID)
+	}
+
+	var count int32
+	var current *int32
+	err = tx.QueryRow(ctx, `
+		SELECT
+			count(*),
+			max(position) FILTER (WHERE work_uuid = $2)
+		FROM collection_works
+		WHERE collection_uuid = $1
+	`, collectionUUID, workUUID).Scan(&count, &current)
+	if err != nil {
+		return false, fmt.Errorf("failed to query collection works: %w", err)
+	}
+
+	// Work out where the work should end up.
+	var target int32
+	switch {
+	case position == nil && current != nil:
+		return false, nil
+	case position == nil:
+		target = count
+	case current != nil:
+		target = min(*position, count-1)
+	default:
+		target = min(*position, count)
+	}
+
+	if current != nil {
+		// Close the gap left by the work at its current position.
+		_, err = tx.Exec(ctx, `
+			UPDATE collection_works
+			SET position = position - 1
+			WHERE collection_uuid = $1 AND position > $2
+		`, collectionUUID, *current)
+		if err != nil {
+			return false, fmt.Errorf("failed to update collection works: %w", err)
+		}
+	}
+
+	// Open a gap at the target position.
+	_, err = tx.Exec(ctx, `
+		UPDATE collection_works
+		SET position = position + 1
+		WHERE collection_uuid = $1 AND position >= $2 AND work_uuid <> $3
+	`, collectionUUID, target, workUUID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update collection works: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO collection_works (collection_uuid, work_uuid, position)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (collection_uuid, work_uuid) DO UPDATE
+		SET position = EXCLUDED.position
+	`, collectionUUID, workUUID, target)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert collection work: %w", err)
+	}
+	return current == nil, nil
+}
+
+// DeleteCollectionWork removes a work from a collection, shifting later works up.
+// Removing a work that is not a member succeeds.  Returns ErrNotFound if the collection does not exist.
+func DeleteCollectionWork(ctx context.Context, tx pgx.Tx, collectionUUID, workUUID uuid.UUID) error {
+	if err := lockCollection(ctx, tx, collectionUUID); err != nil {
+		return err
+	}
+
+	var position int32
+	err := tx.QueryRow(ctx, `
+		DELETE FROM collection_works
+		WHERE collection_uuid = $1 AND work_uuid = $2
+		RETURNING position
+	`, collectionUUID, workUUID).Scan(&position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to delete collection work: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE collection_works
+		SET position = position - 1
+		WHERE collection_uuid = $1 AND position > $2
+	`, collectionUUID, position)
+	if err != nil {
+		return fmt.Errorf("failed to update collection works: %w", err)
+	}
+	return nil
+}
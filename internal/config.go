@@ -5,25 +5,110 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 var (
-	ErrPanicEnvNotSet = errors.New("environment variable not set")
-	ErrPanicEnvNotInt = errors.New("environment variable is not an integer")
+	ErrPanicEnvNotSet      = errors.New("environment variable not set")
+	ErrPanicEnvNotInt      = errors.New("environment variable is not an integer")
+	ErrPanicEnvNotDuration = errors.New("environment variable is not a duration")
+	ErrPanicEnvNotBool     = errors.New("environment variable is not a boolean")
+	ErrPanicEnvNotRoles    = errors.New("environment variable is not a list of role=scope pairs")
 )
 
 const (
-	EnvServerPort       = "VC_SERVER_PORT"
-	EnvDatabaseHost     = "VC_DB_HOST"
-	EnvDatabasePort     = "VC_DB_PORT"
-	EnvDatabaseUser     = "VC_DB_USER"
-	EnvDatabasePassword = "VC_DB_PASSWORD"
-	EnvDatabaseName     = "VC_DB_NAME"
+	EnvServerPort        = "VC_SERVER_PORT"
+	EnvDatabaseHost      = "VC_DB_HOST"
+	EnvDatabasePort      = "VC_DB_PORT"
+	EnvDatabaseUser      = "VC_DB_USER"
+	EnvDatabasePassword  = "VC_DB_PASSWORD"
+	EnvDatabaseName      = "VC_DB_NAME"
+	EnvTrashRetention    = "VC_TRASH_RETENTION"
+	EnvValidateResponses = "VC_VALIDATE_RESPONSES"
+	EnvBootstrapAPIKey   = "VC_BOOTSTRAP_API_KEY"
+	EnvOIDCIssuer        = "VC_OIDC_ISSUER"
+	EnvOIDCAudience      = "VC_OIDC_AUDIENCE"
+	EnvOIDCJWKS          = "VC_OIDC_JWKS"
+	EnvOIDCRolesClaim    = "VC_OIDC_ROLES_CLAIM"
+	EnvOIDCRoleScopes    = "VC_OIDC_ROLE_SCOPES"
+	EnvReadTimeout       = "VC_READ_TIMEOUT"
+	EnvWriteTimeout      = "VC_WRITE_TIMEOUT"
+	EnvIdleTimeout       = "VC_IDLE_TIMEOUT"
+	EnvShutdownTimeout   = "VC_SHUTDOWN_TIMEOUT"
+	EnvMaxBodyBytes      = "VC_MAX_BODY_BYTES"
+)
+
+// DefaultTrashRetention is how long deleted entities are kept when EnvTrashRetention is not set.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// Defaults for how the roles of a bearer token are found and mapped to scopes, when EnvOIDCRolesClaim
+// and EnvOIDCRoleScopes are not set.
+const (
+	DefaultOIDCRolesClaim = "roles"
+	DefaultOIDCRoleScopes = "viewer=read,editor=write,admin=admin"
+)
+
+// Defaults for the limits of the HTTP server, when EnvReadTimeout, EnvWriteTimeout, EnvIdleTimeout,
+// EnvShutdownTimeout and EnvMaxBodyBytes are not set.
+const (
+	DefaultReadTimeout     = 30 * time.Second
+	DefaultWriteTimeout    = 60 * time.Second
+	DefaultIdleTimeout     = 120 * time.Second
+	DefaultShutdownTimeout = 30 * time.Second
+	DefaultMaxBodyBytes    = 10 << 20
 )
 
 type Config struct {
 	ServerPort int
 	Database   *DatabaseConfig
+
+	// TrashRetention is how long soft-deleted entities are kept before they are purged.
+	TrashRetention time.Duration
+
+	// ValidateResponses makes the server check its own responses against the API specification,
+	// which is meant for tests.  Requests are always validated.
+	ValidateResponses bool
+
+	// BootstrapAPIKey is an API key with the admin scope that is not stored in the database, for issuing
+	// the first real keys.  It is disabled if empty.
+	BootstrapAPIKey string
+
+	// OIDC configures authentication with bearer tokens from an identity provider.  It is nil if
+	// EnvOIDCIssuer is not set, in which case only API keys are accepted.
+	OIDC *OIDCConfig
+
+	// ReadTimeout bounds reading a whole request, including its body.  Zero means no limit.
+	ReadTimeout time.Duration
+
+	// WriteTimeout bounds handling a request and writing its response.  It does not apply to event
+	// streams, which stay open until the client goes away.  Zero means no limit.
+	WriteTimeout time.Duration
+
+	// IdleTimeout is how long a kept-alive connection may wait for its next request.  Zero means no limit.
+	IdleTimeout time.Duration
+
+	// ShutdownTimeout is how long the server waits on SIGTERM or SIGINT for in-flight requests and
+	// background jobs to finish before it cancels them.
+	ShutdownTimeout time.Duration
+
+	// MaxBodyBytes is the largest request body that the server accepts.  Zero means no limit.
+	MaxBodyBytes int64
+}
+
+type OIDCConfig struct {
+	// Issuer must match the iss claim of tokens.
+	Issuer string
+	// Audience must be one of the aud claims of tokens, unless it is empty.
+	Audience string
+	// JWKS is the URL or file path of the keys that tokens are signed with.  If empty, the URL is
+	// discovered from the issuer's OpenID configuration.
+	JWKS string
+	// RolesClaim is the claim that lists the roles of the caller.  Dots separate the names of nested
+	// claims, as in "realm_access.roles".
+	RolesClaim string
+	// RoleScopes maps roles to the API key scope that they grant.  Other roles are ignored.
+	RoleScopes map[string]string
 }
 
 type DatabaseConfig struct {
@@ -51,6 +136,77 @@ func mustGetenvAtoi(key string) int {
 	return value
 }
 
+func getenvDuration(key string, def time.Duration) time.Duration {
+	valueStr, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		panic(fmt.Errorf("%w: %q", ErrPanicEnvNotDuration, key))
+	}
+	return value
+}
+
+func getenvInt64(key string, def int64) int64 {
+	valueStr, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	value, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil {
+		panic(fmt.Errorf("%w: %q", ErrPanicEnvNotInt, key))
+	}
+	return value
+}
+
+func getenvBool(key string, def bool) bool {
+	valueStr, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		panic(fmt.Errorf("%w: %q", ErrPanicEnvNotBool, key))
+	}
+	return value
+}
+
+// getenvRoleScopes parses a comma-separated list of role=scope pairs.
+func getenvRoleScopes(key string, def string) map[string]string {
+	valueStr, ok := os.LookupEnv(key)
+	if !ok {
+		valueStr = def
+	}
+	result := map[string]string{}
+	for pair := range strings.SplitSeq(valueStr, ",") {
+		role, scope, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if _, known := scopeRanks[scope]; !ok || role == "" || !known {
+			panic(fmt.Errorf("%w: %q", ErrPanicEnvNotRoles, key))
+		}
+		result[role] = scope
+	}
+	return result
+}
+
+func newOIDCConfigFromEnv() *OIDCConfig {
+	issuer, ok := os.LookupEnv(EnvOIDCIssuer)
+	if !ok || issuer == "" {
+		return nil
+	}
+	rolesClaim := os.Getenv(EnvOIDCRolesClaim)
+	if rolesClaim == "" {
+		rolesClaim = DefaultOIDCRolesClaim
+	}
+	return &OIDCConfig{
+		Issuer:     issuer,
+		Audience:   os.Getenv(EnvOIDCAudience),
+		JWKS:       os.Getenv(EnvOIDCJWKS),
+		RolesClaim: rolesClaim,
+		RoleScopes: getenvRoleScopes(EnvOIDCRoleScopes, DefaultOIDCRoleScopes),
+	}
+}
+
 func NewConfigFromEnv() *Config {
 	return &Config{
 		ServerPort: mustGetenvAtoi(EnvServerPort),
@@ -61,5 +217,14 @@ func NewConfigFromEnv() *Config {
 			Password: mustGetenv(EnvDatabasePassword),
 			Name:     mustGetenv(EnvDatabaseName),
 		},
+		TrashRetention:    getenvDuration(EnvTrashRetention, DefaultTrashRetention),
+		ValidateResponses: getenvBool(EnvValidateResponses, false),
+		BootstrapAPIKey:   os.Getenv(EnvBootstrapAPIKey),
+		OIDC:              newOIDCConfigFromEnv(),
+		ReadTimeout:       getenvDuration(EnvReadTimeout, DefaultReadTimeout),
+		WriteTimeout:      getenvDuration(EnvWriteTimeout, DefaultWriteTimeout),
+		IdleTimeout:       getenvDuration(EnvIdleTimeout, DefaultIdleTimeout),
+		ShutdownTimeout:   getenvDuration(EnvShutdownTimeout, DefaultShutdownTimeout),
+		MaxBodyBytes:      getenvInt64(EnvMaxBodyBytes, DefaultMaxBodyBytes),
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -23,6 +24,12 @@ const (
 // ErrUpsertType is returned when an upsert fails because the entity exists with a different type/kind.
 var ErrUpsertType = errors.New("entity exists with different type")
 
+// ErrNotFound is returned when the entity being read or modified does not exist.
+var ErrNotFound = errors.New("entity not found")
+
+// ErrMissingReference is returned when a write fails because it references an entity that does not exist.
+var ErrMissingReference = errors.New("referenced entity does not exist")
+
 // Querier is an interface that can execute QueryRow, implemented by both pgxpool.Pool and pgx.Tx.
 type Querier interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
@@ -33,60 +40,300 @@ type Execer interface {
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 }
 
+// DB is the database handle used to serve requests, implemented by both pgxpool.Pool and pgx.Tx.
+// Begin on a pgx.Tx starts a savepoint, so code written against DB can also run inside an enclosing transaction.
+type DB interface {
+	Querier
+	Execer
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 // UpsertEntity performs an INSERT ON CONFLICT DO UPDATE for an entity table (works, sources, plans).
 // Returns UpsertCreated if a new row was created, UpsertUpdated if an existing row was updated,
-// or ErrUpsertType if the row exists with a different kind.
+// along with the new version of the row.  Returns ErrUpsertType if the row exists with a different kind,
+// ErrLibraryConflict if the row exists in a different library than the one ctx is scoped to,
+// ErrPreconditionFailed if the row does not satisfy the given preconditions, or ErrMissingReference if
+// the body references a parent that does not exist in the library.
+// Upserting a soft-deleted row restores it, since the caller has supplied its complete new state.
+// The change is recorded in the entity history as part of the same statement.
 // The kind parameter accepts any type that can be passed to pgx (e.g., WorkKind, SourceKind, PlanKind).
 // TODO: change this to take a generic type based on ~string.
-func UpsertEntity(ctx context.Context, q Querier, table string, id uuid.UUID, kind any, body json.RawMessage) (UpsertResult, error) {
+func UpsertEntity(ctx context.Context, q Querier, table string, id uuid.UUID, kind any, body json.RawMessage, pre Preconditions) (UpsertResult, int64, error) {
+	library := writeLibrary(ctx)
+	existed := true
+	if pre.IfMatch != nil || pre.IfNoneMatch != nil {
+		// Deleted rows are treated as missing, because they have no current representation.  So are rows in
+		// other libraries, which the upsert below rejects.
+		var version int64
+		var deleted bool
+		query := fmt.Sprintf(`SELECT version, deleted_at IS NOT NULL FROM %s WHERE uuid = $1 AND library_uuid = $2 FOR UPDATE`, table)
+		err := q.QueryRow(ctx, query, id, library).Scan(&version, &deleted)
+		var current *int64
+		if errors.Is(err, pgx.ErrNoRows) {
+			existed = false
+		} else if err != nil {
+			return UpsertUpdated, 0, fmt.Errorf("failed to query %s: %w", table, err)
+		} else if !deleted {
+			current = &version
+		}
+		if err := pre.Check(current); err != nil {
+			return UpsertUpdated, 0, err
+		}
+	}
+
 	query := fmt.Sprintf(`
-		INSERT INTO %s (uuid, kind, body)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (uuid) DO UPDATE
-		SET body = EXCLUDED.body
-		WHERE %s.kind = $2
-		RETURNING xmax`, table, table)
+		WITH old AS (
+			SELECT body FROM %[1]s WHERE uuid = $1 FOR UPDATE
+		), upserted AS (
+			INSERT INTO %[1]s (uuid, kind, body, library_uuid)
+			VALUES ($1, $2, $3, $9)
+			ON CONFLICT (uuid) DO UPDATE
+			SET body = EXCLUDED.body, deleted_at = NULL
+			WHERE %[1]s.kind = $2 AND %[1]s.library_uuid = $9
+			RETURNING xmax, version
+		), history AS (
+			INSERT INTO entity_history (entity_type, entity_uuid, kind, operation, old_body, new_body, actor, request_id, on_behalf_of)
+			SELECT $4, $1, $2, CASE WHEN upserted.xmax = '0'::xid THEN $5 ELSE $6 END, (SELECT body FROM old), $3, $7, $8, $10
+			FROM upserted
+		)
+		SELECT xmax, version FROM upserted`, table)
 
+	audit := AuditFromContext(ctx)
 	var xmax uint32
-	err := q.QueryRow(ctx, query, id, kind, body).Scan(&xmax)
+	var version int64
+	err := q.QueryRow(ctx, query, id, kind, body, entityType(table), HistoryCreate, HistoryUpdate,
+		nullIfEmpty(audit.Actor), nullIfEmpty(audit.RequestID), library, nullIfEmpty(audit.OnBehalfOf)).Scan(&xmax, &version)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return UpsertUpdated, ErrUpsertType
+		// The row exists, but could not be updated.
+		var sameLibrary bool
+		query := fmt.Sprintf(`SELECT library_uuid = $2 FROM %s WHERE uuid = $1`, table)
+		if err := q.QueryRow(ctx, query, id, library).Scan(&sameLibrary); err != nil {
+			return UpsertUpdated, 0, fmt.Errorf("failed to query %s: %w", table, err)
+		} else if !sameLibrary {
+			return UpsertUpdated, 0, ErrLibraryConflict
+		}
+		return UpsertUpdated, 0, ErrUpsertType
+	} else if isForeignKeyViolation(err) {
+		return UpsertUpdated, 0, fmt.Errorf("%w: %v", ErrMissingReference, err)
 	} else if err != nil {
-		return UpsertUpdated, fmt.Errorf("failed to upsert %s: %w", table, err)
+		return UpsertUpdated, 0, fmt.Errorf("failed to upsert %s: %w", table, err)
 	}
 
 	if xmax == 0 {
-		return UpsertCreated, nil
+		return UpsertCreated, version, nil
+	}
+	if !existed && pre.IfNoneMatch != nil {
+		// The row was created concurrently after the precondition was checked.
+		return UpsertUpdated, 0, ErrPreconditionFailed
+	}
+	return UpsertUpdated, version, nil
+}
+
+// ErrNotDeleted is returned when restoring an entity that is not in the trash.
+var ErrNotDeleted = errors.New("entity is not deleted")
+
+// SoftDeleteEntity marks a row in an entity table (works, sources, plans) as deleted and records the
+// deletion in the entity history.
+// Returns ErrNotFound if the row does not exist in the library of ctx or is already deleted.
+func SoftDeleteEntity(ctx context.Context, tx pgx.Tx, table string, id uuid.UUID) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET deleted_at = now()
+		WHERE uuid = $1 AND deleted_at IS NULL AND %s
+		RETURNING kind, body`, table, LibraryCondition("library_uuid", 2))
+
+	var kind string
+	var body json.RawMessage
+	err := tx.QueryRow(ctx, query, id, LibraryFromContext(ctx)).Scan(&kind, &body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to delete from %s: %w", table, err)
+	}
+	return RecordHistory(ctx, tx, table, id, kind, HistoryDelete, body, body)
+}
+
+// RestoreEntity clears the deletion mark of a row in an entity table (works, sources, plans) and records
+// the restoration in the entity history.
+// Returns ErrNotFound if the row does not exist in the library of ctx, or ErrNotDeleted if it is not deleted.
+func RestoreEntity(ctx context.Context, tx pgx.Tx, table string, id uuid.UUID) error {
+	query := fmt.Sprintf(`SELECT kind, body, deleted_at IS NOT NULL FROM %s WHERE uuid = $1 AND %s FOR UPDATE`,
+		table, LibraryCondition("library_uuid", 2))
+
+	var kind string
+	var body json.RawMessage
+	var deleted bool
+	err := tx.QueryRow(ctx, query, id, LibraryFromContext(ctx)).Scan(&kind, &body, &deleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to query %s: %w", table, err)
+	} else if !deleted {
+		return ErrNotDeleted
+	}
+
+	query = fmt.Sprintf(`UPDATE %s SET deleted_at = NULL WHERE uuid = $1`, table)
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", table, err)
+	}
+	return RecordHistory(ctx, tx, table, id, kind, HistoryRestore, body, body)
+}
+
+// PurgeDeleted permanently removes works, sources and plans that were deleted before the given time,
+// returning the number of rows removed.  Deleted works and sources that still have live children, or that
+// live plans still output or take as input, are kept, because removing them would cascade to the children
+// and to the links of the plans.
+func PurgeDeleted(ctx context.Context, tx pgx.Tx, before time.Time) (int64, error) {
+	var purged int64
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM plans
+		WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge plans: %w", err)
+	}
+	purged += tag.RowsAffected()
+
+	// Each table is checked against the table that links plans to its rows, and the column of the link
+	// that names the row.
+	for _, purge := range []struct{ table, links, column string }{
+		{"works", "plan_outputs", "work_uuid"},
+		{"sources", "plan_inputs", "source_uuid"},
+	} {
+		table := purge.table
+		query := fmt.Sprintf(`
+			DELETE FROM %[1]s t
+			WHERE t.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM %[1]s c WHERE c.parent_uuid = t.uuid AND c.deleted_at IS NULL)
+			AND NOT EXISTS (
+				SELECT 1 FROM %[2]s l
+				INNER JOIN plans p ON p.uuid = l.plan_uuid
+				WHERE l.%[3]s = t.uuid AND p.deleted_at IS NULL)`, table, purge.links, purge.column)
+		tag, err := tx.Exec(ctx, query, before)
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", table, err)
+		}
+		purged += tag.RowsAffected()
 	}
-	return UpsertUpdated, nil
+	return purged, nil
 }
 
 // UpdatePlanInputs replaces the plan_inputs entries for a plan with a single source UUID.
+// Returns ErrMissingReference if the source does not exist in the library of the plan.
 func UpdatePlanInputs(ctx context.Context, tx pgx.Tx, planUUID, sourceUUID uuid.UUID) error {
 	_, err := tx.Exec(ctx, `DELETE FROM plan_inputs WHERE plan_uuid = $1`, planUUID)
 	if err != nil {
 		return fmt.Errorf("failed to delete old plan_inputs: %w", err)
 	}
-	_, err = tx.Exec(ctx, `INSERT INTO plan_inputs (plan_uuid, source_uuid) VALUES ($1, $2)`, planUUID, sourceUUID)
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO plan_inputs (plan_uuid, source_uuid)
+		SELECT p.uuid, s.uuid
+		FROM plans p
+		INNER JOIN sources s ON s.library_uuid = p.library_uuid
+		WHERE p.uuid = $1 AND s.uuid = $2`, planUUID, sourceUUID)
 	if err != nil {
 		return fmt.Errorf("failed to insert plan_inputs: %w", err)
+	} else if tag.RowsAffected() == 0 {
+		return NewFieldError("sourceUuid", fmt.Errorf("%w: source %s is not in the library of the plan", ErrMissingReference, sourceUUID))
 	}
 	return nil
 }
 
 // UpdatePlanOutputs replaces the plan_outputs entries for a plan with a single work UUID.
+// Returns ErrMissingReference if the work does not exist in the library of the plan.
 func UpdatePlanOutputs(ctx context.Context, tx pgx.Tx, planUUID, workUUID uuid.UUID) error {
 	_, err := tx.Exec(ctx, `DELETE FROM plan_outputs WHERE plan_uuid = $1`, planUUID)
 	if err != nil {
 		return fmt.Errorf("failed to delete old plan_outputs: %w", err)
 	}
-	_, err = tx.Exec(ctx, `INSERT INTO plan_outputs (plan_uuid, work_uuid) VALUES ($1, $2)`, planUUID, workUUID)
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO plan_outputs (plan_uuid, work_uuid)
+		SELECT p.uuid, w.uuid
+		FROM plans p
+		INNER JOIN works w ON w.library_uuid = p.library_uuid
+		WHERE p.uuid = $1 AND w.uuid = $2`, planUUID, workUUID)
 	if err != nil {
 		return fmt.Errorf("failed to insert plan_outputs: %w", err)
+	} else if tag.RowsAffected() == 0 {
+		return NewFieldError("workUuid", fmt.Errorf("%w: work %s is not in the library of the plan", ErrMissingReference, workUUID))
+	}
+	return nil
+}
+
+// SyncPlanLinks updates the plan_inputs and plan_outputs entries for a plan to match the source and
+// work referenced by its body.  Every plan kind stores these under the same keys.
+// Returns ErrMissingReference if the source or work no longer exists in the library of the plan.
+func SyncPlanLinks(ctx context.Context, tx pgx.Tx, planUUID uuid.UUID, body json.RawMessage) error {
+	var links struct {
+		SourceUUID uuid.UUID `json:"sourceUuid"`
+		WorkUUID   uuid.UUID `json:"workUuid"`
+	}
+	if err := json.Unmarshal(body, &links); err != nil {
+		return fmt.Errorf("failed to unmarshal plan body: %w", err)
+	}
+	err := UpdatePlanInputs(ctx, tx, planUUID, links.SourceUUID)
+	if err == nil {
+		err = UpdatePlanOutputs(ctx, tx, planUUID, links.WorkUUID)
+	}
+	if isForeignKeyViolation(err) {
+		return fmt.Errorf("%w: %v", ErrMissingReference, err)
+	}
+	return err
+}
+
+// CheckParent checks that a row in an entity table (works, sources) can be the parent of the row with
+// the given UUID: it must exist outside the trash in the library of ctx, be of the given kind, and not be
+// the row itself.  Returns ErrMissingReference otherwise.
+func CheckParent(ctx context.Context, tx pgx.Tx, table string, id, parentID uuid.UUID, kind any) error {
+	if parentID == id {
+		return fmt.Errorf("%w: %s cannot be its own parent", ErrMissingReference, entityType(table))
+	}
+	var matches bool
+	query := fmt.Sprintf(`SELECT kind = $2 FROM %s WHERE uuid = $1 AND library_uuid = $3 AND deleted_at IS NULL FOR SHARE`, table)
+	err := tx.QueryRow(ctx, query, parentID, kind, writeLibrary(ctx)).Scan(&matches)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %s %s", ErrMissingReference, entityType(table), parentID)
+	} else if err != nil {
+		return fmt.Errorf("failed to query %s: %w", table, err)
+	} else if !matches {
+		return fmt.Errorf("%w: %s %s is not a %s", ErrMissingReference, entityType(table), parentID, kind)
 	}
 	return nil
 }
 
+// ReplaceWorkCredits replaces the credits entries for a work.
+// Returns ErrMissingReference if any of the credited persons do not exist.
+func ReplaceWorkCredits(ctx context.Context, tx pgx.Tx, workUUID uuid.UUID, credits []Credit) error {
+	_, err := tx.Exec(ctx, `DELETE FROM credits WHERE work_uuid = $1`, workUUID)
+	if err != nil {
+		return fmt.Errorf("failed to delete old credits: %w", err)
+	}
+	for i, c := range credits {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO credits (work_uuid, person_uuid, role, character_name, billing_order)
+			VALUES ($1, $2, $3, $4, $5)`,
+			workUUID, c.PersonUUID, c.Role, c.CharacterName, c.BillingOrder)
+		if isForeignKeyViolation(err) {
+			return NewFieldError(fmt.Sprintf("credits[%d].personUuid", i), fmt.Errorf("%w: person %s", ErrMissingReference, c.PersonUUID))
+		} else if err != nil {
+			return fmt.Errorf("failed to insert credits: %w", err)
+		}
+	}
+	return nil
+}
+
+// isForeignKeyViolation reports whether err was caused by a foreign key constraint.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// isUniqueViolation reports whether err was caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
 // NewDBPool creates a new pgxpool.Pool from the given DatabaseConfig.
 func NewDBPool(ctx context.Context, cfg *DatabaseConfig) (*pgxpool.Pool, error) {
 	connString := fmt.Sprintf(
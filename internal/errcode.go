@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Codes for the kind of error in an API error response.  These are part of the API, so existing
+// codes must not be renamed.
+const (
+	CodeInvalidRequest       = "INVALID_REQUEST"
+	CodeInvalidField         = "INVALID_FIELD"
+	CodeInvalidPageToken     = "INVALID_PAGE_TOKEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeKindConflict         = "KIND_CONFLICT"
+	CodeLibraryConflict      = "LIBRARY_CONFLICT"
+	CodeNotDeleted           = "NOT_DELETED"
+	CodeReferenceMissing     = "REFERENCE_MISSING"
+	CodePreconditionFailed   = "PRECONDITION_FAILED"
+	CodePatchConflict        = "PATCH_CONFLICT"
+	CodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	CodeUnauthenticated      = "UNAUTHENTICATED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeRequestTooLarge      = "REQUEST_TOO_LARGE"
+	CodeInternal             = "INTERNAL"
+)
+
+// Codes for what is wrong with a single field in the details of an API error response.
+// CodeReferenceMissing is also used for fields that reference an entity that does not exist.
+const (
+	CodeRequired     = "REQUIRED"
+	CodeNull         = "NULL"
+	CodeEmpty        = "EMPTY"
+	CodeInvalidUUID  = "INVALID_UUID"
+	CodeInvalidValue = "INVALID_VALUE"
+	CodeDuplicate    = "DUPLICATE"
+	CodeWrongType    = "WRONG_TYPE"
+	CodeUnknownField = "UNKNOWN_FIELD"
+)
+
+// ErrorCode returns the code for an error returned by this package, or CodeInternal if err is not one
+// of its sentinel errors.
+func ErrorCode(err error) string {
+	var fieldErr *FieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		return CodeInvalidField
+	case errors.Is(err, ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, ErrUpsertType):
+		return CodeKindConflict
+	case errors.Is(err, ErrLibraryConflict):
+		return CodeLibraryConflict
+	case errors.Is(err, ErrNotDeleted):
+		return CodeNotDeleted
+	case errors.Is(err, ErrMissingReference):
+		return CodeReferenceMissing
+	case errors.Is(err, ErrPreconditionFailed):
+		return CodePreconditionFailed
+	case errors.Is(err, ErrPatchConflict):
+		return CodePatchConflict
+	case errors.Is(err, ErrIdempotencyKeyReused):
+		return CodeIdempotencyKeyReused
+	case errors.Is(err, ErrUnauthenticated):
+		return CodeUnauthenticated
+	default:
+		return CodeInternal
+	}
+}
+
+// FieldError is an error caused by the value of one field of a request.
+type FieldError struct {
+	// Field is the path of the field within the request body, such as "alternateTitles[0].title",
+	// or the name of a path or query parameter.
+	Field string
+	Err   error
+}
+
+// NewFieldError returns a FieldError for err, or nil if err is nil.
+func NewFieldError(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &FieldError{Field: field, Err: err}
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the code for what is wrong with the field.
+func (e *FieldError) Code() string {
+	return FieldErrorCode(e.Err)
+}
+
+// FieldErrors returns every FieldError in the tree of err, including those combined with errors.Join.
+func FieldErrors(err error) []*FieldError {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *FieldError:
+		return []*FieldError{e}
+	case interface{ Unwrap() []error }:
+		var result []*FieldError
+		for _, inner := range e.Unwrap() {
+			result = append(result, FieldErrors(inner)...)
+		}
+		return result
+	default:
+		return FieldErrors(errors.Unwrap(err))
+	}
+}
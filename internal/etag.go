@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrPreconditionFailed is returned when a write is refused because of an If-Match or If-None-Match header.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// ETag returns the entity tag for the given version of an entity.
+func ETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// Preconditions holds the conditional request headers of a write.
+type Preconditions struct {
+	IfMatch     *string
+	IfNoneMatch *string
+}
+
+// Check returns ErrPreconditionFailed unless the write may go ahead, given the current version of
+// the entity or nil if it does not exist.
+func (p Preconditions) Check(current *int64) error {
+	if p.IfMatch != nil {
+		// If-Match uses strong comparison, so weak tags never match.
+		if current == nil || !etagListMatches(*p.IfMatch, ETag(*current), false) {
+			return ErrPreconditionFailed
+		}
+	}
+	if p.IfNoneMatch != nil {
+		// If-None-Match uses weak comparison.
+		if current != nil && etagListMatches(*p.IfNoneMatch, ETag(*current), true) {
+			return ErrPreconditionFailed
+		}
+	}
+	return nil
+}
+
+// etagListMatches reports whether the comma separated list of entity tags in header matches etag.
+func etagListMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// Relations that can be named in the expand parameter of a request.
+const (
+	RelationParent   = "parent"
+	RelationChildren = "children"
+	RelationSource   = "source"
+	RelationWork     = "work"
+)
+
+// ParseExpand parses the comma separated expand parameter of a request, which may only name the given
+// relations.  The result holds the relations to expand.
+func ParseExpand(param *string, allowed ...string) (map[string]bool, error) {
+	expand := map[string]bool{}
+	if param == nil {
+		return expand, nil
+	}
+	for _, name := range strings.Split(*param, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(allowed, name) {
+			return nil, NewFieldError("expand", fmt.Errorf("cannot expand %q, expected one of %s", name, strings.Join(allowed, ", ")))
+		}
+		expand[name] = true
+	}
+	return expand, nil
+}
+
+// entityRow is a row from an entity table (works, sources) read for expansion.
+type entityRow struct {
+	ID   uuid.UUID
+	Kind string
+	Body json.RawMessage
+}
+
+// loadEntities reads the rows of an entity table (works, sources) outside the trash and in the library of
+// ctx whose column is one of ids, in a single query.
+func loadEntities(ctx context.Context, tx pgx.Tx, table, column string, ids []uuid.UUID) ([]entityRow, error) {
+	query := fmt.Sprintf(`
+		SELECT uuid, kind, body
+		FROM %s
+		WHERE %s = ANY($1) AND deleted_at IS NULL AND %s
+		ORDER BY uuid`, table, column, LibraryCondition("library_uuid", 2))
+	rows, err := tx.Query(ctx, query, ids, LibraryFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+
+	var result []entityRow
+	var row entityRow
+	_, err = pgx.ForEachRow(rows, []any{&row.ID, &row.Kind, &row.Body}, func() error {
+		result = append(result, row)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", table, err)
+	}
+	return result, nil
+}
+
+// loadWorks returns the API representations of the works whose column is one of ids.
+func loadWorks(ctx context.Context, tx pgx.Tx, column string, ids []uuid.UUID) ([]*vcrest.Work, error) {
+	rows, err := loadEntities(ctx, tx, "works", column, ids)
+	if err != nil {
+		return nil, err
+	}
+	return worksFromRows(rows)
+}
+
+// worksFromRows converts rows of the works table to their API representations.
+func worksFromRows(rows []entityRow) ([]*vcrest.Work, error) {
+	works := make([]*vcrest.Work, 0, len(rows))
+	for _, row := range rows {
+		work, err := WorkToAPI(row.ID, WorkKind(row.Kind), row.Body)
+		if err != nil {
+			return nil, err
+		}
+		works = append(works, work)
+	}
+	return works, nil
+}
+
+// loadSources returns the API representations of the sources whose column is one of ids.
+func loadSources(ctx context.Context, tx pgx.Tx, column string, ids []uuid.UUID) ([]*vcrest.Source, error) {
+	rows, err := loadEntities(ctx, tx, "sources", column, ids)
+	if err != nil {
+		return nil, err
+	}
+	return sourcesFromRows(rows)
+}
+
+// sourcesFromRows converts rows of the sources table to their API representations.
+func sourcesFromRows(rows []entityRow) ([]*vcrest.Source, error) {
+	sources := make([]*vcrest.Source, 0, len(rows))
+	for _, row := range rows {
+		source, err := SourceToAPI(row.ID, SourceKind(row.Kind), row.Body)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// ExpandWork embeds the relations of a work named in expand.  parentID is the parent_uuid of the work.
+func ExpandWork(ctx context.Context, tx pgx.Tx, work *vcrest.Work, parentID *uuid.UUID, expand map[string]bool) error {
+	if expand[RelationParent] && parentID != nil {
+		parents, err := loadWorks(ctx, tx, "uuid", []uuid.UUID{*parentID})
+		if err != nil {
+			return err
+		}
+		if len(parents) > 0 {
+			work.Parent = parents[0]
+		}
+	}
+	if expand[RelationChildren] {
+		children, err := loadWorks(ctx, tx, "parent_uuid", []uuid.UUID{uuid.UUID(work.Uuid)})
+		if err != nil {
+			return err
+		}
+		work.Children = make([]vcrest.Work, 0, len(children))
+		for _, child := range children {
+			work.Children = append(work.Children, *child)
+		}
+	}
+	return nil
+}
+
+// ExpandSource embeds the relations of a source named in expand.  parentID is the parent_uuid of the source.
+func ExpandSource(ctx context.Context, tx pgx.Tx, source *vcrest.Source, parentID *uuid.UUID, expand map[string]bool) error {
+	if expand[RelationParent] && parentID != nil {
+		parents, err := loadSources(ctx, tx, "uuid", []uuid.UUID{*parentID})
+		if err != nil {
+			return err
+		}
+		if len(parents) > 0 {
+			source.Parent = parents[0]
+		}
+	}
+	if expand[RelationChildren] {
+		children, err := loadSources(ctx, tx, "parent_uuid", []uuid.UUID{uuid.UUID(source.Uuid)})
+		if err != nil {
+			return err
+		}
+		source.Children = make([]vcrest.Source, 0, len(children))
+		for _, child := range children {
+			source.Children = append(source.Children, *child)
+		}
+	}
+	return nil
+}
+
+// planLinks returns the source and work referenced by a plan, if any.
+func planLinks(plan *vcrest.Plan) (*uuid.UUID, *uuid.UUID) {
+	switch {
+	case plan.Direct != nil:
+		return FieldMayUUID(plan.Direct.SourceUuid), FieldMayUUID(plan.Direct.WorkUuid)
+	case plan.ChapterRange != nil:
+		return FieldMayUUID(plan.ChapterRange.SourceUuid), FieldMayUUID(plan.ChapterRange.WorkUuid)
+	default:
+		return nil, nil
+	}
+}
+
+// ExpandPlans embeds the relations named in expand into each of the plans.  The referenced entities of
+// all the plans are read with one query per entity type.
+func ExpandPlans(ctx context.Context, tx pgx.Tx, plans []*vcrest.Plan, expand map[string]bool) error {
+	var sourceIDs, workIDs []uuid.UUID
+	for _, plan := range plans {
+		sourceID, workID := planLinks(plan)
+		if sourceID != nil {
+			sourceIDs = append(sourceIDs, *sourceID)
+		}
+		if workID != nil {
+			workIDs = append(workIDs, *workID)
+		}
+	}
+
+	sources := map[uuid.UUID]*vcrest.Source{}
+	if expand[RelationSource] && len(sourceIDs) > 0 {
+		loaded, err := loadSources(ctx, tx, "uuid", sourceIDs)
+		if err != nil {
+			return err
+		}
+		for _, source := range loaded {
+			sources[uuid.UUID(source.Uuid)] = source
+		}
+	}
+	works := map[uuid.UUID]*vcrest.Work{}
+	if expand[RelationWork] && len(workIDs) > 0 {
+		loaded, err := loadWorks(ctx, tx, "uuid", workIDs)
+		if err != nil {
+			return err
+		}
+		for _, work := range loaded {
+			works[uuid.UUID(work.Uuid)] = work
+		}
+	}
+
+	for _, plan := range plans {
+		sourceID, workID := planLinks(plan)
+		if sourceID != nil {
+			plan.Source = sources[*sourceID]
+		}
+		if workID != nil {
+			plan.Work = works[*workID]
+		}
+	}
+	return nil
+}
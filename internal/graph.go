@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// Kinds of edges in the catalog graph.  Edges point in the direction that media flows: from a parent to
+// its child, from a source into the plan that uses it, and from a plan to the work it produces.
+const (
+	GraphEdgeParent = "parent"
+	GraphEdgeInput  = "input"
+	GraphEdgeOutput = "output"
+)
+
+// GraphEdge is a link between two entities in the catalog graph.
+type GraphEdge struct {
+	From uuid.UUID
+	To   uuid.UUID
+	Kind string
+}
+
+// Graph is the part of the catalog graph within some distance of a root entity.
+type Graph struct {
+	Depths    map[uuid.UUID]int // Distance of each entity from the root.
+	Works     []*vcrest.Work
+	Sources   []*vcrest.Source
+	Plans     []*vcrest.Plan
+	Edges     []GraphEdge
+	Truncated bool // Set if entities within the requested distance were left out to respect the size limit.
+}
+
+// LoadGraph returns the entities within depth edges of the root entity, which may be a work, source or
+// plan, and the edges between them.  At most maxNodes entities are returned.  Entities in the trash are
+// left out, along with their edges.  Returns ErrNotFound if the root does not exist in the library of ctx
+// or is in the trash.  References never cross libraries, so neither does the graph.
+func LoadGraph(ctx context.Context, tx pgx.Tx, root uuid.UUID, depth, maxNodes int) (*Graph, error) {
+	graph := &Graph{
+		Depths: map[uuid.UUID]int{root: 0},
+	}
+	seenEdges := map[GraphEdge]bool{}
+	frontier := []uuid.UUID{root}
+	for level := 0; len(frontier) > 0; level++ {
+		edges, err := graphEdges(ctx, tx, frontier)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []uuid.UUID
+		for _, edge := range edges {
+			if seenEdges[edge] {
+				continue
+			}
+			// Entities past the requested depth are not added, but edges between known entities always are.
+			include := true
+			for _, id := range []uuid.UUID{edge.From, edge.To} {
+				if _, ok := graph.Depths[id]; ok {
+					continue
+				}
+				if level >= depth {
+					include = false
+				} else if len(graph.Depths) >= maxNodes {
+					include = false
+					graph.Truncated = true
+				} else {
+					graph.Depths[id] = level + 1
+					next = append(next, id)
+				}
+			}
+			if include {
+				seenEdges[edge] = true
+				graph.Edges = append(graph.Edges, edge)
+			}
+		}
+		if level >= depth {
+			break
+		}
+		frontier = next
+	}
+
+	ids := make([]uuid.UUID, 0, len(graph.Depths))
+	for id := range graph.Depths {
+		ids = append(ids, id)
+	}
+	var err error
+	if graph.Works, err = loadWorks(ctx, tx, "uuid", ids); err != nil {
+		return nil, err
+	}
+	if graph.Sources, err = loadSources(ctx, tx, "uuid", ids); err != nil {
+		return nil, err
+	}
+	if graph.Plans, err = loadPlans(ctx, tx, "uuid", ids); err != nil {
+		return nil, err
+	}
+	if len(graph.Works)+len(graph.Sources)+len(graph.Plans) == 0 {
+		return nil, ErrNotFound
+	}
+
+	slices.SortFunc(graph.Edges, func(a, b GraphEdge) int {
+		return cmp.Or(
+			slices.Compare(a.From[:], b.From[:]),
+			slices.Compare(a.To[:], b.To[:]),
+			cmp.Compare(a.Kind, b.Kind),
+		)
+	})
+	return graph, nil
+}
+
+// graphEdges returns the edges between live entities that touch any of the given entities.
+func graphEdges(ctx context.Context, tx pgx.Tx, ids []uuid.UUID) ([]GraphEdge, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT p.uuid, c.uuid, $2::text
+		FROM works c
+		INNER JOIN works p ON p.uuid = c.parent_uuid
+		WHERE (c.uuid = ANY($1) OR c.parent_uuid = ANY($1))
+			AND c.deleted_at IS NULL AND p.deleted_at IS NULL
+		UNION ALL
+		SELECT p.uuid, c.uuid, $2::text
+		FROM sources c
+		INNER JOIN sources p ON p.uuid = c.parent_uuid
+		WHERE (c.uuid = ANY($1) OR c.parent_uuid = ANY($1))
+			AND c.deleted_at IS NULL AND p.deleted_at IS NULL
+		UNION ALL
+		SELECT pi.source_uuid, pi.plan_uuid, $3::text
+		FROM plan_inputs pi
+		INNER JOIN sources s ON s.uuid = pi.source_uuid
+		INNER JOIN plans p ON p.uuid = pi.plan_uuid
+		WHERE (pi.source_uuid = ANY($1) OR pi.plan_uuid = ANY($1))
+			AND s.deleted_at IS NULL AND p.deleted_at IS NULL
+		UNION ALL
+		SELECT po.plan_uuid, po.work_uuid, $4::text
+		FROM plan_outputs po
+		INNER JOIN plans p ON p.uuid = po.plan_uuid
+		INNER JOIN works w ON w.uuid = po.work_uuid
+		WHERE (po.plan_uuid = ANY($1) OR po.work_uuid = ANY($1))
+			AND p.deleted_at IS NULL AND w.deleted_at IS NULL`,
+		ids, GraphEdgeParent, GraphEdgeInput, GraphEdgeOutput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query graph edges: %w", err)
+	}
+
+	var edges []GraphEdge
+	var edge GraphEdge
+	_, err = pgx.ForEachRow(rows, []any{&edge.From, &edge.To, &edge.Kind}, func() error {
+		edges = append(edges, edge)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan graph edges: %w", err)
+	}
+	return edges, nil
+}
+
+// loadPlans returns the API representations of the plans outside the trash and in the library of ctx whose
+// column is one of ids.
+func loadPlans(ctx context.Context, tx pgx.Tx, column string, ids []uuid.UUID) ([]*vcrest.Plan, error) {
+	query := fmt.Sprintf(`
+		SELECT uuid, kind, body, completed_at
+		FROM plans
+		WHERE %s = ANY($1) AND deleted_at IS NULL AND %s
+		ORDER BY uuid`, column, LibraryCondition("library_uuid", 2))
+	rows, err := tx.Query(ctx, query, ids, LibraryFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query plans: %w", err)
+	}
+
+	var plans []*vcrest.Plan
+	var row entityRow
+	var completedAt *time.Time
+	_, err = pgx.ForEachRow(rows, []any{&row.ID, &row.Kind, &row.Body, &completedAt}, func() error {
+		plan, err := PlanToAPI(row.ID, PlanKind(row.Kind), row.Body)
+		if err != nil {
+			return err
+		}
+		plan.CompletedAt = completedAt
+		plans = append(plans, plan)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan plans: %w", err)
+	}
+	return plans, nil
+}
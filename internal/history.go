@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// HistoryOp is the kind of change recorded in the history of an entity.
+type HistoryOp string
+
+const (
+	HistoryCreate  HistoryOp = "create"
+	HistoryUpdate  HistoryOp = "update"
+	HistoryDelete  HistoryOp = "delete"
+	HistoryRestore HistoryOp = "restore"
+	HistoryRevert  HistoryOp = "revert"
+)
+
+// Audit identifies who made a change and as part of which request.
+type Audit struct {
+	// Actor is the authenticated caller that made the change.
+	Actor     string
+	RequestID string
+	// OnBehalfOf is who the caller says it made the change for, such as the user of a client that shares
+	// one API key among its users.  It is not verified.
+	OnBehalfOf string
+}
+
+type auditKey struct{}
+
+// WithAudit returns a context carrying the given audit information.
+func WithAudit(ctx context.Context, a Audit) context.Context {
+	return context.WithValue(ctx, auditKey{}, a)
+}
+
+// AuditFromContext returns the audit information carried by ctx, if any.
+func AuditFromContext(ctx context.Context) Audit {
+	a, _ := ctx.Value(auditKey{}).(Audit)
+	return a
+}
+
+// nullIfEmpty returns nil for an empty string, so that it is stored as NULL.
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// entityType returns the entity type recorded in the history for an entity table (works, sources, plans).
+func entityType(table string) string {
+	return strings.TrimSuffix(table, "s")
+}
+
+// HistoryEntry is a recorded change to an entity.
+type HistoryEntry struct {
+	ID         int64
+	Kind       string
+	Operation  HistoryOp
+	OldBody    json.RawMessage // nil when the change created the entity.
+	NewBody    json.RawMessage
+	Actor      *string
+	RequestID  *string
+	OnBehalfOf *string
+	CreatedAt  time.Time
+}
+
+// RecordHistory adds an entry to the history of a row in an entity table (works, sources, plans).
+// The actor, request ID and who the change was made on behalf of are taken from the audit information in ctx.
+func RecordHistory(ctx context.Context, e Execer, table string, id uuid.UUID, kind any, op HistoryOp, oldBody, newBody json.RawMessage) error {
+	audit := AuditFromContext(ctx)
+	_, err := e.Exec(ctx, `
+		INSERT INTO entity_history (entity_type, entity_uuid, kind, operation, old_body, new_body, actor, request_id, on_behalf_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		entityType(table), id, kind, op, oldBody, newBody, nullIfEmpty(audit.Actor), nullIfEmpty(audit.RequestID), nullIfEmpty(audit.OnBehalfOf))
+	if err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	return nil
+}
+
+// ListHistory returns up to limit entries from the history of a row in an entity table, newest first.
+// If beforeID is non-zero, only entries older than the entry with that ID are returned.
+// Returns ErrNotFound if the entity does not exist in the library of ctx, including in the trash.
+func ListHistory(ctx context.Context, tx pgx.Tx, table string, id uuid.UUID, beforeID int64, limit int) ([]HistoryEntry, error) {
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE uuid = $1 AND %s)`, table, LibraryCondition("library_uuid", 2))
+	if err := tx.QueryRow(ctx, query, id, LibraryFromContext(ctx)).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	} else if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, kind, operation, old_body, new_body, actor, request_id, on_behalf_of, created_at
+		FROM entity_history
+		WHERE entity_type = $1 AND entity_uuid = $2 AND ($3::bigint = 0 OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4`,
+		entityType(table), id, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+
+	entries := []HistoryEntry{}
+	var entry HistoryEntry
+	_, err = pgx.ForEachRow(rows, []any{&entry.ID, &entry.Kind, &entry.Operation, &entry.OldBody, &entry.NewBody, &entry.Actor, &entry.RequestID, &entry.OnBehalfOf, &entry.CreatedAt}, func() error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan history: %w", err)
+	}
+	return entries, nil
+}
+
+// RevertEntity replaces the body of a row in an entity table with the body it had after the given
+// history entry, and records the revert in the history.  The entity's kind and new body are returned so
+// that callers can update anything derived from the body.
+// Returns ErrNotFound if the entity is missing from the library of ctx or deleted, or if the history entry
+// does not belong to it, ErrUpsertType if the history entry is for a different kind of entity, and
+// ErrMissingReference if the version references an entity that has since been purged.
+func RevertEntity(ctx context.Context, tx pgx.Tx, table string, id uuid.UUID, historyID int64) (string, json.RawMessage, error) {
+	var kind string
+	var oldBody json.RawMessage
+	query := fmt.Sprintf(`SELECT kind, body FROM %s WHERE uuid = $1 AND deleted_at IS NULL AND %s FOR UPDATE`,
+		table, LibraryCondition("library_uuid", 2))
+	err := tx.QueryRow(ctx, query, id, LibraryFromContext(ctx)).Scan(&kind, &oldBody)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, fmt.Errorf("%w: %s %s", ErrNotFound, entityType(table), id)
+	} else if err != nil {
+		return "", nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+
+	var entryKind string
+	var newBody json.RawMessage
+	err = tx.QueryRow(ctx, `
+		SELECT kind, new_body
+		FROM entity_history
+		WHERE id = $1 AND entity_type = $2 AND entity_uuid = $3`,
+		historyID, entityType(table), id).Scan(&entryKind, &newBody)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, fmt.Errorf("%w: history entry %d", ErrNotFound, historyID)
+	} else if err != nil {
+		return "", nil, fmt.Errorf("failed to query history: %w", err)
+	} else if entryKind != kind {
+		return "", nil, ErrUpsertType
+	}
+
+	query = fmt.Sprintf(`UPDATE %s SET body = $2 WHERE uuid = $1`, table)
+	if _, err := tx.Exec(ctx, query, id, newBody); isForeignKeyViolation(err) {
+		return "", nil, fmt.Errorf("%w: %v", ErrMissingReference, err)
+	} else if err != nil {
+		return "", nil, fmt.Errorf("failed to revert %s: %w", table, err)
+	}
+	if err := RecordHistory(ctx, tx, table, id, kind, HistoryRevert, oldBody, newBody); err != nil {
+		return "", nil, err
+	}
+	return kind, newBody, nil
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// IdempotencyKeyRetention is how long idempotency keys are remembered.
+const IdempotencyKeyRetention = 24 * time.Hour
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is sent again with a different request.
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+// ClaimIdempotencyKey records that the request with the given hash creates the entity newUUID, under the
+// idempotency key that the caller, identified by principalID, sent for the operation.  Keys are scoped to the
+// caller and to the library of ctx, so the same key sent by another caller or to another library is a
+// different key.  Returns newUUID and true if the key was unused.  If the key was
+// already claimed by the same request, the UUID recorded then is returned along with false.
+// Returns ErrIdempotencyKeyReused if the key was claimed by a different request.
+// Concurrent claims of the same key wait for tx to finish, so tx should only be committed once the entity
+// has been created.
+func ClaimIdempotencyKey(ctx context.Context, tx pgx.Tx, principalID, operation, key string, requestHash []byte, newUUID uuid.UUID) (uuid.UUID, bool, error) {
+	library := writeLibrary(ctx)
+	var entityUUID uuid.UUID
+	err := tx.QueryRow(ctx, `
+		INSERT INTO idempotency_keys (library_uuid, principal_id, operation, key, request_hash, entity_uuid)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (library_uuid, principal_id, operation, key) DO NOTHING
+		RETURNING entity_uuid`,
+		library, principalID, operation, key, requestHash, newUUID).Scan(&entityUUID)
+	if err == nil {
+		return entityUUID, true, nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, false, fmt.Errorf("failed to insert idempotency key: %w", err)
+	}
+
+	var existingHash []byte
+	err = tx.QueryRow(ctx, `
+		SELECT request_hash, entity_uuid
+		FROM idempotency_keys
+		WHERE library_uuid = $1 AND principal_id = $2 AND operation = $3 AND key = $4`,
+		library, principalID, operation, key).Scan(&existingHash, &entityUUID)
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to query idempotency key: %w", err)
+	}
+	if !bytes.Equal(existingHash, requestHash) {
+		return uuid.Nil, false, ErrIdempotencyKeyReused
+	}
+	return entityUUID, false, nil
+}
+
+// PurgeIdempotencyKeys removes idempotency keys claimed before the given time, returning the number removed.
+func PurgeIdempotencyKeys(ctx context.Context, e Execer, before time.Time) (int64, error) {
+	tag, err := e.Exec(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,266 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+)
+
+// purgeDeletedInterval is how often the trash is checked for entities past their retention.
+const purgeDeletedInterval = time.Hour
+
+// purgeIdempotencyKeysInterval is how often expired idempotency keys are removed.
+const purgeIdempotencyKeysInterval = time.Hour
+
+// refreshStatsInterval is how often the library statistics are recomputed.
+const refreshStatsInterval = 15 * time.Minute
+
+// dispatchWebhooksInterval is how often new webhook deliveries are handed to delivery jobs.
+const dispatchWebhooksInterval = 5 * time.Second
+
+// purgeWebhookDeliveriesInterval is how often old webhook deliveries are removed from the log.
+const purgeWebhookDeliveriesInterval = time.Hour
+
+// webhookDeliveryRetention is how long finished webhook deliveries are kept in the log.
+const webhookDeliveryRetention = 30 * 24 * time.Hour
+
+// webhookMaxAttempts is how many times a webhook delivery is tried before it is marked as failed.
+const webhookMaxAttempts = 10
+
+// webhookTimeout bounds each attempt to deliver a webhook.
+const webhookTimeout = 10 * time.Second
+
+// PurgeDeletedArgs are the arguments of the job that empties the trash.
+type PurgeDeletedArgs struct{}
+
+func (PurgeDeletedArgs) Kind() string { return "purge_deleted" }
+
+// PurgeDeletedWorker permanently removes entities that have been deleted for longer than Retention.
+type PurgeDeletedWorker struct {
+	river.WorkerDefaults[PurgeDeletedArgs]
+	Pool      *pgxpool.Pool
+	Retention time.Duration
+}
+
+func (w *PurgeDeletedWorker) Work(ctx context.Context, job *river.Job[PurgeDeletedArgs]) error {
+	txn, err := w.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer txn.Rollback(ctx)
+
+	purged, err := PurgeDeleted(ctx, txn, time.Now().Add(-w.Retention))
+	if err != nil {
+		return err
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if purged > 0 {
+		log.Printf("Purged %d deleted entities", purged)
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeysArgs are the arguments of the job that forgets expired idempotency keys.
+type PurgeIdempotencyKeysArgs struct{}
+
+func (PurgeIdempotencyKeysArgs) Kind() string { return "purge_idempotency_keys" }
+
+// PurgeIdempotencyKeysWorker removes idempotency keys older than IdempotencyKeyRetention.
+type PurgeIdempotencyKeysWorker struct {
+	river.WorkerDefaults[PurgeIdempotencyKeysArgs]
+	Pool *pgxpool.Pool
+}
+
+func (w *PurgeIdempotencyKeysWorker) Work(ctx context.Context, job *river.Job[PurgeIdempotencyKeysArgs]) error {
+	_, err := PurgeIdempotencyKeys(ctx, w.Pool, time.Now().Add(-IdempotencyKeyRetention))
+	return err
+}
+
+// RefreshStatsArgs are the arguments of the job that recomputes the library statistics.
+type RefreshStatsArgs struct{}
+
+func (RefreshStatsArgs) Kind() string { return "refresh_stats" }
+
+// RefreshStatsWorker recomputes the library_stats materialized view.
+type RefreshStatsWorker struct {
+	river.WorkerDefaults[RefreshStatsArgs]
+	Pool *pgxpool.Pool
+}
+
+func (w *RefreshStatsWorker) Work(ctx context.Context, job *river.Job[RefreshStatsArgs]) error {
+	return RefreshStats(ctx, w.Pool)
+}
+
+// DispatchWebhooksArgs are the arguments of the job that creates delivery jobs for new webhook deliveries.
+type DispatchWebhooksArgs struct{}
+
+func (DispatchWebhooksArgs) Kind() string { return "dispatch_webhooks" }
+
+// DispatchWebhooksWorker creates a DeliverWebhookArgs job for each webhook delivery queued by the database.
+// The jobs are inserted in the same transaction that claims the deliveries, so each gets exactly one job.
+type DispatchWebhooksWorker struct {
+	river.WorkerDefaults[DispatchWebhooksArgs]
+	Pool *pgxpool.Pool
+}
+
+func (w *DispatchWebhooksWorker) Work(ctx context.Context, job *river.Job[DispatchWebhooksArgs]) error {
+	txn, err := w.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer txn.Rollback(ctx)
+
+	ids, err := claimWebhookDeliveries(ctx, txn, 1000)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	params := make([]river.InsertManyParams, 0, len(ids))
+	for _, id := range ids {
+		params = append(params, river.InsertManyParams{Args: DeliverWebhookArgs{DeliveryID: id}})
+	}
+	if _, err := river.ClientFromContext[pgx.Tx](ctx).InsertManyTx(ctx, txn, params); err != nil {
+		return fmt.Errorf("failed to insert webhook delivery jobs: %w", err)
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeliverWebhookArgs are the arguments of the job that sends one webhook delivery.
+type DeliverWebhookArgs struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+func (DeliverWebhookArgs) Kind() string { return "deliver_webhook" }
+
+func (DeliverWebhookArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{MaxAttempts: webhookMaxAttempts}
+}
+
+// DeliverWebhookWorker posts a webhook delivery and records the outcome in the delivery log.  Failed
+// attempts return an error, so that River retries them with backoff.
+type DeliverWebhookWorker struct {
+	river.WorkerDefaults[DeliverWebhookArgs]
+	Pool   *pgxpool.Pool
+	Client *http.Client
+}
+
+func (w *DeliverWebhookWorker) Work(ctx context.Context, job *river.Job[DeliverWebhookArgs]) error {
+	delivery, err := loadWebhookDelivery(ctx, w.Pool, job.Args.DeliveryID)
+	if errors.Is(err, ErrNotFound) {
+		// The webhook was deleted, so there is nothing to send.
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	statusCode, sendErr := SendWebhook(ctx, w.Client, delivery, time.Now())
+	final := job.Attempt >= job.MaxAttempts
+	if err := recordWebhookAttempt(ctx, w.Pool, delivery.ID, statusCode, sendErr, final); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// PurgeWebhookDeliveriesArgs are the arguments of the job that removes old webhook deliveries from the log.
+type PurgeWebhookDeliveriesArgs struct{}
+
+func (PurgeWebhookDeliveriesArgs) Kind() string { return "purge_webhook_deliveries" }
+
+// PurgeWebhookDeliveriesWorker removes finished webhook deliveries older than webhookDeliveryRetention.
+type PurgeWebhookDeliveriesWorker struct {
+	river.WorkerDefaults[PurgeWebhookDeliveriesArgs]
+	Pool *pgxpool.Pool
+}
+
+func (w *PurgeWebhookDeliveriesWorker) Work(ctx context.Context, job *river.Job[PurgeWebhookDeliveriesArgs]) error {
+	_, err := PurgeWebhookDeliveries(ctx, w.Pool, time.Now().Add(-webhookDeliveryRetention))
+	return err
+}
+
+// NewRiverClient creates a River client running the background jobs of the service.
+// The caller is responsible for starting and stopping it.
+func NewRiverClient(pool *pgxpool.Pool, cfg *Config) (*river.Client[pgx.Tx], error) {
+	workers := river.NewWorkers()
+	river.AddWorker(workers, &PurgeDeletedWorker{
+		Pool:      pool,
+		Retention: cfg.TrashRetention,
+	})
+	river.AddWorker(workers, &PurgeIdempotencyKeysWorker{
+		Pool: pool,
+	})
+	river.AddWorker(workers, &RefreshStatsWorker{
+		Pool: pool,
+	})
+	river.AddWorker(workers, &DispatchWebhooksWorker{
+		Pool: pool,
+	})
+	river.AddWorker(workers, &DeliverWebhookWorker{
+		Pool:   pool,
+		Client: &http.Client{Timeout: webhookTimeout},
+	})
+	river.AddWorker(workers, &PurgeWebhookDeliveriesWorker{
+		Pool: pool,
+	})
+
+	client, err := river.NewClient(riverpgxv5.New(pool), &river.Config{
+		Queues: map[string]river.QueueConfig{
+			river.QueueDefault: {MaxWorkers: 10},
+		},
+		Workers: workers,
+		PeriodicJobs: []*river.PeriodicJob{
+			river.NewPeriodicJob(
+				river.PeriodicInterval(purgeDeletedInterval),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return PurgeDeletedArgs{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true},
+			),
+			river.NewPeriodicJob(
+				river.PeriodicInterval(purgeIdempotencyKeysInterval),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return PurgeIdempotencyKeysArgs{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true},
+			),
+			river.NewPeriodicJob(
+				river.PeriodicInterval(refreshStatsInterval),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return RefreshStatsArgs{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true},
+			),
+			river.NewPeriodicJob(
+				river.PeriodicInterval(dispatchWebhooksInterval),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return DispatchWebhooksArgs{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true},
+			),
+			river.NewPeriodicJob(
+				river.PeriodicInterval(purgeWebhookDeliveriesInterval),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return PurgeWebhookDeliveriesArgs{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true},
+			),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create river client: %w", err)
+	}
+	return client, nil
+}
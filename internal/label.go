@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// LabelTable describes a table that attaches string labels, such as tags or genres, to entities.
+type LabelTable struct {
+	EntityTable  string // Table holding the labelled entities, e.g. "works".
+	Table        string // Table holding the labels, e.g. "work_tags".
+	EntityColumn string // Column of Table referencing the entity, e.g. "work_uuid".
+	LabelColumn  string // Column of Table holding the label, e.g. "tag".
+}
+
+var (
+	WorkTags   = LabelTable{EntityTable: "works", Table: "work_tags", EntityColumn: "work_uuid", LabelColumn: "tag"}
+	SourceTags = LabelTable{EntityTable: "sources", Table: "source_tags", EntityColumn: "source_uuid", LabelColumn: "tag"}
+	WorkGenres = LabelTable{EntityTable: "works", Table: "work_genres", EntityColumn: "work_uuid", LabelColumn: "genre"}
+)
+
+// checkEntity returns ErrNotFound if the labelled entity does not exist in the library of ctx.
+func (t LabelTable) checkEntity(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE uuid = $1 AND deleted_at IS NULL AND %s)`,
+		t.EntityTable, LibraryCondition("library_uuid", 2))
+	if err := tx.QueryRow(ctx, query, id, LibraryFromContext(ctx)).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to query %s: %w", t.EntityTable, err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns the labels of the entity with the given UUID in alphabetical order.
+// Returns ErrNotFound if the entity does not exist.
+func (t LabelTable) List(ctx context.Context, tx pgx.Tx, id uuid.UUID) ([]string, error) {
+	if err := t.checkEntity(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s = $1 ORDER BY %s`, t.LabelColumn, t.Table, t.EntityColumn, t.LabelColumn)
+	rows, err := tx.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", t.Table, err)
+	}
+	labels, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", t.Table, err)
+	}
+	return labels, nil
+}
+
+// Add attaches a label to the entity with the given UUID.
+// Returns true if the label was added, or false if it was already present.
+// Returns ErrNotFound if the entity does not exist, or ErrMissingReference if the label is
+// restricted to a vocabulary that does not contain it.
+func (t LabelTable) Add(ctx context.Context, tx pgx.Tx, id uuid.UUID, label string) (bool, error) {
+	if err := t.checkEntity(ctx, tx, id); err != nil {
+		return false, err
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (%s, %s) VALUES ($1, $2) ON CONFLICT DO NOTHING`, t.Table, t.EntityColumn, t.LabelColumn)
+	tag, err := tx.Exec(ctx, query, id, label)
+	if isForeignKeyViolation(err) {
+		return false, fmt.Errorf("%w: %s %q", ErrMissingReference, t.LabelColumn, label)
+	} else if err != nil {
+		return false, fmt.Errorf("failed to insert %s: %w", t.Table, err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Remove detaches a label from the entity with the given UUID.
+// Removing a label that is not attached succeeds.
+// Returns ErrNotFound if the entity does not exist.
+func (t LabelTable) Remove(ctx context.Context, tx pgx.Tx, id uuid.UUID, label string) error {
+	if err := t.checkEntity(ctx, tx, id); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND %s = $2`, t.Table, t.EntityColumn, t.LabelColumn)
+	if _, err := tx.Exec(ctx, query, id, label); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", t.Table, err)
+	}
+	return nil
+}
+
+// ValidLabel checks that a label is usable as a tag or genre.
+func ValidLabel(label string) error {
+	if label == "" {
+		return ErrEmpty
+	}
+	if strings.TrimSpace(label) != label {
+		return ErrInvalid
+	}
+	return nil
+}
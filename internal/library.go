@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// DefaultLibrary is the library of requests that do not name one.  It holds everything that was catalogued
+// before libraries were added.
+var DefaultLibrary = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// ErrLibraryConflict is returned when writing an entity whose UUID is already used in a different library.
+var ErrLibraryConflict = errors.New("entity exists in a different library")
+
+type libraryKey struct{}
+
+// WithLibrary returns a context scoped to the given library.  Works, sources and plans are only read from
+// and written to that library.
+func WithLibrary(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, libraryKey{}, id)
+}
+
+// LibraryFromContext returns the library that ctx is scoped to, or nil if it is not scoped to one, as for
+// background jobs that work across all libraries.
+func LibraryFromContext(ctx context.Context) *uuid.UUID {
+	id, ok := ctx.Value(libraryKey{}).(uuid.UUID)
+	if !ok {
+		return nil
+	}
+	return &id
+}
+
+// writeLibrary returns the library that entities written under ctx belong to.
+func writeLibrary(ctx context.Context) uuid.UUID {
+	if id := LibraryFromContext(ctx); id != nil {
+		return *id
+	}
+	return DefaultLibrary
+}
+
+// LibraryCondition returns a SQL condition that limits column, the library of an entity, to the library
+// passed as query parameter number param, which is the result of LibraryFromContext.  The condition holds
+// for every library if the parameter is NULL.
+func LibraryCondition(column string, param int) string {
+	return fmt.Sprintf("($%[2]d::uuid IS NULL OR %[1]s = $%[2]d)", column, param)
+}
+
+// ValidateLibrary checks that a library can be created.
+func ValidateLibrary(in *vcrest.LibraryInput) error {
+	if in.Name == "" {
+		return NewFieldError("name", ErrEmpty)
+	}
+	return nil
+}
+
+// CreateLibrary adds a library and returns its API representation.  Returns a FieldError wrapping
+// ErrDuplicate if another library has the same name.
+func CreateLibrary(ctx context.Context, q Querier, in *vcrest.LibraryInput) (*vcrest.Library, error) {
+	library := &vcrest.Library{
+		Uuid: openapi_types.UUID(uuid.New()),
+		Name: in.Name,
+	}
+	err := q.QueryRow(ctx, `
+		INSERT INTO libraries (uuid, name)
+		VALUES ($1, $2)
+		RETURNING created_at`, uuid.UUID(library.Uuid), in.Name).Scan(&library.CreatedAt)
+	if isUniqueViolation(err) {
+		return nil, NewFieldError("name", fmt.Errorf("%w: library %q", ErrDuplicate, in.Name))
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to insert library: %w", err)
+	}
+	return library, nil
+}
+
+// GetLibrary returns the API representation of a library.  Returns ErrNotFound if the library does not exist.
+func GetLibrary(ctx context.Context, q Querier, id uuid.UUID) (*vcrest.Library, error) {
+	library := &vcrest.Library{
+		Uuid: openapi_types.UUID(id),
+	}
+	err := q.QueryRow(ctx, `
+		SELECT name, created_at
+		FROM libraries
+		WHERE uuid = $1`, id).Scan(&library.Name, &library.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query library: %w", err)
+	}
+	return library, nil
+}
+
+// ListLibraries returns the API representations of all libraries, ordered by name.
+func ListLibraries(ctx context.Context, tx pgx.Tx) ([]vcrest.Library, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT uuid, name, created_at
+		FROM libraries
+		ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query libraries: %w", err)
+	}
+
+	libraries := []vcrest.Library{}
+	var library vcrest.Library
+	var id uuid.UUID
+	_, err = pgx.ForEachRow(rows, []any{&id, &library.Name, &library.CreatedAt}, func() error {
+		library.Uuid = openapi_types.UUID(id)
+		libraries = append(libraries, library)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan libraries: %w", err)
+	}
+	return libraries, nil
+}
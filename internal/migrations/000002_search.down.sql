@@ -0,0 +1,11 @@
+-- Drop source search indexes
+DROP INDEX IF EXISTS sources_search_trgm_idx;
+DROP INDEX IF EXISTS sources_search_fts_idx;
+
+-- Drop work search indexes
+DROP INDEX IF EXISTS works_search_trgm_idx;
+DROP INDEX IF EXISTS works_search_fts_idx;
+
+-- Drop search text functions
+DROP FUNCTION IF EXISTS source_search_text(JSONB);
+DROP FUNCTION IF EXISTS work_search_text(JSONB);
@@ -0,0 +1,24 @@
+-- Enable trigram matching for substring and fuzzy search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Extract the searchable text from a work body
+CREATE FUNCTION work_search_text(body JSONB) RETURNS TEXT
+LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT concat_ws(' ', body->>'title', body->>'editionType')
+$$;
+
+-- Extract the searchable text from a source body
+CREATE FUNCTION source_search_text(body JSONB) RETURNS TEXT
+LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT concat_ws(' ', body->>'origDirName', body->>'path')
+$$;
+
+-- Create full-text and trigram indexes on works
+CREATE INDEX works_search_fts_idx ON works USING GIN (to_tsvector('simple', work_search_text(body)));
+CREATE INDEX works_search_trgm_idx ON works USING GIN (work_search_text(body) gin_trgm_ops);
+
+-- Create full-text and trigram indexes on sources
+CREATE INDEX sources_search_fts_idx ON sources USING GIN (to_tsvector('simple', source_search_text(body)));
+CREATE INDEX sources_search_trgm_idx ON sources USING GIN (source_search_text(body) gin_trgm_ops);
@@ -0,0 +1,16 @@
+-- Drop sort title index
+DROP INDEX IF EXISTS works_sort_title_idx;
+
+-- Drop sort title function
+DROP FUNCTION IF EXISTS work_sort_title(JSONB);
+
+-- Restore the original searchable text of a work
+CREATE OR REPLACE FUNCTION work_search_text(body JSONB) RETURNS TEXT
+LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT concat_ws(' ', body->>'title', body->>'editionType')
+$$;
+
+-- Rebuild the indexes that depend on the search text
+REINDEX INDEX works_search_fts_idx;
+REINDEX INDEX works_search_trgm_idx;
@@ -0,0 +1,24 @@
+-- Include original and alternate titles in the searchable text of a work
+CREATE OR REPLACE FUNCTION work_search_text(body JSONB) RETURNS TEXT
+LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT concat_ws(' ',
+        body->>'title',
+        body->>'originalTitle',
+        (SELECT string_agg(alt, ' ') FROM jsonb_array_elements_text(jsonb_path_query_array(body, '$.alternateTitles[*].title')) AS alt),
+        body->>'editionType')
+$$;
+
+-- Rebuild the indexes that depend on the search text
+REINDEX INDEX works_search_fts_idx;
+REINDEX INDEX works_search_trgm_idx;
+
+-- Extract the key used to order works, falling back to the title if there is no sort title
+CREATE FUNCTION work_sort_title(body JSONB) RETURNS TEXT
+LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT lower(coalesce(body->>'sortTitle', body->>'title', body->>'editionType', ''))
+$$;
+
+-- Create index for listing works by sort title
+CREATE INDEX works_sort_title_idx ON works (work_sort_title(body), uuid);
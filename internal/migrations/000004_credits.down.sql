@@ -0,0 +1,5 @@
+-- Drop credits table
+DROP TABLE IF EXISTS credits;
+
+-- Drop persons table
+DROP TABLE IF EXISTS persons;
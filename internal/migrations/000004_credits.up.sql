@@ -0,0 +1,18 @@
+-- Create persons table
+CREATE TABLE persons (
+    uuid UUID PRIMARY KEY,
+    body JSONB NOT NULL CHECK (body <> '{}'::jsonb)
+);
+
+-- Create credits table
+CREATE TABLE credits (
+    work_uuid UUID NOT NULL REFERENCES works(uuid) ON DELETE CASCADE,
+    person_uuid UUID NOT NULL REFERENCES persons(uuid) ON DELETE CASCADE,
+    role VARCHAR NOT NULL CHECK (role IN ('director', 'actor', 'writer')),
+    character_name VARCHAR CHECK (character_name <> ''),
+    billing_order INTEGER,
+    PRIMARY KEY (work_uuid, person_uuid, role)
+);
+
+-- Create index for looking up the filmography of a person
+CREATE INDEX credits_person_uuid_idx ON credits (person_uuid, role);
@@ -0,0 +1,17 @@
+-- Drop collection_works table
+DROP TABLE IF EXISTS collection_works;
+
+-- Drop collections table
+DROP TABLE IF EXISTS collections;
+
+-- Drop work_genres table
+DROP TABLE IF EXISTS work_genres;
+
+-- Drop genres table
+DROP TABLE IF EXISTS genres;
+
+-- Drop source_tags table
+DROP TABLE IF EXISTS source_tags;
+
+-- Drop work_tags table
+DROP TABLE IF EXISTS work_tags;
@@ -0,0 +1,62 @@
+-- Create work_tags table
+CREATE TABLE work_tags (
+    work_uuid UUID NOT NULL REFERENCES works(uuid) ON DELETE CASCADE,
+    tag VARCHAR NOT NULL CHECK (tag <> ''),
+    PRIMARY KEY (work_uuid, tag)
+);
+CREATE INDEX work_tags_tag_idx ON work_tags (tag);
+
+-- Create source_tags table
+CREATE TABLE source_tags (
+    source_uuid UUID NOT NULL REFERENCES sources(uuid) ON DELETE CASCADE,
+    tag VARCHAR NOT NULL CHECK (tag <> ''),
+    PRIMARY KEY (source_uuid, tag)
+);
+CREATE INDEX source_tags_tag_idx ON source_tags (tag);
+
+-- Create genres table holding the controlled genre vocabulary
+CREATE TABLE genres (
+    name VARCHAR PRIMARY KEY CHECK (name <> '')
+);
+INSERT INTO genres (name) VALUES
+    ('action'),
+    ('adventure'),
+    ('animation'),
+    ('comedy'),
+    ('crime'),
+    ('documentary'),
+    ('drama'),
+    ('family'),
+    ('fantasy'),
+    ('history'),
+    ('horror'),
+    ('music'),
+    ('mystery'),
+    ('romance'),
+    ('science_fiction'),
+    ('thriller'),
+    ('war'),
+    ('western');
+
+-- Create work_genres table
+CREATE TABLE work_genres (
+    work_uuid UUID NOT NULL REFERENCES works(uuid) ON DELETE CASCADE,
+    genre VARCHAR NOT NULL REFERENCES genres(name),
+    PRIMARY KEY (work_uuid, genre)
+);
+CREATE INDEX work_genres_genre_idx ON work_genres (genre);
+
+-- Create collections table
+CREATE TABLE collections (
+    uuid UUID PRIMARY KEY,
+    body JSONB NOT NULL CHECK (body <> '{}'::jsonb)
+);
+
+-- Create collection_works table
+CREATE TABLE collection_works (
+    collection_uuid UUID NOT NULL REFERENCES collections(uuid) ON DELETE CASCADE,
+    work_uuid UUID NOT NULL REFERENCES works(uuid) ON DELETE CASCADE,
+    position INTEGER NOT NULL CHECK (position >= 0),
+    PRIMARY KEY (collection_uuid, work_uuid)
+);
+CREATE INDEX collection_works_position_idx ON collection_works (collection_uuid, position);
@@ -0,0 +1,9 @@
+-- Drop deleted_at indexes
+DROP INDEX IF EXISTS plans_deleted_at_idx;
+DROP INDEX IF EXISTS sources_deleted_at_idx;
+DROP INDEX IF EXISTS works_deleted_at_idx;
+
+-- Drop deleted_at columns
+ALTER TABLE plans DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE sources DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE works DROP COLUMN IF EXISTS deleted_at;
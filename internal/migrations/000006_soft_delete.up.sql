@@ -0,0 +1,9 @@
+-- Add deleted_at columns to works, sources and plans
+ALTER TABLE works ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE sources ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE plans ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Index deleted rows so the purge job can find expired ones without scanning live rows
+CREATE INDEX works_deleted_at_idx ON works (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX sources_deleted_at_idx ON sources (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX plans_deleted_at_idx ON plans (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Drop entity_history table
+DROP TABLE IF EXISTS entity_history;
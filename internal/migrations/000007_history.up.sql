@@ -0,0 +1,16 @@
+-- Create entity_history table.  Rows are kept after the entity is purged, so there is no foreign key.
+CREATE TABLE entity_history (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR NOT NULL CHECK (entity_type IN ('work', 'source', 'plan')),
+    entity_uuid UUID NOT NULL,
+    kind VARCHAR NOT NULL CHECK (kind <> ''),
+    operation VARCHAR NOT NULL CHECK (operation IN ('create', 'update', 'delete', 'restore', 'revert')),
+    old_body JSONB,
+    new_body JSONB NOT NULL,
+    actor VARCHAR,
+    request_id VARCHAR,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Index for reading the history of a single entity, newest first
+CREATE INDEX entity_history_entity_idx ON entity_history (entity_type, entity_uuid, id);
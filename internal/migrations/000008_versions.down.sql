@@ -0,0 +1,10 @@
+-- Drop version triggers
+DROP TRIGGER IF EXISTS plans_bump_version ON plans;
+DROP TRIGGER IF EXISTS sources_bump_version ON sources;
+DROP TRIGGER IF EXISTS works_bump_version ON works;
+DROP FUNCTION IF EXISTS bump_version();
+
+-- Drop version columns
+ALTER TABLE plans DROP COLUMN IF EXISTS version;
+ALTER TABLE sources DROP COLUMN IF EXISTS version;
+ALTER TABLE works DROP COLUMN IF EXISTS version;
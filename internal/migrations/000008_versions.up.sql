@@ -0,0 +1,20 @@
+-- Add version columns to works, sources and plans
+ALTER TABLE works ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE sources ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE plans ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+-- Bump the version on every update, so that no write path can forget to
+CREATE FUNCTION bump_version() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER works_bump_version BEFORE UPDATE ON works
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER sources_bump_version BEFORE UPDATE ON sources
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+CREATE TRIGGER plans_bump_version BEFORE UPDATE ON plans
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
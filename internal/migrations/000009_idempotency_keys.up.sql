@@ -0,0 +1,12 @@
+-- Create idempotency_keys table, remembering which entity each create request made
+CREATE TABLE idempotency_keys (
+    operation VARCHAR NOT NULL CHECK (operation <> ''),
+    key VARCHAR NOT NULL CHECK (key <> ''),
+    request_hash BYTEA NOT NULL,
+    entity_uuid UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (operation, key)
+);
+
+-- Index for expiring old keys
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at);
@@ -0,0 +1,8 @@
+-- Drop parent indexes
+DROP INDEX IF EXISTS sources_parent_uuid_idx;
+DROP INDEX IF EXISTS works_parent_uuid_idx;
+
+-- Drop parent triggers
+DROP TRIGGER IF EXISTS sources_sync_parent_uuid ON sources;
+DROP TRIGGER IF EXISTS works_sync_parent_uuid ON works;
+DROP FUNCTION IF EXISTS sync_parent_uuid();
@@ -0,0 +1,17 @@
+-- Keep parent_uuid in step with the parent referenced by the body, so that every write path maintains it
+CREATE FUNCTION sync_parent_uuid() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.parent_uuid := (NEW.body->>'parentUuid')::uuid;
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER works_sync_parent_uuid BEFORE INSERT OR UPDATE ON works
+    FOR EACH ROW EXECUTE FUNCTION sync_parent_uuid();
+CREATE TRIGGER sources_sync_parent_uuid BEFORE INSERT OR UPDATE ON sources
+    FOR EACH ROW EXECUTE FUNCTION sync_parent_uuid();
+
+-- Create indexes for looking up the children of an entity
+CREATE INDEX works_parent_uuid_idx ON works (parent_uuid) WHERE parent_uuid IS NOT NULL;
+CREATE INDEX sources_parent_uuid_idx ON sources (parent_uuid) WHERE parent_uuid IS NOT NULL;
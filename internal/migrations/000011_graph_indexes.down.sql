@@ -0,0 +1,3 @@
+-- Drop plan link indexes
+DROP INDEX IF EXISTS plan_outputs_work_uuid_idx;
+DROP INDEX IF EXISTS plan_inputs_source_uuid_idx;
@@ -0,0 +1,3 @@
+-- Create indexes for following plan links from sources and works, not just from plans
+CREATE INDEX plan_inputs_source_uuid_idx ON plan_inputs (source_uuid);
+CREATE INDEX plan_outputs_work_uuid_idx ON plan_outputs (work_uuid);
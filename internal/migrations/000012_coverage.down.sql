@@ -0,0 +1,8 @@
+-- Drop plan completion
+DROP INDEX IF EXISTS plans_incomplete_idx;
+ALTER TABLE plans DROP COLUMN IF EXISTS completed_at;
+
+-- Drop creation times
+ALTER TABLE plans DROP COLUMN IF EXISTS created_at;
+ALTER TABLE sources DROP COLUMN IF EXISTS created_at;
+ALTER TABLE works DROP COLUMN IF EXISTS created_at;
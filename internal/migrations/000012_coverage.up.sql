@@ -0,0 +1,27 @@
+-- Add creation times, taken from the entity history where it goes back far enough
+ALTER TABLE works ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE sources ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE plans ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- The backfill does not change the entities, so it must not bump their versions
+ALTER TABLE works DISABLE TRIGGER works_bump_version;
+ALTER TABLE sources DISABLE TRIGGER sources_bump_version;
+ALTER TABLE plans DISABLE TRIGGER plans_bump_version;
+
+UPDATE works t SET created_at = h.created_at
+FROM (SELECT entity_uuid, min(created_at) AS created_at FROM entity_history WHERE entity_type = 'work' GROUP BY entity_uuid) h
+WHERE h.entity_uuid = t.uuid;
+UPDATE sources t SET created_at = h.created_at
+FROM (SELECT entity_uuid, min(created_at) AS created_at FROM entity_history WHERE entity_type = 'source' GROUP BY entity_uuid) h
+WHERE h.entity_uuid = t.uuid;
+UPDATE plans t SET created_at = h.created_at
+FROM (SELECT entity_uuid, min(created_at) AS created_at FROM entity_history WHERE entity_type = 'plan' GROUP BY entity_uuid) h
+WHERE h.entity_uuid = t.uuid;
+
+ALTER TABLE works ENABLE TRIGGER works_bump_version;
+ALTER TABLE sources ENABLE TRIGGER sources_bump_version;
+ALTER TABLE plans ENABLE TRIGGER plans_bump_version;
+
+-- Add plan completion
+ALTER TABLE plans ADD COLUMN completed_at TIMESTAMPTZ;
+CREATE INDEX plans_incomplete_idx ON plans (uuid) WHERE completed_at IS NULL AND deleted_at IS NULL;
@@ -0,0 +1,2 @@
+-- Drop library_stats materialized view
+DROP MATERIALIZED VIEW IF EXISTS library_stats;
@@ -0,0 +1,33 @@
+-- Create library_stats materialized view.  Each row is one statistic, named by its category and key, over
+-- the entities outside the trash.  File bodies do not carry sizeBytes or durationSeconds yet, so the media
+-- totals are NULL until they do.
+CREATE MATERIALIZED VIEW library_stats AS
+SELECT category, key, value, now() AS refreshed_at
+FROM (
+    SELECT 'work_kind' AS category, kind AS key, count(*) AS value
+    FROM works WHERE deleted_at IS NULL GROUP BY kind
+    UNION ALL
+    SELECT 'source_kind', kind, count(*)
+    FROM sources WHERE deleted_at IS NULL GROUP BY kind
+    UNION ALL
+    SELECT 'plan_kind', kind, count(*)
+    FROM plans WHERE deleted_at IS NULL GROUP BY kind
+    UNION ALL
+    SELECT 'decade', ((body->>'releaseYear')::int / 10 * 10)::text, count(*)
+    FROM works WHERE deleted_at IS NULL AND body ? 'releaseYear' GROUP BY 2
+    UNION ALL
+    SELECT 'missing_tmdb_id', 'work', count(*)
+    FROM works WHERE deleted_at IS NULL AND kind = 'movie' AND NOT body ? 'tmdbId'
+    UNION ALL
+    SELECT 'missing_tmdb_id', 'person', count(*)
+    FROM persons WHERE NOT body ? 'tmdbId'
+    UNION ALL
+    SELECT 'media', 'size_bytes', sum((body->>'sizeBytes')::bigint)
+    FROM sources WHERE deleted_at IS NULL AND kind = 'file'
+    UNION ALL
+    SELECT 'media', 'duration_seconds', sum((body->>'durationSeconds')::bigint)
+    FROM sources WHERE deleted_at IS NULL AND kind = 'file'
+) stats;
+
+-- Unique index, which REFRESH MATERIALIZED VIEW CONCURRENTLY requires
+CREATE UNIQUE INDEX library_stats_category_key_idx ON library_stats (category, key);
@@ -0,0 +1,6 @@
+-- Drop change feed
+DROP TRIGGER IF EXISTS plans_record_change ON plans;
+DROP TRIGGER IF EXISTS sources_record_change ON sources;
+DROP TRIGGER IF EXISTS works_record_change ON works;
+DROP FUNCTION IF EXISTS record_change();
+DROP TABLE IF EXISTS changes;
@@ -0,0 +1,48 @@
+-- Create changes table, the feed of writes to works, sources and plans.  txid is the writing transaction,
+-- so that readers can wait for every transaction that might still add earlier changes to finish.
+CREATE TABLE changes (
+    seq BIGSERIAL PRIMARY KEY,
+    txid xid8 NOT NULL DEFAULT pg_current_xact_id(),
+    entity_type VARCHAR NOT NULL CHECK (entity_type IN ('work', 'source', 'plan')),
+    entity_uuid UUID NOT NULL,
+    operation VARCHAR NOT NULL CHECK (operation IN ('upsert', 'delete')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Index for reading the feed in order
+CREATE INDEX changes_txid_seq_idx ON changes (txid, seq);
+
+-- Record every write to an entity table.  Moving a row to the trash is a delete and taking it back out is
+-- an upsert; purging a row that is already in the trash is not recorded again.
+CREATE FUNCTION record_change() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.deleted_at IS NULL THEN
+            INSERT INTO changes (entity_type, entity_uuid, operation) VALUES (TG_ARGV[0], OLD.uuid, 'delete');
+        END IF;
+    ELSIF NEW.deleted_at IS NOT NULL THEN
+        IF TG_OP = 'INSERT' OR OLD.deleted_at IS NULL THEN
+            INSERT INTO changes (entity_type, entity_uuid, operation) VALUES (TG_ARGV[0], NEW.uuid, 'delete');
+        END IF;
+    ELSE
+        INSERT INTO changes (entity_type, entity_uuid, operation) VALUES (TG_ARGV[0], NEW.uuid, 'upsert');
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER works_record_change AFTER INSERT OR UPDATE OR DELETE ON works
+    FOR EACH ROW EXECUTE FUNCTION record_change('work');
+CREATE TRIGGER sources_record_change AFTER INSERT OR UPDATE OR DELETE ON sources
+    FOR EACH ROW EXECUTE FUNCTION record_change('source');
+CREATE TRIGGER plans_record_change AFTER INSERT OR UPDATE OR DELETE ON plans
+    FOR EACH ROW EXECUTE FUNCTION record_change('plan');
+
+-- Start the feed with the entities that already exist, so that a new consumer sees everything
+INSERT INTO changes (entity_type, entity_uuid, operation)
+SELECT 'work', uuid, 'upsert' FROM works WHERE deleted_at IS NULL ORDER BY uuid;
+INSERT INTO changes (entity_type, entity_uuid, operation)
+SELECT 'source', uuid, 'upsert' FROM sources WHERE deleted_at IS NULL ORDER BY uuid;
+INSERT INTO changes (entity_type, entity_uuid, operation)
+SELECT 'plan', uuid, 'upsert' FROM plans WHERE deleted_at IS NULL ORDER BY uuid;
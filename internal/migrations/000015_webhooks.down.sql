@@ -0,0 +1,8 @@
+-- Drop webhooks
+DROP TRIGGER IF EXISTS plans_status_webhook_event ON plans;
+DROP TRIGGER IF EXISTS entity_history_webhook_event ON entity_history;
+DROP FUNCTION IF EXISTS plan_status_webhook_event();
+DROP FUNCTION IF EXISTS history_webhook_event();
+DROP FUNCTION IF EXISTS queue_webhook_event(VARCHAR, JSONB);
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,86 @@
+-- Create webhooks table.  An empty events array subscribes to every event.
+CREATE TABLE webhooks (
+    uuid UUID PRIMARY KEY,
+    url VARCHAR NOT NULL CHECK (url <> ''),
+    secret VARCHAR NOT NULL CHECK (secret <> ''),
+    events VARCHAR[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create webhook_deliveries table, which is both the queue of events to send and the delivery log.
+-- enqueued is set once a job has been created to send the delivery.
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_uuid UUID NOT NULL REFERENCES webhooks(uuid) ON DELETE CASCADE,
+    event VARCHAR NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_status_code INT,
+    last_error VARCHAR,
+    enqueued BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ
+);
+
+-- Index for reading the delivery log of a webhook, newest first
+CREATE INDEX webhook_deliveries_webhook_uuid_idx ON webhook_deliveries (webhook_uuid, id);
+
+-- Index for finding deliveries that still need a job
+CREATE INDEX webhook_deliveries_unenqueued_idx ON webhook_deliveries (id) WHERE NOT enqueued;
+
+-- Queue a delivery of an event to every webhook subscribed to it
+CREATE FUNCTION queue_webhook_event(event_name VARCHAR, payload JSONB) RETURNS void
+LANGUAGE sql AS $$
+    INSERT INTO webhook_deliveries (webhook_uuid, event, payload)
+    SELECT uuid, event_name, payload
+    FROM webhooks
+    WHERE events = '{}' OR event_name = ANY(events);
+$$;
+
+-- Every write to works, sources and plans is recorded in the history, so events are raised from there.
+-- Restoring an entity from the trash raises a created event, since it reappears to subscribers.
+CREATE FUNCTION history_webhook_event() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    event_name VARCHAR := NEW.entity_type || '.' || CASE NEW.operation
+        WHEN 'create' THEN 'created'
+        WHEN 'restore' THEN 'created'
+        WHEN 'delete' THEN 'deleted'
+        ELSE 'updated'
+    END;
+BEGIN
+    PERFORM queue_webhook_event(event_name, jsonb_build_object(
+        'event', event_name,
+        'type', NEW.entity_type,
+        'uuid', NEW.entity_uuid,
+        'kind', NEW.kind,
+        'body', NEW.new_body,
+        'occurredAt', NEW.created_at));
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER entity_history_webhook_event AFTER INSERT ON entity_history
+    FOR EACH ROW EXECUTE FUNCTION history_webhook_event();
+
+-- Completing and reopening plans is not recorded in the history, so it raises its own events
+CREATE FUNCTION plan_status_webhook_event() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    event_name VARCHAR := CASE WHEN NEW.completed_at IS NULL THEN 'plan.reopened' ELSE 'plan.completed' END;
+BEGIN
+    PERFORM queue_webhook_event(event_name, jsonb_build_object(
+        'event', event_name,
+        'type', 'plan',
+        'uuid', NEW.uuid,
+        'kind', NEW.kind,
+        'completedAt', NEW.completed_at,
+        'occurredAt', now()));
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER plans_status_webhook_event AFTER UPDATE OF completed_at ON plans
+    FOR EACH ROW WHEN (OLD.completed_at IS DISTINCT FROM NEW.completed_at)
+    EXECUTE FUNCTION plan_status_webhook_event();
@@ -0,0 +1,24 @@
+-- Stop notifying listeners
+DROP TRIGGER IF EXISTS changes_notify ON changes;
+DROP FUNCTION IF EXISTS notify_changes();
+
+-- Stop recording the kind of each changed entity
+CREATE OR REPLACE FUNCTION record_change() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.deleted_at IS NULL THEN
+            INSERT INTO changes (entity_type, entity_uuid, operation) VALUES (TG_ARGV[0], OLD.uuid, 'delete');
+        END IF;
+    ELSIF NEW.deleted_at IS NOT NULL THEN
+        IF TG_OP = 'INSERT' OR OLD.deleted_at IS NULL THEN
+            INSERT INTO changes (entity_type, entity_uuid, operation) VALUES (TG_ARGV[0], NEW.uuid, 'delete');
+        END IF;
+    ELSE
+        INSERT INTO changes (entity_type, entity_uuid, operation) VALUES (TG_ARGV[0], NEW.uuid, 'upsert');
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+ALTER TABLE changes DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,37 @@
+-- Record the kind of each changed entity, so that the feed can be filtered by kind
+ALTER TABLE changes ADD COLUMN kind VARCHAR;
+
+UPDATE changes c SET kind = t.kind FROM works t WHERE c.entity_type = 'work' AND t.uuid = c.entity_uuid;
+UPDATE changes c SET kind = t.kind FROM sources t WHERE c.entity_type = 'source' AND t.uuid = c.entity_uuid;
+UPDATE changes c SET kind = t.kind FROM plans t WHERE c.entity_type = 'plan' AND t.uuid = c.entity_uuid;
+
+CREATE OR REPLACE FUNCTION record_change() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.deleted_at IS NULL THEN
+            INSERT INTO changes (entity_type, entity_uuid, kind, operation) VALUES (TG_ARGV[0], OLD.uuid, OLD.kind, 'delete');
+        END IF;
+    ELSIF NEW.deleted_at IS NOT NULL THEN
+        IF TG_OP = 'INSERT' OR OLD.deleted_at IS NULL THEN
+            INSERT INTO changes (entity_type, entity_uuid, kind, operation) VALUES (TG_ARGV[0], NEW.uuid, NEW.kind, 'delete');
+        END IF;
+    ELSE
+        INSERT INTO changes (entity_type, entity_uuid, kind, operation) VALUES (TG_ARGV[0], NEW.uuid, NEW.kind, 'upsert');
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+-- Wake up listeners when changes are added.  Notifications are delivered when the transaction commits,
+-- and repeats within a transaction are folded into one.
+CREATE FUNCTION notify_changes() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM pg_notify('catalog_changes', '');
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER changes_notify AFTER INSERT ON changes
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_changes();
@@ -0,0 +1,11 @@
+-- Create api_keys table.  Only the SHA-256 hash of each key is stored, so a leaked database does not leak
+-- usable keys.
+CREATE TABLE api_keys (
+    uuid UUID PRIMARY KEY,
+    name VARCHAR NOT NULL CHECK (name <> ''),
+    key_hash BYTEA NOT NULL UNIQUE,
+    scopes VARCHAR[] NOT NULL CHECK (scopes <@ ARRAY['read', 'write', 'admin']::VARCHAR[]),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
@@ -0,0 +1,59 @@
+-- Compute the statistics over all libraries again
+DROP MATERIALIZED VIEW IF EXISTS library_stats;
+CREATE MATERIALIZED VIEW library_stats AS
+SELECT category, key, value, now() AS refreshed_at
+FROM (
+    SELECT 'work_kind' AS category, kind AS key, count(*) AS value
+    FROM works WHERE deleted_at IS NULL GROUP BY kind
+    UNION ALL
+    SELECT 'source_kind', kind, count(*)
+    FROM sources WHERE deleted_at IS NULL GROUP BY kind
+    UNION ALL
+    SELECT 'plan_kind', kind, count(*)
+    FROM plans WHERE deleted_at IS NULL GROUP BY kind
+    UNION ALL
+    SELECT 'decade', ((body->>'releaseYear')::int / 10 * 10)::text, count(*)
+    FROM works WHERE deleted_at IS NULL AND body ? 'releaseYear' GROUP BY 2
+    UNION ALL
+    SELECT 'missing_tmdb_id', 'work', count(*)
+    FROM works WHERE deleted_at IS NULL AND kind = 'movie' AND NOT body ? 'tmdbId'
+    UNION ALL
+    SELECT 'missing_tmdb_id', 'person', count(*)
+    FROM persons WHERE NOT body ? 'tmdbId'
+    UNION ALL
+    SELECT 'media', 'size_bytes', sum((body->>'sizeBytes')::bigint)
+    FROM sources WHERE deleted_at IS NULL AND kind = 'file'
+    UNION ALL
+    SELECT 'media', 'duration_seconds', sum((body->>'durationSeconds')::bigint)
+    FROM sources WHERE deleted_at IS NULL AND kind = 'file'
+) stats;
+CREATE UNIQUE INDEX library_stats_category_key_idx ON library_stats (category, key);
+
+-- Stop recording the library of each changed entity
+CREATE OR REPLACE FUNCTION record_change() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.deleted_at IS NULL THEN
+            INSERT INTO changes (entity_type, entity_uuid, kind, operation) VALUES (TG_ARGV[0], OLD.uuid, OLD.kind, 'delete');
+        END IF;
+    ELSIF NEW.deleted_at IS NOT NULL THEN
+        IF TG_OP = 'INSERT' OR OLD.deleted_at IS NULL THEN
+            INSERT INTO changes (entity_type, entity_uuid, kind, operation) VALUES (TG_ARGV[0], NEW.uuid, NEW.kind, 'delete');
+        END IF;
+    ELSE
+        INSERT INTO changes (entity_type, entity_uuid, kind, operation) VALUES (TG_ARGV[0], NEW.uuid, NEW.kind, 'upsert');
+    END IF;
+    RETURN NULL;
+END;
+$$;
+ALTER TABLE changes DROP COLUMN IF EXISTS library_uuid;
+
+-- Drop library columns, which also drops their indexes
+ALTER TABLE works DROP CONSTRAINT IF EXISTS works_library_parent_fkey;
+ALTER TABLE sources DROP CONSTRAINT IF EXISTS sources_library_parent_fkey;
+ALTER TABLE works DROP COLUMN IF EXISTS library_uuid;
+ALTER TABLE sources DROP COLUMN IF EXISTS library_uuid;
+ALTER TABLE plans DROP COLUMN IF EXISTS library_uuid;
+
+DROP TABLE IF EXISTS libraries;
@@ -0,0 +1,94 @@
+-- Create libraries table.  Every work, source and plan belongs to one library, and the entities of one library
+-- cannot reference those of another.
+CREATE TABLE libraries (
+    uuid UUID PRIMARY KEY,
+    name VARCHAR NOT NULL UNIQUE CHECK (name <> ''),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- The default library holds everything that was catalogued before libraries were added
+INSERT INTO libraries (uuid, name) VALUES ('00000000-0000-0000-0000-000000000001', 'default');
+
+ALTER TABLE works ADD COLUMN library_uuid UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES libraries(uuid);
+ALTER TABLE sources ADD COLUMN library_uuid UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES libraries(uuid);
+ALTER TABLE plans ADD COLUMN library_uuid UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES libraries(uuid);
+ALTER TABLE works ALTER COLUMN library_uuid DROP DEFAULT;
+ALTER TABLE sources ALTER COLUMN library_uuid DROP DEFAULT;
+ALTER TABLE plans ALTER COLUMN library_uuid DROP DEFAULT;
+
+-- Create indexes for listing the entities of a library, which also let parents be referenced together with
+-- their library
+ALTER TABLE works ADD CONSTRAINT works_library_uuid_uuid_key UNIQUE (library_uuid, uuid);
+ALTER TABLE sources ADD CONSTRAINT sources_library_uuid_uuid_key UNIQUE (library_uuid, uuid);
+CREATE INDEX plans_library_uuid_idx ON plans (library_uuid, uuid);
+
+-- Parents must be in the same library as their children
+ALTER TABLE works ADD CONSTRAINT works_library_parent_fkey
+    FOREIGN KEY (library_uuid, parent_uuid) REFERENCES works (library_uuid, uuid) ON DELETE CASCADE;
+ALTER TABLE sources ADD CONSTRAINT sources_library_parent_fkey
+    FOREIGN KEY (library_uuid, parent_uuid) REFERENCES sources (library_uuid, uuid) ON DELETE CASCADE;
+
+-- Record the library of each changed entity, so that the feed can be read for one library
+ALTER TABLE changes ADD COLUMN library_uuid UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE changes ALTER COLUMN library_uuid DROP DEFAULT;
+
+CREATE OR REPLACE FUNCTION record_change() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.deleted_at IS NULL THEN
+            INSERT INTO changes (entity_type, entity_uuid, kind, library_uuid, operation)
+            VALUES (TG_ARGV[0], OLD.uuid, OLD.kind, OLD.library_uuid, 'delete');
+        END IF;
+    ELSIF NEW.deleted_at IS NOT NULL THEN
+        IF TG_OP = 'INSERT' OR OLD.deleted_at IS NULL THEN
+            INSERT INTO changes (entity_type, entity_uuid, kind, library_uuid, operation)
+            VALUES (TG_ARGV[0], NEW.uuid, NEW.kind, NEW.library_uuid, 'delete');
+        END IF;
+    ELSE
+        INSERT INTO changes (entity_type, entity_uuid, kind, library_uuid, operation)
+        VALUES (TG_ARGV[0], NEW.uuid, NEW.kind, NEW.library_uuid, 'upsert');
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+-- Compute the statistics of each library separately.  Persons are shared by all libraries, so every library
+-- reports the same count of persons without a TMDB ID.
+DROP MATERIALIZED VIEW library_stats;
+CREATE MATERIALIZED VIEW library_stats AS
+SELECT library_uuid, category, key, value, now() AS refreshed_at
+FROM (
+    SELECT library_uuid, 'work_kind' AS category, kind AS key, count(*) AS value
+    FROM works WHERE deleted_at IS NULL GROUP BY library_uuid, kind
+    UNION ALL
+    SELECT library_uuid, 'source_kind', kind, count(*)
+    FROM sources WHERE deleted_at IS NULL GROUP BY library_uuid, kind
+    UNION ALL
+    SELECT library_uuid, 'plan_kind', kind, count(*)
+    FROM plans WHERE deleted_at IS NULL GROUP BY library_uuid, kind
+    UNION ALL
+    SELECT library_uuid, 'decade', ((body->>'releaseYear')::int / 10 * 10)::text, count(*)
+    FROM works WHERE deleted_at IS NULL AND body ? 'releaseYear' GROUP BY 1, 3
+    UNION ALL
+    SELECT l.uuid, 'missing_tmdb_id', 'work', count(w.uuid)
+    FROM libraries l
+    LEFT JOIN works w ON w.library_uuid = l.uuid AND w.deleted_at IS NULL AND w.kind = 'movie' AND NOT w.body ? 'tmdbId'
+    GROUP BY l.uuid
+    UNION ALL
+    SELECT l.uuid, 'missing_tmdb_id', 'person', (SELECT count(*) FROM persons WHERE NOT body ? 'tmdbId')
+    FROM libraries l
+    UNION ALL
+    SELECT l.uuid, 'media', 'size_bytes', sum((s.body->>'sizeBytes')::bigint)
+    FROM libraries l
+    LEFT JOIN sources s ON s.library_uuid = l.uuid AND s.deleted_at IS NULL AND s.kind = 'file'
+    GROUP BY l.uuid
+    UNION ALL
+    SELECT l.uuid, 'media', 'duration_seconds', sum((s.body->>'durationSeconds')::bigint)
+    FROM libraries l
+    LEFT JOIN sources s ON s.library_uuid = l.uuid AND s.deleted_at IS NULL AND s.kind = 'file'
+    GROUP BY l.uuid
+) stats;
+
+-- Unique index, which REFRESH MATERIALIZED VIEW CONCURRENTLY requires
+CREATE UNIQUE INDEX library_stats_library_category_key_idx ON library_stats (library_uuid, category, key);
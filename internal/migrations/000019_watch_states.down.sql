@@ -0,0 +1,2 @@
+-- Drop watch_states
+DROP TABLE IF EXISTS watch_states;
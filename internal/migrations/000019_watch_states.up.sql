@@ -0,0 +1,17 @@
+-- Create watch_states table.  Users are identified by the stable ID of the principal that recorded the state,
+-- which names the API key or the issuer and subject of the bearer token, never by a name that another caller
+-- could take.  Works that a user has no row for are unwatched.
+CREATE TABLE watch_states (
+    user_id VARCHAR NOT NULL CHECK (user_id <> ''),
+    work_uuid UUID NOT NULL REFERENCES works (uuid) ON DELETE CASCADE,
+    watched BOOLEAN NOT NULL DEFAULT false,
+    play_count INTEGER NOT NULL DEFAULT 0 CHECK (play_count >= 0),
+    last_watched_at TIMESTAMPTZ,
+    rating SMALLINT CHECK (rating BETWEEN 1 AND 5),
+    on_watchlist BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, work_uuid)
+);
+
+-- Index for deleting the watch state of purged works
+CREATE INDEX watch_states_work_uuid_idx ON watch_states (work_uuid);
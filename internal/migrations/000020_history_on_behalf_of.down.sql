@@ -0,0 +1,2 @@
+-- Drop on_behalf_of column
+ALTER TABLE entity_history DROP COLUMN IF EXISTS on_behalf_of;
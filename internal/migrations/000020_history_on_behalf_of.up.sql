@@ -0,0 +1,2 @@
+-- Record who a change was made on behalf of, as named by the client, apart from the authenticated actor
+ALTER TABLE entity_history ADD COLUMN on_behalf_of VARCHAR;
@@ -0,0 +1,6 @@
+-- Make idempotency keys global again, forgetting those that would clash
+DELETE FROM idempotency_keys;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS library_uuid;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS principal_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (operation, key);
@@ -0,0 +1,7 @@
+-- Scope idempotency keys to the library and the caller that claimed them, so that callers cannot replay each
+-- other's requests by reusing a key.  Keys claimed before cannot be attributed to a caller, so they are forgotten.
+DELETE FROM idempotency_keys;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD COLUMN library_uuid UUID NOT NULL REFERENCES libraries(uuid);
+ALTER TABLE idempotency_keys ADD COLUMN principal_id VARCHAR NOT NULL CHECK (principal_id <> '');
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (library_uuid, principal_id, operation, key);
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// changesChannel is the channel notified by the database when changes are added to the feed.
+const changesChannel = "catalog_changes"
+
+// notifierRetryDelay is how long ChangeNotifier waits before listening again after losing its connection.
+const notifierRetryDelay = 5 * time.Second
+
+// ChangeNotifier listens for notifications that changes were added to the feed and passes them on to its
+// subscribers.  Notifications carry no data, so subscribers read the feed to find out what changed.
+type ChangeNotifier struct {
+	pool *pgxpool.Pool
+
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+// NewChangeNotifier creates a ChangeNotifier.  Run must be called for it to deliver notifications.
+func NewChangeNotifier(pool *pgxpool.Pool) *ChangeNotifier {
+	return &ChangeNotifier{
+		pool: pool,
+		subs: map[chan struct{}]struct{}{},
+	}
+}
+
+// Subscribe returns a channel that receives a value after changes are added to the feed, and a function
+// that ends the subscription.  Notifications that arrive while the subscriber is busy are folded into one.
+func (n *ChangeNotifier) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	n.mu.Lock()
+	n.subs[ch] = struct{}{}
+	n.mu.Unlock()
+	return ch, func() {
+		n.mu.Lock()
+		delete(n.subs, ch)
+		n.mu.Unlock()
+	}
+}
+
+func (n *ChangeNotifier) broadcast() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for ch := range n.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run listens for notifications until ctx is done, reconnecting if the connection is lost.  Subscribers
+// are notified after each reconnection too, since notifications may have been missed in between.
+func (n *ChangeNotifier) Run(ctx context.Context) {
+	for {
+		err := n.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Lost change notifications, retrying in %v: %v", notifierRetryDelay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(notifierRetryDelay):
+		}
+	}
+}
+
+func (n *ChangeNotifier) listen(ctx context.Context) error {
+	pooled, err := n.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// The connection is left listening, so it must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+changesChannel); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	n.broadcast()
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+		n.broadcast()
+	}
+}
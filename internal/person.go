@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/vcrest"
+	"github.com/oapi-codegen/nullable"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type Person struct {
+	Name   string `json:"name"`
+	TmdbId *int32 `json:"tmdbId,omitempty"`
+}
+
+// ToAPI converts the Person to its API representation.
+func (p *Person) ToAPI() *vcrest.PersonDetails {
+	result := &vcrest.PersonDetails{
+		Name: nullable.NewNullableWithValue(p.Name),
+	}
+	if p.TmdbId != nil {
+		result.TmdbId = nullable.NewNullableWithValue(*p.TmdbId)
+	}
+	return result
+}
+
+// PersonToAPI converts a row from the persons table to its API representation.
+func PersonToAPI(id uuid.UUID, body json.RawMessage) (*vcrest.Person, error) {
+	var personBody Person
+	if err := json.Unmarshal(body, &personBody); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal person body: %w", err)
+	}
+	return &vcrest.Person{
+		Uuid:    openapi_types.UUID(id),
+		Details: personBody.ToAPI(),
+	}, nil
+}
+
+type CreditRole string
+
+const (
+	CreditRoleDirector CreditRole = "director"
+	CreditRoleActor    CreditRole = "actor"
+	CreditRoleWriter   CreditRole = "writer"
+)
+
+func (r CreditRole) IsValid() bool {
+	switch r {
+	case CreditRoleDirector, CreditRoleActor, CreditRoleWriter:
+		return true
+	default:
+		return false
+	}
+}
+
+// Credit is a row of the credits table.
+type Credit struct {
+	WorkUUID      uuid.UUID
+	PersonUUID    uuid.UUID
+	Role          CreditRole
+	CharacterName *string
+	BillingOrder  *int32
+}
+
+// ToAPI converts the Credit to its API representation.
+func (c *Credit) ToAPI() *vcrest.Credit {
+	result := &vcrest.Credit{
+		WorkUuid:   nullable.NewNullableWithValue(openapi_types.UUID(c.WorkUUID)),
+		PersonUuid: nullable.NewNullableWithValue(openapi_types.UUID(c.PersonUUID)),
+		Role:       nullable.NewNullableWithValue(string(c.Role)),
+	}
+	if c.CharacterName != nil {
+		result.CharacterName = nullable.NewNullableWithValue(*c.CharacterName)
+	}
+	if c.BillingOrder != nil {
+		result.BillingOrder = nullable.NewNullableWithValue(*c.BillingOrder)
+	}
+	return result
+}
+
+// CreditsFromAPI validates and converts a list of credits for the given work from their API representation.
+// Returned errors are prefixed with the name and index of the offending field.
+func CreditsFromAPI(workUUID uuid.UUID, in []vcrest.Credit) ([]Credit, error) {
+	type creditKey struct {
+		personUUID uuid.UUID
+		role       CreditRole
+	}
+	seen := map[creditKey]bool{}
+	out := make([]Credit, 0, len(in))
+	for i, c := range in {
+		if err := errors.Join(
+			FieldRequired(c.PersonUuid),
+			FieldNotNull(c.PersonUuid),
+			FieldNonZeroUUID(c.PersonUuid),
+		); err != nil {
+			return nil, NewFieldError(fmt.Sprintf("credits[%d].personUuid", i), err)
+		}
+		if err := errors.Join(
+			FieldRequired(c.Role),
+			FieldNotNull(c.Role),
+			FieldNotEmpty(c.Role),
+		); err != nil {
+			return nil, NewFieldError(fmt.Sprintf("credits[%d].role", i), err)
+		}
+		if !CreditRole(c.Role.MustGet()).IsValid() {
+			return nil, NewFieldError(fmt.Sprintf("credits[%d].role", i), ErrInvalid)
+		}
+		if err := FieldNotEmpty(c.CharacterName); err != nil {
+			return nil, NewFieldError(fmt.Sprintf("credits[%d].characterName", i), err)
+		}
+		credit := Credit{
+			WorkUUID:   workUUID,
+			PersonUUID: FieldMustUUID(c.PersonUuid),
+			Role:       CreditRole(c.Role.MustGet()),
+		}
+		FieldSetPtr(c.CharacterName, &credit.CharacterName)
+		FieldSetPtr(c.BillingOrder, &credit.BillingOrder)
+
+		key := creditKey{credit.PersonUUID, credit.Role}
+		if seen[key] {
+			return nil, NewFieldError(fmt.Sprintf("credits[%d]", i), ErrDuplicate)
+		}
+		seen[key] = true
+		out = append(out, credit)
+	}
+	return out, nil
+}
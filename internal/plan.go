@@ -1,7 +1,13 @@
 package internal
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/krelinga/video-catalog/vcrest"
 	"github.com/oapi-codegen/nullable"
 	openapi_types "github.com/oapi-codegen/runtime/types"
@@ -57,3 +63,51 @@ func (p *ChapterRangePlan) ToAPI() *vcrest.ChapterRangePlan {
 	}
 	return result
 }
+
+// PlanToAPI converts a row from the plans table to its API representation.
+func PlanToAPI(id uuid.UUID, kind PlanKind, body json.RawMessage) (*vcrest.Plan, error) {
+	result := &vcrest.Plan{
+		Uuid: openapi_types.UUID(id),
+	}
+	switch kind {
+	case PlanKindDirect:
+		var directBody DirectPlan
+		if err := json.Unmarshal(body, &directBody); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal direct plan body: %w", err)
+		}
+		result.Direct = directBody.ToAPI()
+	case PlanKindChapterRange:
+		var chapterRangeBody ChapterRangePlan
+		if err := json.Unmarshal(body, &chapterRangeBody); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chapter range plan body: %w", err)
+		}
+		result.ChapterRange = chapterRangeBody.ToAPI()
+	default:
+		return nil, fmt.Errorf("unimplemented plan kind: %s", kind)
+	}
+	return result, nil
+}
+
+// SetPlanCompleted marks a plan as complete, or clears its completion time.  A plan that is already in the
+// requested state is left alone, so that its version does not change.  Returns ErrNotFound if the plan does
+// not exist in the library of ctx or is in the trash.
+func SetPlanCompleted(ctx context.Context, tx pgx.Tx, id uuid.UUID, completed bool) error {
+	var one int
+	err := tx.QueryRow(ctx, `
+		SELECT 1 FROM plans WHERE uuid = $1 AND deleted_at IS NULL AND `+LibraryCondition("library_uuid", 2)+` FOR UPDATE`,
+		id, LibraryFromContext(ctx)).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to query plan: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE plans
+		SET completed_at = CASE WHEN $2 THEN now() END
+		WHERE uuid = $1 AND (completed_at IS NULL) = $2`, id, completed)
+	if err != nil {
+		return fmt.Errorf("failed to update plan: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// Conditions that select the rows of each report.  Each is applied to a live row t of the table that the
+// report lists, and only counts plans outside the trash.
+const (
+	unplannedWorksCondition = `NOT EXISTS (
+		SELECT 1 FROM plan_outputs po
+		INNER JOIN plans p ON p.uuid = po.plan_uuid
+		WHERE po.work_uuid = t.uuid AND p.deleted_at IS NULL)`
+
+	// A disc whose files are consumed is planned, even though no plan consumes the disc itself.
+	unplannedSourcesCondition = `NOT EXISTS (
+		SELECT 1 FROM plan_inputs pi
+		INNER JOIN plans p ON p.uuid = pi.plan_uuid
+		INNER JOIN sources s ON s.uuid = pi.source_uuid
+		WHERE (s.uuid = t.uuid OR s.parent_uuid = t.uuid)
+			AND p.deleted_at IS NULL AND s.deleted_at IS NULL)`
+
+	incompleteDiscsCondition = `t.kind = $3
+		AND NOT (t.body->>'allFilesAdded')::boolean
+		AND t.created_at <= $4`
+
+	incompleteWorksCondition = `EXISTS (
+		SELECT 1 FROM plan_outputs po
+		INNER JOIN plans p ON p.uuid = po.plan_uuid
+		WHERE po.work_uuid = t.uuid AND p.deleted_at IS NULL AND p.completed_at IS NULL)`
+)
+
+// reportRows reads up to limit live rows of an entity table (works, sources) in the library of ctx that
+// match condition and sort after the given UUID, in UUID order.  Any args are passed to condition starting
+// at $3.
+func reportRows(ctx context.Context, tx pgx.Tx, table, condition string, after uuid.UUID, limit int, args ...any) ([]entityRow, error) {
+	libraryParam := 3 + len(args)
+	query := fmt.Sprintf(`
+		SELECT t.uuid, t.kind, t.body
+		FROM %s t
+		WHERE t.deleted_at IS NULL AND t.uuid > $1 AND %s AND %s
+		ORDER BY t.uuid
+		LIMIT $2`, table, condition, LibraryCondition("t.library_uuid", libraryParam))
+	args = append([]any{after, limit}, args...)
+	rows, err := tx.Query(ctx, query, append(args, LibraryFromContext(ctx))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+
+	var result []entityRow
+	var row entityRow
+	_, err = pgx.ForEachRow(rows, []any{&row.ID, &row.Kind, &row.Body}, func() error {
+		result = append(result, row)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", table, err)
+	}
+	return result, nil
+}
+
+// UnplannedWorks returns up to limit works, after the given UUID, that no plan outputs.
+func UnplannedWorks(ctx context.Context, tx pgx.Tx, after uuid.UUID, limit int) ([]*vcrest.Work, error) {
+	rows, err := reportRows(ctx, tx, "works", unplannedWorksCondition, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return worksFromRows(rows)
+}
+
+// UnplannedSources returns up to limit sources, after the given UUID, that no plan consumes.
+func UnplannedSources(ctx context.Context, tx pgx.Tx, after uuid.UUID, limit int) ([]*vcrest.Source, error) {
+	rows, err := reportRows(ctx, tx, "sources", unplannedSourcesCondition, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return sourcesFromRows(rows)
+}
+
+// IncompleteDiscs returns up to limit discs, after the given UUID, that were created no later than
+// createdBefore and do not have all of their files added.
+func IncompleteDiscs(ctx context.Context, tx pgx.Tx, createdBefore time.Time, after uuid.UUID, limit int) ([]*vcrest.Source, error) {
+	rows, err := reportRows(ctx, tx, "sources", incompleteDiscsCondition, after, limit, SourceKindDisc, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+	return sourcesFromRows(rows)
+}
+
+// IncompleteWorks returns up to limit works, after the given UUID, that some plan outputs but has not
+// completed.
+func IncompleteWorks(ctx context.Context, tx pgx.Tx, after uuid.UUID, limit int) ([]*vcrest.Work, error) {
+	rows, err := reportRows(ctx, tx, "works", incompleteWorksCondition, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return worksFromRows(rows)
+}
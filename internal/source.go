@@ -1,8 +1,13 @@
 package internal
 
 import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
 	"github.com/krelinga/video-catalog/vcrest"
 	"github.com/oapi-codegen/nullable"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 type SourceKind string
@@ -22,14 +27,19 @@ func (k SourceKind) IsValid() bool {
 }
 
 type FileSource struct {
-	Path string `json:"path"`
+	Path     string     `json:"path"`
+	DiscUUID *uuid.UUID `json:"parentUuid,omitempty"`
 }
 
 // ToAPI converts the FileSource to its API representation.
 func (s *FileSource) ToAPI() *vcrest.File {
-	return &vcrest.File{
+	result := &vcrest.File{
 		Path: nullable.NewNullableWithValue(s.Path),
 	}
+	if s.DiscUUID != nil {
+		result.DiscUuid = nullable.NewNullableWithValue(openapi_types.UUID(*s.DiscUUID))
+	}
+	return result
 }
 
 type DiscSource struct {
@@ -46,3 +56,27 @@ func (s *DiscSource) ToAPI() *vcrest.Disc {
 		AllFilesAdded: nullable.NewNullableWithValue(s.AllFilesAdded),
 	}
 }
+
+// SourceToAPI converts a row from the sources table to its API representation.
+func SourceToAPI(id uuid.UUID, kind SourceKind, body json.RawMessage) (*vcrest.Source, error) {
+	result := &vcrest.Source{
+		Uuid: openapi_types.UUID(id),
+	}
+	switch kind {
+	case SourceKindFile:
+		var fileBody FileSource
+		if err := json.Unmarshal(body, &fileBody); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal file source body: %w", err)
+		}
+		result.File = fileBody.ToAPI()
+	case SourceKindDisc:
+		var discBody DiscSource
+		if err := json.Unmarshal(body, &discBody); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal disc source body: %w", err)
+		}
+		result.Disc = discBody.ToAPI()
+	default:
+		return nil, fmt.Errorf("unimplemented source kind: %s", kind)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// RefreshStats recomputes the library_stats materialized view.  Readers are not blocked while it runs.
+func RefreshStats(ctx context.Context, e Execer) error {
+	if _, err := e.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY library_stats`); err != nil {
+		return fmt.Errorf("failed to refresh library stats: %w", err)
+	}
+	return nil
+}
+
+// LoadStats returns the API representation of the library_stats materialized view, for the library of ctx.
+func LoadStats(ctx context.Context, tx pgx.Tx) (*vcrest.Stats, error) {
+	stats := &vcrest.Stats{
+		WorkKinds:     []vcrest.KindCount{},
+		SourceKinds:   []vcrest.KindCount{},
+		PlanKinds:     []vcrest.KindCount{},
+		WorksByDecade: []vcrest.DecadeCount{},
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT category, key, value, refreshed_at
+		FROM library_stats
+		WHERE library_uuid = $1
+		ORDER BY category, key`, writeLibrary(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query library stats: %w", err)
+	}
+
+	var category, key string
+	var value *int64
+	var refreshedAt time.Time
+	_, err = pgx.ForEachRow(rows, []any{&category, &key, &value, &refreshedAt}, func() error {
+		stats.RefreshedAt = refreshedAt
+		if value == nil {
+			// Totals over no known values are NULL.
+			return nil
+		}
+		switch category {
+		case "work_kind":
+			stats.WorkKinds = append(stats.WorkKinds, vcrest.KindCount{Kind: key, Count: *value})
+		case "source_kind":
+			stats.SourceKinds = append(stats.SourceKinds, vcrest.KindCount{Kind: key, Count: *value})
+		case "plan_kind":
+			stats.PlanKinds = append(stats.PlanKinds, vcrest.KindCount{Kind: key, Count: *value})
+		case "decade":
+			decade, err := strconv.ParseInt(key, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid decade in library stats: %q", key)
+			}
+			stats.WorksByDecade = append(stats.WorksByDecade, vcrest.DecadeCount{Decade: int32(decade), Count: *value})
+		case "missing_tmdb_id":
+			switch key {
+			case "work":
+				stats.MissingTmdbId.Works = *value
+			case "person":
+				stats.MissingTmdbId.Persons = *value
+			}
+		case "media":
+			switch key {
+			case "size_bytes":
+				stats.TotalSizeBytes = value
+			case "duration_seconds":
+				stats.TotalDurationSeconds = value
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan library stats: %w", err)
+	}
+
+	// Decades are keyed by text, so they are put in numeric order here.
+	slices.SortFunc(stats.WorksByDecade, func(a, b vcrest.DecadeCount) int {
+		return cmp.Compare(a.Decade, b.Decade)
+	})
+	return stats, nil
+}
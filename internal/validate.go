@@ -11,13 +11,43 @@ import (
 // Sentinel errors for validation functions.
 // Callers should use these to construct field-specific error messages.
 var (
-	ErrRequired    = errors.New("is required")
-	ErrInvalidUUID = errors.New("invalid UUID format")
-	ErrEmpty       = errors.New("cannot be empty")
-	ErrNull        = errors.New("cannot be null")
-	ErrNullOrEmpty = errors.New("cannot be null or empty")
+	ErrRequired     = errors.New("is required")
+	ErrInvalidUUID  = errors.New("invalid UUID format")
+	ErrEmpty        = errors.New("cannot be empty")
+	ErrNull         = errors.New("cannot be null")
+	ErrNullOrEmpty  = errors.New("cannot be null or empty")
+	ErrInvalid      = errors.New("is not a valid value")
+	ErrDuplicate    = errors.New("is duplicated")
+	ErrNegative     = errors.New("cannot be negative")
+	ErrWrongType    = errors.New("has the wrong type")
+	ErrUnknownField = errors.New("is not a known field")
 )
 
+// FieldErrorCode returns the API error code for a field that failed validation with err.
+// Errors that do not wrap one of the sentinels above are reported as invalid values.
+func FieldErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrRequired):
+		return CodeRequired
+	case errors.Is(err, ErrInvalidUUID):
+		return CodeInvalidUUID
+	case errors.Is(err, ErrNull):
+		return CodeNull
+	case errors.Is(err, ErrEmpty), errors.Is(err, ErrNullOrEmpty):
+		return CodeEmpty
+	case errors.Is(err, ErrDuplicate):
+		return CodeDuplicate
+	case errors.Is(err, ErrMissingReference):
+		return CodeReferenceMissing
+	case errors.Is(err, ErrWrongType):
+		return CodeWrongType
+	case errors.Is(err, ErrUnknownField):
+		return CodeUnknownField
+	default:
+		return CodeInvalidValue
+	}
+}
+
 // FieldRequired checks that the field is specified.
 func FieldRequired[T any](field nullable.Nullable[T]) error {
 	if !field.IsSpecified() {
@@ -142,4 +172,4 @@ func FieldSetPtr[T any](field nullable.Nullable[T], out **T) {
 	}
 	val := field.MustGet()
 	*out = &val
-}
\ No newline at end of file
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Version and Commit identify the build.  Release builds set them with -ldflags, as the Dockerfile does.
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// BuildCommit returns the git commit that the server was built from.  If Commit is not set, the commit that
+// go build recorded is used, so that builds from a checkout are identified too.
+func BuildCommit() string {
+	if Commit != "" {
+		return Commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
+
+// SchemaVersion returns the version of the newest embedded migration, which is the version of the database
+// schema that MigrateUp migrates to.
+func SchemaVersion() (uint, error) {
+	names, err := fs.Glob(migrationsFS, "migrations/*.up.sql")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	var latest uint
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(strings.TrimPrefix(name, "migrations/"), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse version of migration %s: %w", name, err)
+		}
+		latest = max(latest, uint(version))
+	}
+	return latest, nil
+}
+
+// DatabaseSchemaVersion returns the version of the newest migration applied to the database, and whether
+// it failed part way.  Returns version 0 if no migration has been applied.
+func DatabaseSchemaVersion(ctx context.Context, q Querier) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := q.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, fmt.Errorf("failed to query schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
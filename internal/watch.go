@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// MaxRating is the highest number of stars that a user can rate a work.
+const MaxRating = 5
+
+// watchStateColumns are the columns of watch_states that watchStateTargets scans.
+const watchStateColumns = `watched, play_count, last_watched_at, rating, on_watchlist, updated_at`
+
+// WatchFilter selects the works listed by ListWatchedWorks.  Nil fields do not filter.
+type WatchFilter struct {
+	Watched     *bool
+	OnWatchlist *bool
+	Kind        *WorkKind
+	MinRating   *int32
+	// Completed selects works that some completed plan outputs, that is works that are in the collection.
+	Completed *bool
+}
+
+// ValidateWatchState checks that a watch state can be recorded.
+func ValidateWatchState(in *vcrest.WatchStateInput) error {
+	if in.PlayCount < 0 {
+		return NewFieldError("playCount", ErrNegative)
+	}
+	if in.Rating != nil && (*in.Rating < 1 || *in.Rating > MaxRating) {
+		return NewFieldError("rating", fmt.Errorf("%w, expected 1 to %d stars", ErrInvalid, MaxRating))
+	}
+	return nil
+}
+
+// watchStateTargets returns the scan targets for watchStateColumns.
+func watchStateTargets(state *vcrest.WatchState) []any {
+	return []any{&state.Watched, &state.PlayCount, &state.LastWatchedAt, &state.Rating, &state.OnWatchlist, &state.UpdatedAt}
+}
+
+// checkWork returns ErrNotFound if the work does not exist outside the trash in the library of ctx.
+func checkWork(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM works WHERE uuid = $1 AND deleted_at IS NULL AND ` + LibraryCondition("library_uuid", 2) + `)`
+	if err := tx.QueryRow(ctx, query, id, LibraryFromContext(ctx)).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to query works: %w", err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetWatchState returns a user's watch state of a work, which is unwatched if the user never recorded one.
+// Returns ErrNotFound if the work does not exist.
+func GetWatchState(ctx context.Context, tx pgx.Tx, userID string, workID uuid.UUID) (*vcrest.WatchState, error) {
+	if err := checkWork(ctx, tx, workID); err != nil {
+		return nil, err
+	}
+	state := &vcrest.WatchState{WorkUuid: openapi_types.UUID(workID)}
+	err := tx.QueryRow(ctx, `
+		SELECT `+watchStateColumns+`
+		FROM watch_states
+		WHERE user_id = $1 AND work_uuid = $2`, userID, workID).Scan(watchStateTargets(state)...)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to query watch state: %w", err)
+	}
+	return state, nil
+}
+
+// PutWatchState replaces a user's watch state of a work.  Returns ErrNotFound if the work does not exist.
+func PutWatchState(ctx context.Context, tx pgx.Tx, userID string, workID uuid.UUID, in *vcrest.WatchStateInput) (*vcrest.WatchState, error) {
+	if err := checkWork(ctx, tx, workID); err != nil {
+		return nil, err
+	}
+	state := &vcrest.WatchState{WorkUuid: openapi_types.UUID(workID)}
+	err := tx.QueryRow(ctx, `
+		INSERT INTO watch_states (user_id, work_uuid, watched, play_count, last_watched_at, rating, on_watchlist)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, work_uuid) DO UPDATE
+		SET watched = EXCLUDED.watched, play_count = EXCLUDED.play_count, last_watched_at = EXCLUDED.last_watched_at,
+			rating = EXCLUDED.rating, on_watchlist = EXCLUDED.on_watchlist, updated_at = now()
+		RETURNING `+watchStateColumns,
+		userID, workID, in.Watched, in.PlayCount, in.LastWatchedAt, in.Rating, in.OnWatchlist).Scan(watchStateTargets(state)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert watch state: %w", err)
+	}
+	return state, nil
+}
+
+// RecordPlay records that a user watched a work at the given time: the work becomes watched, its play count
+// goes up by one, and its last watched time moves forward to at if that is later.  Returns ErrNotFound if the
+// work does not exist.
+func RecordPlay(ctx context.Context, tx pgx.Tx, userID string, workID uuid.UUID, at time.Time) (*vcrest.WatchState, error) {
+	if err := checkWork(ctx, tx, workID); err != nil {
+		return nil, err
+	}
+	state := &vcrest.WatchState{WorkUuid: openapi_types.UUID(workID)}
+	err := tx.QueryRow(ctx, `
+		INSERT INTO watch_states (user_id, work_uuid, watched, play_count, last_watched_at)
+		VALUES ($1, $2, true, 1, $3)
+		ON CONFLICT (user_id, work_uuid) DO UPDATE
+		SET watched = true, play_count = watch_states.play_count + 1,
+			last_watched_at = GREATEST(watch_states.last_watched_at, EXCLUDED.last_watched_at), updated_at = now()
+		RETURNING `+watchStateColumns, userID, workID, at).Scan(watchStateTargets(state)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record play: %w", err)
+	}
+	return state, nil
+}
+
+// DeleteWatchState forgets a user's watch state of a work.  Forgetting a state that was never recorded
+// succeeds.  Returns ErrNotFound if the work does not exist.
+func DeleteWatchState(ctx context.Context, tx pgx.Tx, userID string, workID uuid.UUID) error {
+	if err := checkWork(ctx, tx, workID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `DELETE FROM watch_states WHERE user_id = $1 AND work_uuid = $2`, userID, workID)
+	if err != nil {
+		return fmt.Errorf("failed to delete watch state: %w", err)
+	}
+	return nil
+}
+
+// ListWatchedWorks returns up to limit live works in the library of ctx, after the given UUID and in UUID
+// order, that match filter, along with the user's watch state of each.
+func ListWatchedWorks(ctx context.Context, tx pgx.Tx, userID string, filter WatchFilter, after uuid.UUID, limit int) ([]vcrest.WatchedWork, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT w.uuid, w.kind, w.body, COALESCE(ws.watched, false), COALESCE(ws.play_count, 0), ws.last_watched_at,
+			ws.rating, COALESCE(ws.on_watchlist, false), ws.updated_at
+		FROM works w
+		LEFT JOIN watch_states ws ON ws.work_uuid = w.uuid AND ws.user_id = $1
+		WHERE w.deleted_at IS NULL AND w.uuid > $2 AND `+LibraryCondition("w.library_uuid", 4)+`
+			AND ($5::boolean IS NULL OR COALESCE(ws.watched, false) = $5)
+			AND ($6::boolean IS NULL OR COALESCE(ws.on_watchlist, false) = $6)
+			AND ($7::varchar IS NULL OR w.kind = $7)
+			AND ($8::integer IS NULL OR ws.rating >= $8)
+			AND ($9::boolean IS NULL OR EXISTS (
+				SELECT 1 FROM plan_outputs po
+				INNER JOIN plans p ON p.uuid = po.plan_uuid
+				WHERE po.work_uuid = w.uuid AND p.deleted_at IS NULL AND p.completed_at IS NOT NULL) = $9)
+		ORDER BY w.uuid
+		LIMIT $3`,
+		userID, after, limit, LibraryFromContext(ctx), filter.Watched, filter.OnWatchlist, filter.Kind, filter.MinRating, filter.Completed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watched works: %w", err)
+	}
+
+	result := []vcrest.WatchedWork{}
+	var row entityRow
+	var state vcrest.WatchState
+	_, err = pgx.ForEachRow(rows, append([]any{&row.ID, &row.Kind, &row.Body}, watchStateTargets(&state)...), func() error {
+		work, err := WorkToAPI(row.ID, WorkKind(row.Kind), row.Body)
+		if err != nil {
+			return err
+		}
+		state.WorkUuid = openapi_types.UUID(row.ID)
+		result = append(result, vcrest.WatchedWork{Work: *work, WatchState: state})
+		// Clear the pointers, so that the next row is not scanned into the values of this one.
+		state = vcrest.WatchState{}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan watched works: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,294 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// WebhookEvents are the events that webhooks can subscribe to.  They are raised by triggers in the
+// database, so that every write path raises them.
+var WebhookEvents = []string{
+	"work.created", "work.updated", "work.deleted",
+	"source.created", "source.updated", "source.deleted",
+	"plan.created", "plan.updated", "plan.deleted",
+	"plan.completed", "plan.reopened",
+}
+
+// Headers sent with each webhook delivery.
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// Statuses of a webhook delivery.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// ValidateWebhook checks that a webhook subscription can be stored.
+func ValidateWebhook(in *vcrest.WebhookInput) error {
+	u, err := url.Parse(in.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return NewFieldError("url", fmt.Errorf("%w, expected an absolute http or https URL", ErrInvalid))
+	}
+	if in.Secret == "" {
+		return NewFieldError("secret", ErrEmpty)
+	}
+	for i, event := range in.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return NewFieldError(fmt.Sprintf("events[%d]", i), fmt.Errorf("%w, unknown event %q", ErrInvalid, event))
+		}
+	}
+	return nil
+}
+
+// CreateWebhook stores a new webhook subscription and returns its API representation.
+func CreateWebhook(ctx context.Context, q Querier, in *vcrest.WebhookInput) (*vcrest.Webhook, error) {
+	events := in.Events
+	if events == nil {
+		events = []string{}
+	}
+	webhook := &vcrest.Webhook{
+		Uuid:   openapi_types.UUID(uuid.New()),
+		Url:    in.Url,
+		Events: events,
+	}
+	err := q.QueryRow(ctx, `
+		INSERT INTO webhooks (uuid, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at`, uuid.UUID(webhook.Uuid), in.Url, in.Secret, events).Scan(&webhook.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// GetWebhook returns the API representation of a webhook subscription.  Returns ErrNotFound if the webhook
+// does not exist.
+func GetWebhook(ctx context.Context, q Querier, id uuid.UUID) (*vcrest.Webhook, error) {
+	webhook := &vcrest.Webhook{
+		Uuid: openapi_types.UUID(id),
+	}
+	err := q.QueryRow(ctx, `
+		SELECT url, events, created_at
+		FROM webhooks
+		WHERE uuid = $1`, id).Scan(&webhook.Url, &webhook.Events, &webhook.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// ListWebhooks returns the API representations of all webhook subscriptions, ordered by UUID.
+func ListWebhooks(ctx context.Context, tx pgx.Tx) ([]vcrest.Webhook, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT uuid, url, events, created_at
+		FROM webhooks
+		ORDER BY uuid`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+
+	webhooks := []vcrest.Webhook{}
+	var webhook vcrest.Webhook
+	var id uuid.UUID
+	_, err = pgx.ForEachRow(rows, []any{&id, &webhook.Url, &webhook.Events, &webhook.CreatedAt}, func() error {
+		webhook.Uuid = openapi_types.UUID(id)
+		webhooks = append(webhooks, webhook)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook subscription and its deliveries.  Returns ErrNotFound if the webhook does
+// not exist.
+func DeleteWebhook(ctx context.Context, e Execer, id uuid.UUID) error {
+	tag, err := e.Exec(ctx, `DELETE FROM webhooks WHERE uuid = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	} else if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns up to limit deliveries to a webhook with IDs below before, newest first.
+// A before of zero starts from the newest delivery.
+func ListWebhookDeliveries(ctx context.Context, tx pgx.Tx, webhookID uuid.UUID, before int64, limit int) ([]vcrest.WebhookDelivery, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT id, event, payload, status, attempts, last_status_code, last_error, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE webhook_uuid = $1 AND ($2::bigint = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3`, webhookID, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+
+	deliveries := []vcrest.WebhookDelivery{}
+	var d vcrest.WebhookDelivery
+	var payload json.RawMessage
+	_, err = pgx.ForEachRow(rows, []any{&d.Id, &d.Event, &payload, &d.Status, &d.Attempts, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt}, func() error {
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+		// Start the next row afresh, so that it does not share pointers with this one.
+		d, payload = vcrest.WebhookDelivery{}, nil
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// SignWebhook returns the signature of a webhook delivery: sha256= followed by the hex HMAC-SHA256, keyed
+// by the secret, of the timestamp in Unix seconds, a period and the body.  Receivers recompute it to check
+// that a delivery is genuine, and can reject old timestamps to stop replays.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookDeliveryRequest is what is needed to send one delivery.
+type WebhookDeliveryRequest struct {
+	ID      int64
+	URL     string
+	Secret  string
+	Event   string
+	Payload json.RawMessage
+}
+
+// SendWebhook posts a delivery to its webhook.  It returns the HTTP status of the response, or zero if
+// there was none, and an error unless the status was 2xx.
+func SendWebhook(ctx context.Context, client *http.Client, d *WebhookDeliveryRequest, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, d.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(d.Secret, now, d.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// loadWebhookDelivery reads what is needed to send a delivery.  Returns ErrNotFound if the delivery is gone,
+// which happens when its webhook is deleted.
+func loadWebhookDelivery(ctx context.Context, q Querier, id int64) (*WebhookDeliveryRequest, error) {
+	d := &WebhookDeliveryRequest{ID: id}
+	err := q.QueryRow(ctx, `
+		SELECT w.url, w.secret, d.event, d.payload
+		FROM webhook_deliveries d
+		INNER JOIN webhooks w ON w.uuid = d.webhook_uuid
+		WHERE d.id = $1`, id).Scan(&d.URL, &d.Secret, &d.Event, &d.Payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+// recordWebhookAttempt updates the delivery log after an attempt.  statusCode is zero if there was no
+// response, and sendErr is nil if the attempt succeeded.  If final is set, a failed attempt was the last.
+func recordWebhookAttempt(ctx context.Context, e Execer, id int64, statusCode int, sendErr error, final bool) error {
+	status := WebhookDeliveryPending
+	var lastError *string
+	if sendErr == nil {
+		status = WebhookDeliverySucceeded
+	} else {
+		lastError = nullIfEmpty(sendErr.Error())
+		if final {
+			status = WebhookDeliveryFailed
+		}
+	}
+	var lastStatusCode *int
+	if statusCode != 0 {
+		lastStatusCode = &statusCode
+	}
+	_, err := e.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, status = $2, last_status_code = $3, last_error = $4,
+			delivered_at = CASE WHEN $2 = 'succeeded' THEN now() END
+		WHERE id = $1`, id, status, lastStatusCode, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// claimWebhookDeliveries marks up to limit deliveries that do not have a job yet as enqueued, and returns
+// their IDs.  Deliveries claimed by a concurrent transaction are skipped.
+func claimWebhookDeliveries(ctx context.Context, tx pgx.Tx, limit int) ([]int64, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE webhook_deliveries
+		SET enqueued = true
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE NOT enqueued
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING id`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan webhook deliveries: %w", err)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// PurgeWebhookDeliveries removes finished deliveries created before the given time, returning the number
+// removed.
+func PurgeWebhookDeliveries(ctx context.Context, e Execer, before time.Time) (int64, error) {
+	tag, err := e.Exec(ctx, `
+		DELETE FROM webhook_deliveries
+		WHERE created_at < $1 AND status <> 'pending'`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
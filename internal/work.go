@@ -1,8 +1,14 @@
 package internal
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
 	"github.com/krelinga/video-catalog/vcrest"
 	"github.com/oapi-codegen/nullable"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 type WorkKind string
@@ -22,9 +28,12 @@ func (k WorkKind) IsValid() bool {
 }
 
 type MovieWork struct {
-	Title       string `json:"title"`
-	ReleaseYear *int32   `json:"releaseYear,omitempty"`
-	TmdbId      *int32   `json:"tmdbId,omitempty"`
+	Title           string           `json:"title"`
+	OriginalTitle   *string          `json:"originalTitle,omitempty"`
+	SortTitle       *string          `json:"sortTitle,omitempty"`
+	AlternateTitles []AlternateTitle `json:"alternateTitles,omitempty"`
+	ReleaseYear     *int32           `json:"releaseYear,omitempty"`
+	TmdbId          *int32           `json:"tmdbId,omitempty"`
 }
 
 // ToAPI converts the MovieWork to its API representation.
@@ -32,6 +41,19 @@ func (w *MovieWork) ToAPI() *vcrest.Movie {
 	result := &vcrest.Movie{
 		Title: nullable.NewNullableWithValue(w.Title),
 	}
+	if w.OriginalTitle != nil {
+		result.OriginalTitle = nullable.NewNullableWithValue(*w.OriginalTitle)
+	}
+	if w.SortTitle != nil {
+		result.SortTitle = nullable.NewNullableWithValue(*w.SortTitle)
+	}
+	if len(w.AlternateTitles) > 0 {
+		alternateTitles := make([]vcrest.AlternateTitle, 0, len(w.AlternateTitles))
+		for _, alt := range w.AlternateTitles {
+			alternateTitles = append(alternateTitles, *alt.ToAPI())
+		}
+		result.AlternateTitles = nullable.NewNullableWithValue(alternateTitles)
+	}
 	if w.ReleaseYear != nil {
 		result.ReleaseYear = nullable.NewNullableWithValue(*w.ReleaseYear)
 	}
@@ -41,13 +63,90 @@ func (w *MovieWork) ToAPI() *vcrest.Movie {
 	return result
 }
 
+type AlternateTitle struct {
+	Title    string  `json:"title"`
+	Language *string `json:"language,omitempty"`
+	Region   *string `json:"region,omitempty"`
+}
+
+// ToAPI converts the AlternateTitle to its API representation.
+func (t *AlternateTitle) ToAPI() *vcrest.AlternateTitle {
+	result := &vcrest.AlternateTitle{
+		Title: nullable.NewNullableWithValue(t.Title),
+	}
+	if t.Language != nil {
+		result.Language = nullable.NewNullableWithValue(*t.Language)
+	}
+	if t.Region != nil {
+		result.Region = nullable.NewNullableWithValue(*t.Region)
+	}
+	return result
+}
+
+// AlternateTitlesFromAPI validates and converts a list of alternate titles from their API representation.
+// Returned errors are prefixed with the name and index of the offending field.
+func AlternateTitlesFromAPI(in []vcrest.AlternateTitle) ([]AlternateTitle, error) {
+	out := make([]AlternateTitle, 0, len(in))
+	for i, alt := range in {
+		if err := errors.Join(
+			FieldRequired(alt.Title),
+			FieldNotNull(alt.Title),
+			FieldNotEmpty(alt.Title),
+		); err != nil {
+			return nil, NewFieldError(fmt.Sprintf("alternateTitles[%d].title", i), err)
+		}
+		if err := FieldNotEmpty(alt.Language); err != nil {
+			return nil, NewFieldError(fmt.Sprintf("alternateTitles[%d].language", i), err)
+		}
+		if err := FieldNotEmpty(alt.Region); err != nil {
+			return nil, NewFieldError(fmt.Sprintf("alternateTitles[%d].region", i), err)
+		}
+		title := AlternateTitle{
+			Title: alt.Title.MustGet(),
+		}
+		FieldSetPtr(alt.Language, &title.Language)
+		FieldSetPtr(alt.Region, &title.Region)
+		out = append(out, title)
+	}
+	return out, nil
+}
+
 type MovieEditionWork struct {
-	EditionType string `json:"editionType"`
+	EditionType string     `json:"editionType"`
+	MovieUUID   *uuid.UUID `json:"parentUuid,omitempty"`
 }
 
 // ToAPI converts the MovieEditionWork to its API representation.
 func (w *MovieEditionWork) ToAPI() *vcrest.MovieEdition {
-	return &vcrest.MovieEdition{
+	result := &vcrest.MovieEdition{
 		EditionType: nullable.NewNullableWithValue(w.EditionType),
 	}
+	if w.MovieUUID != nil {
+		result.MovieUuid = nullable.NewNullableWithValue(openapi_types.UUID(*w.MovieUUID))
+	}
+	return result
+}
+
+// WorkToAPI converts a row from the works table to its API representation.
+func WorkToAPI(id uuid.UUID, kind WorkKind, body json.RawMessage) (*vcrest.Work, error) {
+	result := &vcrest.Work{
+		Uuid: openapi_types.UUID(id),
+	}
+	switch kind {
+	case WorkKindMovie:
+		var movieBody MovieWork
+		if err := json.Unmarshal(body, &movieBody); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal movie work body: %w", err)
+		}
+		result.Movie = movieBody.ToAPI()
+	case WorkKindMovieEdition:
+		var editionBody MovieEditionWork
+		if err := json.Unmarshal(body, &editionBody); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal movie edition work body: %w", err)
+		}
+		result.MovieEdition = editionBody.ToAPI()
+	default:
+		return nil, fmt.Errorf("unimplemented work kind: %s", kind)
+	}
+	return result, nil
 }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"sync"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+const headerAPIKey = "X-API-Key"
+
+// bootstrapPrincipal is the caller that uses the bootstrap API key from the configuration.
+var bootstrapPrincipal = internal.Principal{
+	ID:     "apikey:bootstrap",
+	Name:   "bootstrap",
+	Scopes: []string{internal.ScopeAdmin},
+}
+
+// statusError is returned by strict middleware to reject a request with a status other than 500.
+type statusError struct {
+	status int
+	body   vcrest.Error
+}
+
+func (e *statusError) Error() string {
+	return e.body.Message
+}
+
+// openPaths are the paths whose operations have no security requirements, such as the health checks.
+// Paths are matched exactly, so only paths without parameters can be open.
+var openPaths = sync.OnceValues(func() (map[string]bool, error) {
+	doc, err := vcrest.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API specification: %w", err)
+	}
+	open := map[string]bool{}
+	for path, item := range doc.Paths.Map() {
+		open[path] = true
+		for _, op := range item.Operations() {
+			if op.Security == nil || len(*op.Security) > 0 {
+				open[path] = false
+			}
+		}
+	}
+	return open, nil
+})
+
+// withAuthentication checks that the caller has an API key or bearer token, and adds the caller to the
+// context so that its changes are attributed to it.  It runs before anything else looks at the request,
+// so that callers without credentials learn nothing about the catalog or the shape of the API, not even
+// which paths and methods exist.  Only open paths can be requested without credentials.  Whether the
+// caller has the scopes that the operation needs is checked later, by authorize.  Requests that the server
+// makes to itself, for batches and patches, are made on behalf of the original caller.
+func (s *Server) withAuthentication(next http.Handler) http.Handler {
+	open, err := openPaths()
+	if err != nil {
+		panic(err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if _, ok := internal.PrincipalFromContext(ctx); ok || open[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := s.authenticateRequest(ctx, r)
+		if errors.Is(err, internal.ErrUnauthenticated) {
+			writeError(w, http.StatusUnauthorized, apiError(internal.CodeUnauthenticated, err.Error()))
+			return
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, err.Error()))
+			return
+		}
+		audit := internal.AuditFromContext(ctx)
+		audit.Actor = principal.ID
+		ctx = internal.WithAudit(internal.WithPrincipal(ctx, principal), audit)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authorize is strict middleware that checks that the caller has the scopes that the specification
+// requires for the operation.  Operations without security requirements are open to everyone.
+func (s *Server) authorize(f vcrest.StrictHandlerFunc, _ string) vcrest.StrictHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+		// Operations accept either scheme with the same scopes, so it does not matter which one the
+		// scopes are taken from.
+		scopes, secured := ctx.Value(vcrest.ApiKeyAuthScopes).([]string)
+		if !secured {
+			scopes, secured = ctx.Value(vcrest.BearerAuthScopes).([]string)
+		}
+		if !secured {
+			return f(ctx, w, r, request)
+		}
+
+		principal, ok := internal.PrincipalFromContext(ctx)
+		if !ok {
+			return nil, &statusError{
+				status: http.StatusUnauthorized,
+				body:   apiError(internal.CodeUnauthenticated, internal.ErrUnauthenticated.Error()),
+			}
+		}
+		for _, scope := range scopes {
+			if !principal.Allows(scope) {
+				return nil, &statusError{
+					status: http.StatusForbidden,
+					body:   apiError(internal.CodeForbidden, fmt.Sprintf("caller does not have the %s scope", scope)),
+				}
+			}
+		}
+		return f(ctx, w, r, request)
+	}
+}
+
+// authenticateRequest returns the principal that the credentials of a request belong to.  A bearer token
+// takes precedence over an API key.
+func (s *Server) authenticateRequest(ctx context.Context, r *http.Request) (internal.Principal, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return s.authenticateAPIKey(ctx, r.Header.Get(headerAPIKey))
+	}
+	if s.Tokens == nil {
+		return internal.Principal{}, fmt.Errorf("%w: bearer tokens are not accepted by this server", internal.ErrUnauthenticated)
+	}
+	return s.Tokens.Verify(ctx, strings.TrimSpace(token))
+}
+
+// authenticateAPIKey returns the principal that an API key belongs to.
+func (s *Server) authenticateAPIKey(ctx context.Context, key string) (internal.Principal, error) {
+	if key == "" {
+		return internal.Principal{}, internal.ErrUnauthenticated
+	}
+	if bootstrap := s.Config.BootstrapAPIKey; bootstrap != "" && subtle.ConstantTimeCompare([]byte(key), []byte(bootstrap)) == 1 {
+		return bootstrapPrincipal, nil
+	}
+	return internal.AuthenticateAPIKey(ctx, s.Pool, key)
+}
+
+// currentUserID returns the ID of the caller, which identifies the user whose watch state or idempotency keys
+// a request uses.  Every operation that uses it requires credentials, so the caller is always known.
+func currentUserID(ctx context.Context) (string, error) {
+	principal, ok := internal.PrincipalFromContext(ctx)
+	if !ok {
+		return "", errors.New("request has no authenticated caller")
+	}
+	return principal.ID, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// maxBatchOperations limits the size of a batch, since all of its changes are held in one transaction.
+const maxBatchOperations = 100
+
+// batchMethods are the methods of the operations that may be included in a batch.
+var batchMethods = map[string]bool{
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// ApplyBatch applies a list of changes in a single transaction
+func (s *Server) ApplyBatch(ctx context.Context, request vcrest.ApplyBatchRequestObject) (outResp vcrest.ApplyBatchResponseObject, _ error) {
+	// Validate request.
+	ops := request.Body.Operations
+	if len(ops) == 0 {
+		outResp = vcrest.ApplyBatch400JSONResponse(fieldError("operations", internal.ErrEmpty))
+		return
+	} else if len(ops) > maxBatchOperations {
+		outResp = vcrest.ApplyBatch400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
+			Message: fmt.Sprintf("batch must contain at most %d operations", maxBatchOperations),
+		}
+		return
+	}
+	for i, op := range ops {
+		if !batchMethods[op.Method] {
+			outResp = vcrest.ApplyBatch400JSONResponse(fieldError(fmt.Sprintf("operations[%d].method", i), fmt.Errorf("%w, expected PUT, PATCH or DELETE", internal.ErrInvalid)))
+			return
+		}
+		if !strings.HasPrefix(op.Path, "/") || strings.HasPrefix(op.Path, "//") {
+			outResp = vcrest.ApplyBatch400JSONResponse(fieldError(fmt.Sprintf("operations[%d].path", i), fmt.Errorf("%w, expected an absolute path", internal.ErrInvalid)))
+			return
+		}
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ApplyBatch500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Operations are served by the usual handlers, bound to the batch transaction.  The transactions they
+	// begin become savepoints, so a failed operation leaves the batch transaction usable until it is rolled back.
+	handler := (&Server{Config: s.Config, Pool: txn}).Handler()
+	results := make([]vcrest.BatchResult, 0, len(ops))
+	for _, op := range ops {
+		result, err := serveBatchOperation(ctx, handler, op)
+		if err != nil {
+			outResp = vcrest.ApplyBatch500JSONResponse{
+				Code:    internal.CodeInternal,
+				Message: err.Error(),
+			}
+			return
+		}
+		results = append(results, result)
+		if result.Error != nil {
+			outResp = vcrest.ApplyBatch422JSONResponse{
+				Results: results,
+			}
+			return
+		}
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ApplyBatch500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.ApplyBatch200JSONResponse{
+		Results: results,
+	}
+	return
+}
+
+// serveBatchOperation passes a single operation of a batch to handler as if it were an individual request.
+func serveBatchOperation(ctx context.Context, handler http.Handler, op vcrest.BatchOperation) (vcrest.BatchResult, error) {
+	var body []byte
+	if op.Body != nil {
+		var err error
+		body, err = json.Marshal(op.Body)
+		if err != nil {
+			return vcrest.BatchResult{}, fmt.Errorf("failed to marshal operation body: %w", err)
+		}
+	}
+	header := http.Header{}
+	if op.IfMatch != nil {
+		header.Set("If-Match", *op.IfMatch)
+	}
+	if op.IfNoneMatch != nil {
+		header.Set("If-None-Match", *op.IfNoneMatch)
+	}
+
+	w, err := serveInternal(ctx, handler, op.Method, op.Path, header, body)
+	if err != nil {
+		return vcrest.BatchResult{
+			Status: http.StatusBadRequest,
+			Error:  &vcrest.Error{Code: internal.CodeInvalidRequest, Message: fmt.Sprintf("invalid path: %v", err)},
+		}, nil
+	}
+
+	result := vcrest.BatchResult{
+		Status: int32(w.status),
+	}
+	if etag := w.header.Get("ETag"); etag != "" {
+		result.Etag = &etag
+	}
+	if w.status >= http.StatusBadRequest {
+		// Errors from the handlers are JSON, but errors from routing and request parsing are plain text.
+		var apiErr vcrest.Error
+		if err := json.Unmarshal(w.body.Bytes(), &apiErr); err != nil || apiErr.Message == "" {
+			apiErr = apiError(codeForStatus(w.status), strings.TrimSpace(w.body.String()))
+		}
+		result.Error = &apiErr
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// CompletePlan marks the plan with the given UUID as complete
+func (s *Server) CompletePlan(ctx context.Context, request vcrest.CompletePlanRequestObject) (outResp vcrest.CompletePlanResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.CompletePlan400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.CompletePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.SetPlanCompleted(ctx, txn, requestUuid, true)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.CompletePlan404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "plan not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.CompletePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.CompletePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.CompletePlan200Response{}
+	return
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// createOnly is the If-None-Match value that stops a PUT from overwriting an existing entity.
+var createOnly = "*"
+
+// idempotentCreate picks a UUID for a new entity and calls create to write it with srv, which reports whether
+// the entity was created.  If an idempotency key is given, the UUID is remembered under it, and a retry of the
+// same request returns the UUID of the entity created the first time without calling create again.  Keys
+// are only remembered for the caller and library that sent them.
+func (s *Server) idempotentCreate(ctx context.Context, operation string, key *string, body any, create func(srv *Server, id uuid.UUID) (bool, error)) (uuid.UUID, error) {
+	id := uuid.New()
+	if key == nil || *key == "" {
+		_, err := create(s, id)
+		return id, err
+	}
+
+	bodyRaw, err := json.Marshal(body)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	requestHash := sha256.Sum256(bodyRaw)
+	caller, err := currentUserID(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// The entity is created in the same transaction as the claim, so that one is never committed without
+	// the other, and retries racing with this request wait for it rather than creating a second entity.
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer txn.Rollback(ctx)
+
+	id, claimed, err := internal.ClaimIdempotencyKey(ctx, txn, caller, operation, *key, requestHash[:], id)
+	if err != nil || !claimed {
+		return id, err
+	}
+
+	created, err := create(&Server{Config: s.Config, Pool: txn}, id)
+	if err != nil || !created {
+		return id, err
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return id, nil
+}
+
+// createdWork reads back a work after it has been created.  Deleted works are included, since a retried
+// request should still see the work it created.
+func (s *Server) createdWork(ctx context.Context, id uuid.UUID) (*vcrest.GetWork200JSONResponse, error) {
+	includeDeleted := true
+	resp, err := s.GetWork(ctx, vcrest.GetWorkRequestObject{
+		Uuid:   openapi_types.UUID(id),
+		Params: vcrest.GetWorkParams{IncludeDeleted: &includeDeleted},
+	})
+	if err != nil {
+		return nil, err
+	}
+	work, ok := resp.(vcrest.GetWork200JSONResponse)
+	if !ok {
+		return nil, fmt.Errorf("failed to read created work: %+v", resp)
+	}
+	return &work, nil
+}
+
+// createdSource reads back a source after it has been created, like createdWork.
+func (s *Server) createdSource(ctx context.Context, id uuid.UUID) (*vcrest.GetSource200JSONResponse, error) {
+	includeDeleted := true
+	resp, err := s.GetSource(ctx, vcrest.GetSourceRequestObject{
+		Uuid:   openapi_types.UUID(id),
+		Params: vcrest.GetSourceParams{IncludeDeleted: &includeDeleted},
+	})
+	if err != nil {
+		return nil, err
+	}
+	source, ok := resp.(vcrest.GetSource200JSONResponse)
+	if !ok {
+		return nil, fmt.Errorf("failed to read created source: %+v", resp)
+	}
+	return &source, nil
+}
+
+// createdPlan reads back a plan after it has been created, like createdWork.
+func (s *Server) createdPlan(ctx context.Context, id uuid.UUID) (*vcrest.GetPlan200JSONResponse, error) {
+	includeDeleted := true
+	resp, err := s.GetPlan(ctx, vcrest.GetPlanRequestObject{
+		Uuid:   openapi_types.UUID(id),
+		Params: vcrest.GetPlanParams{IncludeDeleted: &includeDeleted},
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan, ok := resp.(vcrest.GetPlan200JSONResponse)
+	if !ok {
+		return nil, fmt.Errorf("failed to read created plan: %+v", resp)
+	}
+	return &plan, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// CreateApiKey issues a new API key
+func (s *Server) CreateApiKey(ctx context.Context, request vcrest.CreateApiKeyRequestObject) (outResp vcrest.CreateApiKeyResponseObject, _ error) {
+	// Validate request.
+	if err := internal.ValidateAPIKey(request.Body); err != nil {
+		outResp = vcrest.CreateApiKey400JSONResponse(invalidRequest(err))
+		return
+	}
+
+	created, err := internal.CreateAPIKey(ctx, s.Pool, request.Body)
+	if err != nil {
+		outResp = vcrest.CreateApiKey500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.CreateApiKey201JSONResponse(*created)
+	return
+}
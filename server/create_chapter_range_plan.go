@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// CreateChapterRangePlan adds a chapter range plan with a server-assigned UUID
+func (s *Server) CreateChapterRangePlan(ctx context.Context, request vcrest.CreateChapterRangePlanRequestObject) (outResp vcrest.CreateChapterRangePlanResponseObject, _ error) {
+	var putResp vcrest.PutChapterRangePlanResponseObject
+	id, err := s.idempotentCreate(ctx, "createChapterRangePlan", request.Params.IdempotencyKey, request.Body, func(srv *Server, id uuid.UUID) (bool, error) {
+		var err error
+		putResp, err = srv.PutChapterRangePlan(ctx, vcrest.PutChapterRangePlanRequestObject{
+			Uuid:   openapi_types.UUID(id),
+			Params: vcrest.PutChapterRangePlanParams{IfNoneMatch: &createOnly},
+			Body:   request.Body,
+		})
+		_, created := putResp.(vcrest.PutChapterRangePlan201Response)
+		return created, err
+	})
+	if errors.Is(err, internal.ErrIdempotencyKeyReused) {
+		outResp = vcrest.CreateChapterRangePlan422JSONResponse{
+			Code:    internal.CodeIdempotencyKeyReused,
+			Message: err.Error(),
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.CreateChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to create plan: %v", err),
+		}
+		return
+	}
+
+	switch r := putResp.(type) {
+	case nil, vcrest.PutChapterRangePlan201Response:
+		// Created by this request, or by an earlier one with the same idempotency key.
+	case vcrest.PutChapterRangePlan400JSONResponse:
+		outResp = vcrest.CreateChapterRangePlan400JSONResponse(r)
+		return
+	case vcrest.PutChapterRangePlan409JSONResponse:
+		outResp = vcrest.CreateChapterRangePlan409JSONResponse(r)
+		return
+	case vcrest.PutChapterRangePlan500JSONResponse:
+		outResp = vcrest.CreateChapterRangePlan500JSONResponse(r)
+		return
+	default:
+		outResp = vcrest.CreateChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("unexpected response creating plan: %+v", r),
+		}
+		return
+	}
+
+	created, err := s.createdPlan(ctx, id)
+	if err != nil {
+		outResp = vcrest.CreateChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.CreateChapterRangePlan201JSONResponse{
+		Body: created.Body,
+		Headers: vcrest.CreateChapterRangePlan201ResponseHeaders{
+			ETag:     created.Headers.ETag,
+			Location: "/plans/" + id.String(),
+		},
+	}
+	return
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// CreateDirectPlan adds a direct plan with a server-assigned UUID
+func (s *Server) CreateDirectPlan(ctx context.Context, request vcrest.CreateDirectPlanRequestObject) (outResp vcrest.CreateDirectPlanResponseObject, _ error) {
+	var putResp vcrest.PutDirectPlanResponseObject
+	id, err := s.idempotentCreate(ctx, "createDirectPlan", request.Params.IdempotencyKey, request.Body, func(srv *Server, id uuid.UUID) (bool, error) {
+		var err error
+		putResp, err = srv.PutDirectPlan(ctx, vcrest.PutDirectPlanRequestObject{
+			Uuid:   openapi_types.UUID(id),
+			Params: vcrest.PutDirectPlanParams{IfNoneMatch: &createOnly},
+			Body:   request.Body,
+		})
+		_, created := putResp.(vcrest.PutDirectPlan201Response)
+		return created, err
+	})
+	if errors.Is(err, internal.ErrIdempotencyKeyReused) {
+		outResp = vcrest.CreateDirectPlan422JSONResponse{
+			Code:    internal.CodeIdempotencyKeyReused,
+			Message: err.Error(),
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.CreateDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to create plan: %v", err),
+		}
+		return
+	}
+
+	switch r := putResp.(type) {
+	case nil, vcrest.PutDirectPlan201Response:
+		// Created by this request, or by an earlier one with the same idempotency key.
+	case vcrest.PutDirectPlan400JSONResponse:
+		outResp = vcrest.CreateDirectPlan400JSONResponse(r)
+		return
+	case vcrest.PutDirectPlan409JSONResponse:
+		outResp = vcrest.CreateDirectPlan409JSONResponse(r)
+		return
+	case vcrest.PutDirectPlan500JSONResponse:
+		outResp = vcrest.CreateDirectPlan500JSONResponse(r)
+		return
+	default:
+		outResp = vcrest.CreateDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("unexpected response creating plan: %+v", r),
+		}
+		return
+	}
+
+	created, err := s.createdPlan(ctx, id)
+	if err != nil {
+		outResp = vcrest.CreateDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.CreateDirectPlan201JSONResponse{
+		Body: created.Body,
+		Headers: vcrest.CreateDirectPlan201ResponseHeaders{
+			ETag:     created.Headers.ETag,
+			Location: "/plans/" + id.String(),
+		},
+	}
+	return
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// CreateDiscSource adds a disc source with a server-assigned UUID
+func (s *Server) CreateDiscSource(ctx context.Context, request vcrest.CreateDiscSourceRequestObject) (outResp vcrest.CreateDiscSourceResponseObject, _ error) {
+	var putResp vcrest.PutDiscSourceResponseObject
+	id, err := s.idempotentCreate(ctx, "createDiscSource", request.Params.IdempotencyKey, request.Body, func(srv *Server, id uuid.UUID) (bool, error) {
+		var err error
+		putResp, err = srv.PutDiscSource(ctx, vcrest.PutDiscSourceRequestObject{
+			Uuid:   openapi_types.UUID(id),
+			Params: vcrest.PutDiscSourceParams{IfNoneMatch: &createOnly},
+			Body:   request.Body,
+		})
+		_, created := putResp.(vcrest.PutDiscSource201Response)
+		return created, err
+	})
+	if errors.Is(err, internal.ErrIdempotencyKeyReused) {
+		outResp = vcrest.CreateDiscSource422JSONResponse{
+			Code:    internal.CodeIdempotencyKeyReused,
+			Message: err.Error(),
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.CreateDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to create source: %v", err),
+		}
+		return
+	}
+
+	switch r := putResp.(type) {
+	case nil, vcrest.PutDiscSource201Response:
+		// Created by this request, or by an earlier one with the same idempotency key.
+	case vcrest.PutDiscSource400JSONResponse:
+		outResp = vcrest.CreateDiscSource400JSONResponse(r)
+		return
+	case vcrest.PutDiscSource500JSONResponse:
+		outResp = vcrest.CreateDiscSource500JSONResponse(r)
+		return
+	default:
+		outResp = vcrest.CreateDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("unexpected response creating source: %+v", r),
+		}
+		return
+	}
+
+	created, err := s.createdSource(ctx, id)
+	if err != nil {
+		outResp = vcrest.CreateDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.CreateDiscSource201JSONResponse{
+		Body: created.Body,
+		Headers: vcrest.CreateDiscSource201ResponseHeaders{
+			ETag:     created.Headers.ETag,
+			Location: "/sources/" + id.String(),
+		},
+	}
+	return
+}
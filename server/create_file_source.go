@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// CreateFileSource adds a file source with a server-assigned UUID
+func (s *Server) CreateFileSource(ctx context.Context, request vcrest.CreateFileSourceRequestObject) (outResp vcrest.CreateFileSourceResponseObject, _ error) {
+	var putResp vcrest.PutFileSourceResponseObject
+	id, err := s.idempotentCreate(ctx, "createFileSource", request.Params.IdempotencyKey, request.Body, func(srv *Server, id uuid.UUID) (bool, error) {
+		var err error
+		putResp, err = srv.PutFileSource(ctx, vcrest.PutFileSourceRequestObject{
+			Uuid:   openapi_types.UUID(id),
+			Params: vcrest.PutFileSourceParams{IfNoneMatch: &createOnly},
+			Body:   request.Body,
+		})
+		_, created := putResp.(vcrest.PutFileSource201Response)
+		return created, err
+	})
+	if errors.Is(err, internal.ErrIdempotencyKeyReused) {
+		outResp = vcrest.CreateFileSource422JSONResponse{
+			Code:    internal.CodeIdempotencyKeyReused,
+			Message: err.Error(),
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.CreateFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to create source: %v", err),
+		}
+		return
+	}
+
+	switch r := putResp.(type) {
+	case nil, vcrest.PutFileSource201Response:
+		// Created by this request, or by an earlier one with the same idempotency key.
+	case vcrest.PutFileSource400JSONResponse:
+		outResp = vcrest.CreateFileSource400JSONResponse(r)
+		return
+	case vcrest.PutFileSource409JSONResponse:
+		outResp = vcrest.CreateFileSource409JSONResponse(r)
+		return
+	case vcrest.PutFileSource500JSONResponse:
+		outResp = vcrest.CreateFileSource500JSONResponse(r)
+		return
+	default:
+		outResp = vcrest.CreateFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("unexpected response creating source: %+v", r),
+		}
+		return
+	}
+
+	created, err := s.createdSource(ctx, id)
+	if err != nil {
+		outResp = vcrest.CreateFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.CreateFileSource201JSONResponse{
+		Body: created.Body,
+		Headers: vcrest.CreateFileSource201ResponseHeaders{
+			ETag:     created.Headers.ETag,
+			Location: "/sources/" + id.String(),
+		},
+	}
+	return
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// CreateLibrary adds an empty library
+func (s *Server) CreateLibrary(ctx context.Context, request vcrest.CreateLibraryRequestObject) (outResp vcrest.CreateLibraryResponseObject, _ error) {
+	// Validate request.
+	if err := internal.ValidateLibrary(request.Body); err != nil {
+		outResp = vcrest.CreateLibrary400JSONResponse(invalidRequest(err))
+		return
+	}
+
+	library, err := internal.CreateLibrary(ctx, s.Pool, request.Body)
+	if errors.Is(err, internal.ErrDuplicate) {
+		outResp = vcrest.CreateLibrary400JSONResponse(invalidRequest(err))
+		return
+	} else if err != nil {
+		outResp = vcrest.CreateLibrary500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.CreateLibrary201JSONResponse(*library)
+	return
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// CreateMovieEdition adds a movie edition work with a server-assigned UUID
+func (s *Server) CreateMovieEdition(ctx context.Context, request vcrest.CreateMovieEditionRequestObject) (outResp vcrest.CreateMovieEditionResponseObject, _ error) {
+	var putResp vcrest.PutMovieEditionResponseObject
+	id, err := s.idempotentCreate(ctx, "createMovieEdition", request.Params.IdempotencyKey, request.Body, func(srv *Server, id uuid.UUID) (bool, error) {
+		var err error
+		putResp, err = srv.PutMovieEdition(ctx, vcrest.PutMovieEditionRequestObject{
+			Uuid:   openapi_types.UUID(id),
+			Params: vcrest.PutMovieEditionParams{IfNoneMatch: &createOnly},
+			Body:   request.Body,
+		})
+		_, created := putResp.(vcrest.PutMovieEdition201Response)
+		return created, err
+	})
+	if errors.Is(err, internal.ErrIdempotencyKeyReused) {
+		outResp = vcrest.CreateMovieEdition422JSONResponse{
+			Code:    internal.CodeIdempotencyKeyReused,
+			Message: err.Error(),
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.CreateMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to create work: %v", err),
+		}
+		return
+	}
+
+	switch r := putResp.(type) {
+	case nil, vcrest.PutMovieEdition201Response:
+		// Created by this request, or by an earlier one with the same idempotency key.
+	case vcrest.PutMovieEdition400JSONResponse:
+		outResp = vcrest.CreateMovieEdition400JSONResponse(r)
+		return
+	case vcrest.PutMovieEdition409JSONResponse:
+		outResp = vcrest.CreateMovieEdition409JSONResponse(r)
+		return
+	case vcrest.PutMovieEdition500JSONResponse:
+		outResp = vcrest.CreateMovieEdition500JSONResponse(r)
+		return
+	default:
+		outResp = vcrest.CreateMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("unexpected response creating work: %+v", r),
+		}
+		return
+	}
+
+	created, err := s.createdWork(ctx, id)
+	if err != nil {
+		outResp = vcrest.CreateMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.CreateMovieEdition201JSONResponse{
+		Body: created.Body,
+		Headers: vcrest.CreateMovieEdition201ResponseHeaders{
+			ETag:     created.Headers.ETag,
+			Location: "/works/" + id.String(),
+		},
+	}
+	return
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// CreateMovieWork adds a movie work with a server-assigned UUID
+func (s *Server) CreateMovieWork(ctx context.Context, request vcrest.CreateMovieWorkRequestObject) (outResp vcrest.CreateMovieWorkResponseObject, _ error) {
+	var putResp vcrest.PutMovieWorkResponseObject
+	id, err := s.idempotentCreate(ctx, "createMovieWork", request.Params.IdempotencyKey, request.Body, func(srv *Server, id uuid.UUID) (bool, error) {
+		var err error
+		putResp, err = srv.PutMovieWork(ctx, vcrest.PutMovieWorkRequestObject{
+			Uuid:   openapi_types.UUID(id),
+			Params: vcrest.PutMovieWorkParams{IfNoneMatch: &createOnly},
+			Body:   request.Body,
+		})
+		_, created := putResp.(vcrest.PutMovieWork201Response)
+		return created, err
+	})
+	if errors.Is(err, internal.ErrIdempotencyKeyReused) {
+		outResp = vcrest.CreateMovieWork422JSONResponse{
+			Code:    internal.CodeIdempotencyKeyReused,
+			Message: err.Error(),
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.CreateMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to create work: %v", err),
+		}
+		return
+	}
+
+	switch r := putResp.(type) {
+	case nil, vcrest.PutMovieWork201Response:
+		// Created by this request, or by an earlier one with the same idempotency key.
+	case vcrest.PutMovieWork400JSONResponse:
+		outResp = vcrest.CreateMovieWork400JSONResponse(r)
+		return
+	case vcrest.PutMovieWork500JSONResponse:
+		outResp = vcrest.CreateMovieWork500JSONResponse(r)
+		return
+	default:
+		outResp = vcrest.CreateMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("unexpected response creating work: %+v", r),
+		}
+		return
+	}
+
+	created, err := s.createdWork(ctx, id)
+	if err != nil {
+		outResp = vcrest.CreateMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.CreateMovieWork201JSONResponse{
+		Body: created.Body,
+		Headers: vcrest.CreateMovieWork201ResponseHeaders{
+			ETag:     created.Headers.ETag,
+			Location: "/works/" + id.String(),
+		},
+	}
+	return
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// CreateWebhook subscribes a URL to catalog events
+func (s *Server) CreateWebhook(ctx context.Context, request vcrest.CreateWebhookRequestObject) (outResp vcrest.CreateWebhookResponseObject, _ error) {
+	// Validate request.
+	if err := internal.ValidateWebhook(request.Body); err != nil {
+		outResp = vcrest.CreateWebhook400JSONResponse(invalidRequest(err))
+		return
+	}
+
+	webhook, err := internal.CreateWebhook(ctx, s.Pool, request.Body)
+	if err != nil {
+		outResp = vcrest.CreateWebhook500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.CreateWebhook201JSONResponse(*webhook)
+	return
+}
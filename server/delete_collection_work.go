@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// DeleteCollectionWork removes a work from a collection
+func (s *Server) DeleteCollectionWork(ctx context.Context, request vcrest.DeleteCollectionWorkRequestObject) (outResp vcrest.DeleteCollectionWorkResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.DeleteCollectionWork400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	workUuid, err := internal.AsUUID(request.WorkUuid)
+	if err != nil {
+		outResp = vcrest.DeleteCollectionWork400JSONResponse(fieldError("workUuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.DeleteCollectionWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.DeleteCollectionWork(ctx, txn, requestUuid, workUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.DeleteCollectionWork404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "collection not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.DeleteCollectionWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to remove work from collection: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.DeleteCollectionWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.DeleteCollectionWork200Response{}
+	return
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// DeletePlan moves the plan with the given UUID to the trash
+func (s *Server) DeletePlan(ctx context.Context, request vcrest.DeletePlanRequestObject) (outResp vcrest.DeletePlanResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.DeletePlan400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.DeletePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.SoftDeleteEntity(ctx, txn, "plans", requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.DeletePlan404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "plan not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.DeletePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.DeletePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.DeletePlan200Response{}
+	return
+}
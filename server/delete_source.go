@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// DeleteSource moves the source with the given UUID to the trash
+func (s *Server) DeleteSource(ctx context.Context, request vcrest.DeleteSourceRequestObject) (outResp vcrest.DeleteSourceResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.DeleteSource400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.DeleteSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.SoftDeleteEntity(ctx, txn, "sources", requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.DeleteSource404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "source not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.DeleteSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.DeleteSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.DeleteSource200Response{}
+	return
+}
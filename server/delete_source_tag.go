@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// DeleteSourceTag removes a tag from the source with the given UUID
+func (s *Server) DeleteSourceTag(ctx context.Context, request vcrest.DeleteSourceTagRequestObject) (outResp vcrest.DeleteSourceTagResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.DeleteSourceTag400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.DeleteSourceTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.SourceTags.Remove(ctx, txn, requestUuid, request.Tag)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.DeleteSourceTag404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "source not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.DeleteSourceTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to remove tag: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.DeleteSourceTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.DeleteSourceTag200Response{}
+	return
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// DeleteWatchState forgets the caller's watch state of the work with the given UUID.
+func (s *Server) DeleteWatchState(ctx context.Context, request vcrest.DeleteWatchStateRequestObject) (outResp vcrest.DeleteWatchStateResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.DeleteWatchState400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		outResp = vcrest.DeleteWatchState500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.DeleteWatchState500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.DeleteWatchState(ctx, txn, userID, requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.DeleteWatchState404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.DeleteWatchState500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.DeleteWatchState500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.DeleteWatchState204Response{}
+	return
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// DeleteWebhook removes a webhook subscription and its delivery log
+func (s *Server) DeleteWebhook(ctx context.Context, request vcrest.DeleteWebhookRequestObject) (outResp vcrest.DeleteWebhookResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.DeleteWebhook400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	err = internal.DeleteWebhook(ctx, s.Pool, requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.DeleteWebhook404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "webhook not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.DeleteWebhook500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.DeleteWebhook200Response{}
+	return
+}
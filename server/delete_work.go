@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// DeleteWork moves the work with the given UUID to the trash
+func (s *Server) DeleteWork(ctx context.Context, request vcrest.DeleteWorkRequestObject) (outResp vcrest.DeleteWorkResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.DeleteWork400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.DeleteWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.SoftDeleteEntity(ctx, txn, "works", requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.DeleteWork404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.DeleteWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.DeleteWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.DeleteWork200Response{}
+	return
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// DeleteWorkGenre removes a genre from the work with the given UUID
+func (s *Server) DeleteWorkGenre(ctx context.Context, request vcrest.DeleteWorkGenreRequestObject) (outResp vcrest.DeleteWorkGenreResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.DeleteWorkGenre400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.DeleteWorkGenre500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.WorkGenres.Remove(ctx, txn, requestUuid, request.Genre)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.DeleteWorkGenre404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.DeleteWorkGenre500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to remove genre: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.DeleteWorkGenre500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.DeleteWorkGenre200Response{}
+	return
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// DeleteWorkTag removes a tag from the work with the given UUID
+func (s *Server) DeleteWorkTag(ctx context.Context, request vcrest.DeleteWorkTagRequestObject) (outResp vcrest.DeleteWorkTagResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.DeleteWorkTag400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.DeleteWorkTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.WorkTags.Remove(ctx, txn, requestUuid, request.Tag)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.DeleteWorkTag404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.DeleteWorkTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to remove tag: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.DeleteWorkTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.DeleteWorkTag200Response{}
+	return
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// apiError builds the body of an error response.
+func apiError(code, message string) vcrest.Error {
+	return vcrest.Error{Code: code, Message: message}
+}
+
+// invalidRequest builds the body of a 400 response for err.  If err contains any internal.FieldErrors, they
+// are listed in the details of the response; otherwise the whole request is reported as invalid.
+func invalidRequest(err error) vcrest.Error {
+	fieldErrs := internal.FieldErrors(err)
+	if len(fieldErrs) == 0 {
+		return apiError(internal.CodeInvalidRequest, err.Error())
+	}
+	body := apiError(internal.CodeInvalidField, err.Error())
+	for _, fieldErr := range fieldErrs {
+		body.Details = append(body.Details, vcrest.FieldError{
+			Field:   fieldErr.Field,
+			Code:    fieldErr.Code(),
+			Message: fieldErr.Err.Error(),
+		})
+	}
+	return body
+}
+
+// fieldError builds the body of a 400 response for a field whose value failed validation with err.
+func fieldError(field string, err error) vcrest.Error {
+	return invalidRequest(internal.NewFieldError(field, err))
+}
+
+// missingReference builds the body of a response to a request with fields that reference entities that do
+// not exist or cannot be referenced.
+func missingReference(err error) vcrest.Error {
+	body := invalidRequest(err)
+	body.Code = internal.CodeReferenceMissing
+	return body
+}
+
+// codeForStatus returns the error code for an error response that did not come with one, such as the plain
+// text responses for requests that do not match any route.
+func codeForStatus(status int) string {
+	switch {
+	case status == http.StatusNotFound:
+		return internal.CodeNotFound
+	case status == http.StatusRequestEntityTooLarge:
+		return internal.CodeRequestTooLarge
+	case status < http.StatusInternalServerError:
+		return internal.CodeInvalidRequest
+	default:
+		return internal.CodeInternal
+	}
+}
+
+// requestTooLarge builds the body of a 413 response if err comes from reading a request body that is over
+// the limit set by withMaxBodySize.
+func requestTooLarge(err error) (vcrest.Error, bool) {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return vcrest.Error{}, false
+	}
+	return apiError(internal.CodeRequestTooLarge, fmt.Sprintf("request body is larger than %d bytes", maxBytesErr.Limit)), true
+}
+
+// writeRequestError reports a request that the generated code could not decode.
+func writeRequestError(w http.ResponseWriter, _ *http.Request, err error) {
+	if body, ok := requestTooLarge(err); ok {
+		writeError(w, http.StatusRequestEntityTooLarge, body)
+		return
+	}
+	writeError(w, http.StatusBadRequest, invalidRequest(err))
+}
+
+// writeResponseError reports a handler that failed without building a response, or a request that was
+// rejected by strict middleware.
+func writeResponseError(w http.ResponseWriter, _ *http.Request, err error) {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		writeError(w, statusErr.status, statusErr.body)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, err.Error()))
+}
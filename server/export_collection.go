@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// exportCollectionHeader is the header row of a collection export.
+var exportCollectionHeader = []string{"position", "uuid", "kind", "title", "releaseYear", "tmdbId"}
+
+// ExportCollection exports the works in a collection as CSV
+func (s *Server) ExportCollection(ctx context.Context, request vcrest.ExportCollectionRequestObject) (outResp vcrest.ExportCollectionResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.ExportCollection400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ExportCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	var one int
+	err = txn.QueryRow(ctx, `SELECT 1 FROM collections WHERE uuid = $1`, requestUuid).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		outResp = vcrest.ExportCollection404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "collection not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.ExportCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query collection: %v", err),
+		}
+		return
+	}
+
+	rows, err := txn.Query(ctx, `
+		SELECT cw.position, w.uuid, w.kind, w.body
+		FROM collection_works cw
+		INNER JOIN works w ON w.uuid = cw.work_uuid
+		WHERE cw.collection_uuid = $1 AND w.deleted_at IS NULL AND `+internal.LibraryCondition("w.library_uuid", 2)+`
+		ORDER BY cw.position
+	`, requestUuid, internal.LibraryFromContext(ctx))
+	if err != nil {
+		outResp = vcrest.ExportCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query collection works: %v", err),
+		}
+		return
+	}
+
+	var buf bytes.Buffer
+	out := csv.NewWriter(&buf)
+	if err := out.Write(exportCollectionHeader); err != nil {
+		outResp = vcrest.ExportCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to write export: %v", err),
+		}
+		return
+	}
+
+	var position int32
+	var workUUID uuid.UUID
+	var workKind internal.WorkKind
+	var workBody json.RawMessage
+	_, err = pgx.ForEachRow(rows, []any{&position, &workUUID, &workKind, &workBody}, func() error {
+		record := []string{strconv.Itoa(int(position)), workUUID.String(), string(workKind), "", "", ""}
+		switch workKind {
+		case internal.WorkKindMovie:
+			var movieBody internal.MovieWork
+			if err := json.Unmarshal(workBody, &movieBody); err != nil {
+				return fmt.Errorf("failed to unmarshal movie work body: %w", err)
+			}
+			record[3] = movieBody.Title
+			if movieBody.ReleaseYear != nil {
+				record[4] = strconv.Itoa(int(*movieBody.ReleaseYear))
+			}
+			if movieBody.TmdbId != nil {
+				record[5] = strconv.Itoa(int(*movieBody.TmdbId))
+			}
+		case internal.WorkKindMovieEdition:
+			var editionBody internal.MovieEditionWork
+			if err := json.Unmarshal(workBody, &editionBody); err != nil {
+				return fmt.Errorf("failed to unmarshal movie edition work body: %w", err)
+			}
+			record[3] = editionBody.EditionType
+		default:
+			return fmt.Errorf("unimplemented work kind: %s", workKind)
+		}
+		return out.Write(record)
+	})
+	if err != nil {
+		outResp = vcrest.ExportCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to export collection works: %v", err),
+		}
+		return
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		outResp = vcrest.ExportCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to write export: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ExportCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.ExportCollection200TextcsvResponse{
+		Body:          &buf,
+		ContentLength: int64(buf.Len()),
+	}
+	return
+}
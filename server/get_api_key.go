@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetApiKey retrieves an API key by UUID
+func (s *Server) GetApiKey(ctx context.Context, request vcrest.GetApiKeyRequestObject) (outResp vcrest.GetApiKeyResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.GetApiKey400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	apiKey, err := internal.GetAPIKey(ctx, s.Pool, requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.GetApiKey404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "API key not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.GetApiKey500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.GetApiKey200JSONResponse(*apiKey)
+	return
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetCollection retrieves a collection and its works by UUID
+func (s *Server) GetCollection(ctx context.Context, request vcrest.GetCollectionRequestObject) (outResp vcrest.GetCollectionResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.GetCollection400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.GetCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	var bodyRaw json.RawMessage
+	err = txn.QueryRow(ctx, `
+		SELECT body
+		FROM collections
+		WHERE uuid = $1
+	`, requestUuid).Scan(&bodyRaw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		outResp = vcrest.GetCollection404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "collection not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.GetCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query collection: %v", err),
+		}
+		return
+	}
+	collection, err := internal.CollectionToAPI(requestUuid, bodyRaw)
+	if err != nil {
+		outResp = vcrest.GetCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	rows, err := txn.Query(ctx, `
+		SELECT w.uuid, w.kind, w.body
+		FROM collection_works cw
+		INNER JOIN works w ON w.uuid = cw.work_uuid
+		WHERE cw.collection_uuid = $1 AND w.deleted_at IS NULL AND `+internal.LibraryCondition("w.library_uuid", 2)+`
+		ORDER BY cw.position
+	`, requestUuid, internal.LibraryFromContext(ctx))
+	if err != nil {
+		outResp = vcrest.GetCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query collection works: %v", err),
+		}
+		return
+	}
+
+	collection.Works = []vcrest.Work{}
+	var workUUID uuid.UUID
+	var workKind internal.WorkKind
+	var workBody json.RawMessage
+	_, err = pgx.ForEachRow(rows, []any{&workUUID, &workKind, &workBody}, func() error {
+		work, err := internal.WorkToAPI(workUUID, workKind, workBody)
+		if err != nil {
+			return err
+		}
+		collection.Works = append(collection.Works, *work)
+		return nil
+	})
+	if err != nil {
+		outResp = vcrest.GetCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query and scan collection works: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.GetCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.GetCollection200JSONResponse(*collection)
+	return
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetCurrentUser returns the user that the credentials of the request belong to.
+func (s *Server) GetCurrentUser(ctx context.Context, request vcrest.GetCurrentUserRequestObject) (outResp vcrest.GetCurrentUserResponseObject, _ error) {
+	principal, ok := internal.PrincipalFromContext(ctx)
+	if !ok {
+		outResp = vcrest.GetCurrentUser500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: "request has no authenticated caller",
+		}
+		return
+	}
+
+	outResp = vcrest.GetCurrentUser200JSONResponse{
+		Id:     principal.ID,
+		Name:   principal.Name,
+		Scopes: append([]string{}, principal.Scopes...),
+	}
+	return
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+const (
+	defaultGraphDepth = 2
+	maxGraphDepth     = 10
+	maxGraphNodes     = 1000
+)
+
+// GetGraph returns the catalog graph around a work, source or plan
+func (s *Server) GetGraph(ctx context.Context, request vcrest.GetGraphRequestObject) (outResp vcrest.GetGraphResponseObject, _ error) {
+	// Validate request.
+	rootUuid, err := internal.AsUUID(request.Params.Root)
+	if err != nil {
+		outResp = vcrest.GetGraph400JSONResponse(fieldError("root", internal.ErrInvalidUUID))
+		return
+	}
+	depth := defaultGraphDepth
+	if request.Params.Depth != nil {
+		depth = min(max(int(*request.Params.Depth), 0), maxGraphDepth)
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.GetGraph500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	graph, err := internal.LoadGraph(ctx, txn, rootUuid, depth, maxGraphNodes)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.GetGraph404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "root entity not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.GetGraph500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	nodes := make([]vcrest.GraphNode, 0, len(graph.Works)+len(graph.Sources)+len(graph.Plans))
+	for _, work := range graph.Works {
+		nodes = append(nodes, vcrest.GraphNode{
+			Uuid:  work.Uuid,
+			Type:  "work",
+			Depth: int32(graph.Depths[uuid.UUID(work.Uuid)]),
+			Work:  work,
+		})
+	}
+	for _, source := range graph.Sources {
+		nodes = append(nodes, vcrest.GraphNode{
+			Uuid:   source.Uuid,
+			Type:   "source",
+			Depth:  int32(graph.Depths[uuid.UUID(source.Uuid)]),
+			Source: source,
+		})
+	}
+	for _, plan := range graph.Plans {
+		nodes = append(nodes, vcrest.GraphNode{
+			Uuid:  plan.Uuid,
+			Type:  "plan",
+			Depth: int32(graph.Depths[uuid.UUID(plan.Uuid)]),
+			Plan:  plan,
+		})
+	}
+	slices.SortFunc(nodes, func(a, b vcrest.GraphNode) int {
+		return cmp.Or(cmp.Compare(a.Depth, b.Depth), cmp.Compare(a.Uuid.String(), b.Uuid.String()))
+	})
+
+	edges := make([]vcrest.GraphEdge, 0, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		edges = append(edges, vcrest.GraphEdge{
+			From: edge.From,
+			To:   edge.To,
+			Type: edge.Kind,
+		})
+	}
+
+	response := vcrest.GetGraph200JSONResponse{
+		Nodes: nodes,
+		Edges: edges,
+	}
+	if graph.Truncated {
+		response.Truncated = &graph.Truncated
+	}
+	outResp = response
+	return
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"context"
+
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetHealth reports that the server is alive.  It checks nothing else, since failing it gets the server
+// restarted, which would not fix a failing database.
+func (s *Server) GetHealth(ctx context.Context, request vcrest.GetHealthRequestObject) (outResp vcrest.GetHealthResponseObject, _ error) {
+	outResp = vcrest.GetHealth200JSONResponse{
+		Status: "ok",
+	}
+	return
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetLibrary retrieves a library by UUID
+func (s *Server) GetLibrary(ctx context.Context, request vcrest.GetLibraryRequestObject) (outResp vcrest.GetLibraryResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.GetLibrary400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	library, err := internal.GetLibrary(ctx, s.Pool, requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.GetLibrary404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "library not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.GetLibrary500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.GetLibrary200JSONResponse(*library)
+	return
+}
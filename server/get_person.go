@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetPerson retrieves a person by UUID
+func (s *Server) GetPerson(ctx context.Context, request vcrest.GetPersonRequestObject) (outResp vcrest.GetPersonResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.GetPerson400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	var bodyRaw json.RawMessage
+	err = s.Pool.QueryRow(ctx, `
+		SELECT body
+		FROM persons
+		WHERE uuid = $1
+	`, requestUuid).Scan(&bodyRaw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		outResp = vcrest.GetPerson404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "person not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.GetPerson500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query person: %v", err),
+		}
+		return
+	}
+
+	var body internal.Person
+	if err := json.Unmarshal(bodyRaw, &body); err != nil {
+		outResp = vcrest.GetPerson500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to unmarshal person body: %v", err),
+		}
+		return
+	}
+	outResp = vcrest.GetPerson200JSONResponse{
+		Uuid:    request.Uuid,
+		Details: body.ToAPI(),
+	}
+	return
+}
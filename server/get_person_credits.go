@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetPersonCredits lists the works a person is credited on.
+func (s *Server) GetPersonCredits(ctx context.Context, request vcrest.GetPersonCreditsRequestObject) (outResp vcrest.GetPersonCreditsResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.GetPersonCredits400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	var role *internal.CreditRole
+	if request.Params.Role != nil {
+		r := internal.CreditRole(*request.Params.Role)
+		if !r.IsValid() {
+			outResp = vcrest.GetPersonCredits400JSONResponse(fieldError("role", internal.ErrInvalid))
+			return
+		}
+		role = &r
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.GetPersonCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	var exists bool
+	err = txn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM persons WHERE uuid = $1)`, requestUuid).Scan(&exists)
+	if err != nil {
+		outResp = vcrest.GetPersonCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query person: %v", err),
+		}
+		return
+	} else if !exists {
+		outResp = vcrest.GetPersonCredits404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "person not found",
+		}
+		return
+	}
+
+	rows, err := txn.Query(ctx, `
+		SELECT c.work_uuid, c.role, c.character_name, c.billing_order, w.kind, w.body
+		FROM credits c
+		INNER JOIN works w ON w.uuid = c.work_uuid
+		WHERE c.person_uuid = $1 AND w.deleted_at IS NULL AND ($2::varchar IS NULL OR c.role = $2)
+			AND `+internal.LibraryCondition("w.library_uuid", 3)+`
+		ORDER BY work_sort_title(w.body), c.work_uuid, c.role
+	`, requestUuid, role, internal.LibraryFromContext(ctx))
+	if err != nil {
+		outResp = vcrest.GetPersonCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query credits: %v", err),
+		}
+		return
+	}
+
+	credits := []vcrest.Credit{}
+	row := internal.Credit{
+		PersonUUID: requestUuid,
+	}
+	var workUUID uuid.UUID
+	var workKind internal.WorkKind
+	var workBody json.RawMessage
+	_, err = pgx.ForEachRow(rows, []any{&workUUID, &row.Role, &row.CharacterName, &row.BillingOrder, &workKind, &workBody}, func() error {
+		row.WorkUUID = workUUID
+		credit := row.ToAPI()
+		work, err := internal.WorkToAPI(workUUID, workKind, workBody)
+		if err != nil {
+			return err
+		}
+		credit.Work = work
+		credits = append(credits, *credit)
+		return nil
+	})
+	if err != nil {
+		outResp = vcrest.GetPersonCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query and scan credits: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.GetPersonCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.GetPersonCredits200JSONResponse{
+		Credits: credits,
+	}
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/krelinga/video-catalog/internal"
@@ -16,15 +17,19 @@ func (s *Server) GetPlan(ctx context.Context, request vcrest.GetPlanRequestObjec
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.GetPlan400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.GetPlan400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	expand, err := internal.ParseExpand(request.Params.Expand, internal.RelationSource, internal.RelationWork)
+	if err != nil {
+		outResp = vcrest.GetPlan400JSONResponse(invalidRequest(err))
 		return
 	}
 
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.GetPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
@@ -33,18 +38,23 @@ func (s *Server) GetPlan(ctx context.Context, request vcrest.GetPlanRequestObjec
 
 	var kind internal.PlanKind
 	var bodyRaw json.RawMessage
+	var deletedAt, completedAt *time.Time
+	var version int64
+	includeDeleted := request.Params.IncludeDeleted != nil && *request.Params.IncludeDeleted
 	err = txn.QueryRow(ctx, `
-		SELECT kind, body
+		SELECT kind, body, deleted_at, completed_at, version
 		FROM plans
-		WHERE uuid = $1
-	`, requestUuid).Scan(&kind, &bodyRaw)
+		WHERE uuid = $1 AND ($2 OR deleted_at IS NULL) AND `+internal.LibraryCondition("library_uuid", 3)+`
+	`, requestUuid, includeDeleted, internal.LibraryFromContext(ctx)).Scan(&kind, &bodyRaw, &deletedAt, &completedAt, &version)
 	if errors.Is(err, pgx.ErrNoRows) {
 		outResp = vcrest.GetPlan404JSONResponse{
+			Code:    internal.CodeNotFound,
 			Message: "plan not found",
 		}
 		return
 	} else if err != nil {
 		outResp = vcrest.GetPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query plan: %v", err),
 		}
 		return
@@ -52,42 +62,36 @@ func (s *Server) GetPlan(ctx context.Context, request vcrest.GetPlanRequestObjec
 
 	if !kind.IsValid() {
 		outResp = vcrest.GetPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("invalid plan kind in database: %s", kind),
 		}
 		return
 	}
 
-	switch kind {
-	case internal.PlanKindDirect:
-		var directBody internal.DirectPlan
-		if err := json.Unmarshal(bodyRaw, &directBody); err != nil {
-			outResp = vcrest.GetPlan500JSONResponse{
-				Message: fmt.Sprintf("failed to unmarshal direct plan body: %v", err),
-			}
-			return
-		}
-		outResp = vcrest.GetPlan200JSONResponse{
-			Uuid:   request.Uuid,
-			Direct: directBody.ToAPI(),
-		}
-		return
-	case internal.PlanKindChapterRange:
-		var chapterRangeBody internal.ChapterRangePlan
-		if err := json.Unmarshal(bodyRaw, &chapterRangeBody); err != nil {
-			outResp = vcrest.GetPlan500JSONResponse{
-				Message: fmt.Sprintf("failed to unmarshal chapter range plan body: %v", err),
-			}
-			return
-		}
-		outResp = vcrest.GetPlan200JSONResponse{
-			Uuid:         request.Uuid,
-			ChapterRange: chapterRangeBody.ToAPI(),
+	plan, err := internal.PlanToAPI(requestUuid, kind, bodyRaw)
+	if err != nil {
+		outResp = vcrest.GetPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
 		}
 		return
-	default:
+	}
+	plan.DeletedAt = deletedAt
+	plan.CompletedAt = completedAt
+
+	if err := internal.ExpandPlans(ctx, txn, []*vcrest.Plan{plan}, expand); err != nil {
 		outResp = vcrest.GetPlan500JSONResponse{
-			Message: fmt.Sprintf("unimplemented plan kind: %s", kind),
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
 		}
 		return
 	}
+
+	outResp = vcrest.GetPlan200JSONResponse{
+		Body: *plan,
+		Headers: vcrest.GetPlan200ResponseHeaders{
+			ETag: internal.ETag(version),
+		},
+	}
+	return
 }
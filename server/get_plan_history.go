@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetPlanHistory retrieves the change history of the plan with the given UUID
+func (s *Server) GetPlanHistory(ctx context.Context, request vcrest.GetPlanHistoryRequestObject) (outResp vcrest.GetPlanHistoryResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.GetPlanHistory400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	// Determine page size with reasonable bounds
+	pageSize := defaultPageSize
+	if request.Params.PageSize != nil {
+		ps := *request.Params.PageSize
+		if ps < minPageSize {
+			pageSize = minPageSize
+		} else if ps > maxPageSize {
+			pageSize = maxPageSize
+		} else {
+			pageSize = int(ps)
+		}
+	}
+
+	// Decode page token if provided
+	var lastID int64
+	if request.Params.PageToken != nil && *request.Params.PageToken != "" {
+		lastID, err = decodeHistoryPageToken(*request.Params.PageToken)
+		if err != nil {
+			outResp = vcrest.GetPlanHistory400JSONResponse{
+				Code:    internal.CodeInvalidPageToken,
+				Message: fmt.Sprintf("invalid page token: %v", err),
+			}
+			return
+		}
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.GetPlanHistory500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Fetch one extra to determine if there's a next page
+	entries, err := internal.ListHistory(ctx, txn, "plans", requestUuid, lastID, pageSize+1)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.GetPlanHistory404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "plan not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.GetPlanHistory500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.GetPlanHistory500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	hasMore := len(entries) > pageSize
+	if hasMore {
+		entries = entries[:pageSize]
+	}
+
+	response := vcrest.GetPlanHistory200JSONResponse{
+		Entries: []vcrest.PlanHistoryEntry{},
+	}
+	for _, entry := range entries {
+		apiEntry := vcrest.PlanHistoryEntry{
+			Id:         entry.ID,
+			Operation:  string(entry.Operation),
+			Actor:      entry.Actor,
+			RequestId:  entry.RequestID,
+			OnBehalfOf: entry.OnBehalfOf,
+			CreatedAt:  entry.CreatedAt,
+		}
+		if entry.OldBody != nil {
+			apiEntry.Before, err = internal.PlanToAPI(requestUuid, internal.PlanKind(entry.Kind), entry.OldBody)
+			if err != nil {
+				outResp = vcrest.GetPlanHistory500JSONResponse{
+					Code:    internal.CodeInternal,
+					Message: err.Error(),
+				}
+				return
+			}
+		}
+		apiEntry.After, err = internal.PlanToAPI(requestUuid, internal.PlanKind(entry.Kind), entry.NewBody)
+		if err != nil {
+			outResp = vcrest.GetPlanHistory500JSONResponse{
+				Code:    internal.CodeInternal,
+				Message: err.Error(),
+			}
+			return
+		}
+		response.Entries = append(response.Entries, apiEntry)
+	}
+
+	// Add next page token if there are more results
+	if hasMore {
+		token := encodeHistoryPageToken(entries[len(entries)-1].ID)
+		response.NextPageToken = &token
+	}
+
+	outResp = response
+	return
+}
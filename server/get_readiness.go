@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// readinessTimeout bounds the checks, so that a database that hangs makes the server unready rather than
+// making the probe time out.
+const readinessTimeout = 5 * time.Second
+
+// GetReadiness checks that the server can handle requests: the database responds with its schema at the
+// expected version, and the background jobs are running.
+func (s *Server) GetReadiness(ctx context.Context, request vcrest.GetReadinessRequestObject) (outResp vcrest.GetReadinessResponseObject, _ error) {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	readiness := vcrest.Readiness{
+		Ready:  true,
+		Checks: []vcrest.ReadinessCheck{},
+	}
+	check := func(name string, err error) {
+		result := vcrest.ReadinessCheck{Name: name, Ok: err == nil}
+		if err != nil {
+			message := err.Error()
+			result.Message = &message
+			readiness.Ready = false
+		}
+		readiness.Checks = append(readiness.Checks, result)
+	}
+
+	var ping int
+	check("database", s.Pool.QueryRow(ctx, `SELECT 1`).Scan(&ping))
+	check("schema", s.checkSchema(ctx))
+	check("jobs", s.checkJobs())
+
+	if !readiness.Ready {
+		outResp = vcrest.GetReadiness503JSONResponse(readiness)
+		return
+	}
+	outResp = vcrest.GetReadiness200JSONResponse(readiness)
+	return
+}
+
+// checkSchema checks that all of the migrations of this build have been applied to the database, and none
+// of them failed.
+func (s *Server) checkSchema(ctx context.Context) error {
+	expected, err := internal.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	version, dirty, err := internal.DatabaseSchemaVersion(ctx, s.Pool)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration to schema version %d failed", version)
+	}
+	if version != expected {
+		return fmt.Errorf("schema version is %d, expected %d", version, expected)
+	}
+	return nil
+}
+
+// checkJobs checks that the background jobs are running.
+func (s *Server) checkJobs() error {
+	if s.Jobs == nil {
+		return errors.New("background jobs are not configured")
+	}
+	select {
+	case <-s.Jobs.Stopped():
+		return errors.New("background jobs have stopped")
+	default:
+		return nil
+	}
+}
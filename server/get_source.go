@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/krelinga/video-catalog/internal"
 	"github.com/krelinga/video-catalog/vcrest"
@@ -16,15 +18,19 @@ func (s *Server) GetSource(ctx context.Context, request vcrest.GetSourceRequestO
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.GetSource400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.GetSource400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	expand, err := internal.ParseExpand(request.Params.Expand, internal.RelationParent, internal.RelationChildren)
+	if err != nil {
+		outResp = vcrest.GetSource400JSONResponse(invalidRequest(err))
 		return
 	}
 
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.GetSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
@@ -33,18 +39,24 @@ func (s *Server) GetSource(ctx context.Context, request vcrest.GetSourceRequestO
 
 	var kind internal.SourceKind
 	var bodyRaw json.RawMessage
+	var deletedAt *time.Time
+	var version int64
+	var parentUuid *uuid.UUID
+	includeDeleted := request.Params.IncludeDeleted != nil && *request.Params.IncludeDeleted
 	err = txn.QueryRow(ctx, `
-		SELECT kind, body
+		SELECT kind, body, deleted_at, version, parent_uuid
 		FROM sources
-		WHERE uuid = $1
-	`, requestUuid).Scan(&kind, &bodyRaw)
+		WHERE uuid = $1 AND ($2 OR deleted_at IS NULL) AND `+internal.LibraryCondition("library_uuid", 3)+`
+	`, requestUuid, includeDeleted, internal.LibraryFromContext(ctx)).Scan(&kind, &bodyRaw, &deletedAt, &version, &parentUuid)
 	if errors.Is(err, pgx.ErrNoRows) {
 		outResp = vcrest.GetSource404JSONResponse{
+			Code:    internal.CodeNotFound,
 			Message: "source not found",
 		}
 		return
 	} else if err != nil {
 		outResp = vcrest.GetSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query source: %v", err),
 		}
 		return
@@ -52,42 +64,35 @@ func (s *Server) GetSource(ctx context.Context, request vcrest.GetSourceRequestO
 
 	if !kind.IsValid() {
 		outResp = vcrest.GetSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("invalid source kind in database: %s", kind),
 		}
 		return
 	}
 
-	switch kind {
-	case internal.SourceKindFile:
-		var fileBody internal.FileSource
-		if err := json.Unmarshal(bodyRaw, &fileBody); err != nil {
-			outResp = vcrest.GetSource500JSONResponse{
-				Message: fmt.Sprintf("failed to unmarshal file source body: %v", err),
-			}
-			return
-		}
-		outResp = vcrest.GetSource200JSONResponse{
-			Uuid: request.Uuid,
-			File: fileBody.ToAPI(),
-		}
-		return
-	case internal.SourceKindDisc:
-		var discBody internal.DiscSource
-		if err := json.Unmarshal(bodyRaw, &discBody); err != nil {
-			outResp = vcrest.GetSource500JSONResponse{
-				Message: fmt.Sprintf("failed to unmarshal disc source body: %v", err),
-			}
-			return
-		}
-		outResp = vcrest.GetSource200JSONResponse{
-			Uuid: request.Uuid,
-			Disc: discBody.ToAPI(),
+	source, err := internal.SourceToAPI(requestUuid, kind, bodyRaw)
+	if err != nil {
+		outResp = vcrest.GetSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
 		}
 		return
-	default:
+	}
+	source.DeletedAt = deletedAt
+
+	if err := internal.ExpandSource(ctx, txn, source, parentUuid, expand); err != nil {
 		outResp = vcrest.GetSource500JSONResponse{
-			Message: fmt.Sprintf("unimplemented source kind: %s", kind),
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
 		}
 		return
 	}
+
+	outResp = vcrest.GetSource200JSONResponse{
+		Body: *source,
+		Headers: vcrest.GetSource200ResponseHeaders{
+			ETag: internal.ETag(version),
+		},
+	}
+	return
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetSourceTags lists the tags of the source with the given UUID
+func (s *Server) GetSourceTags(ctx context.Context, request vcrest.GetSourceTagsRequestObject) (outResp vcrest.GetSourceTagsResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.GetSourceTags400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.GetSourceTags500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	tags, err := internal.SourceTags.List(ctx, txn, requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.GetSourceTags404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "source not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.GetSourceTags500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to list tags: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.GetSourceTags500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.GetSourceTags200JSONResponse{
+		Tags: tags,
+	}
+	return
+}
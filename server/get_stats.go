@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetStats returns the library statistics, as last computed by the refresh job.
+func (s *Server) GetStats(ctx context.Context, request vcrest.GetStatsRequestObject) (outResp vcrest.GetStatsResponseObject, _ error) {
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.GetStats500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	stats, err := internal.LoadStats(ctx, txn)
+	if err != nil {
+		outResp = vcrest.GetStats500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.GetStats200JSONResponse(*stats)
+	return
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetVersion returns the release, commit and schema version of the server.
+func (s *Server) GetVersion(ctx context.Context, request vcrest.GetVersionRequestObject) (outResp vcrest.GetVersionResponseObject, _ error) {
+	schemaVersion, err := internal.SchemaVersion()
+	if err != nil {
+		outResp = vcrest.GetVersion500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.GetVersion200JSONResponse{
+		Version:       internal.Version,
+		Commit:        internal.BuildCommit(),
+		SchemaVersion: int64(schemaVersion),
+	}
+	return
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetWebhook retrieves a webhook subscription by UUID
+func (s *Server) GetWebhook(ctx context.Context, request vcrest.GetWebhookRequestObject) (outResp vcrest.GetWebhookResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.GetWebhook400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	webhook, err := internal.GetWebhook(ctx, s.Pool, requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.GetWebhook404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "webhook not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.GetWebhook500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.GetWebhook200JSONResponse(*webhook)
+	return
+}
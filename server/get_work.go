@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/krelinga/video-catalog/internal"
 	"github.com/krelinga/video-catalog/vcrest"
@@ -16,15 +18,19 @@ func (s *Server) GetWork(ctx context.Context, request vcrest.GetWorkRequestObjec
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.GetWork400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.GetWork400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	expand, err := internal.ParseExpand(request.Params.Expand, internal.RelationParent, internal.RelationChildren)
+	if err != nil {
+		outResp = vcrest.GetWork400JSONResponse(invalidRequest(err))
 		return
 	}
 
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.GetWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
@@ -33,18 +39,24 @@ func (s *Server) GetWork(ctx context.Context, request vcrest.GetWorkRequestObjec
 
 	var kind internal.WorkKind
 	var bodyRaw json.RawMessage
+	var deletedAt *time.Time
+	var version int64
+	var parentUuid *uuid.UUID
+	includeDeleted := request.Params.IncludeDeleted != nil && *request.Params.IncludeDeleted
 	err = txn.QueryRow(ctx, `
-		SELECT kind, body
+		SELECT kind, body, deleted_at, version, parent_uuid
 		FROM works
-		WHERE uuid = $1
-	`, requestUuid).Scan(&kind, &bodyRaw)
+		WHERE uuid = $1 AND ($2 OR deleted_at IS NULL) AND `+internal.LibraryCondition("library_uuid", 3)+`
+	`, requestUuid, includeDeleted, internal.LibraryFromContext(ctx)).Scan(&kind, &bodyRaw, &deletedAt, &version, &parentUuid)
 	if errors.Is(err, pgx.ErrNoRows) {
 		outResp = vcrest.GetWork404JSONResponse{
+			Code:    internal.CodeNotFound,
 			Message: "work not found",
 		}
 		return
 	} else if err != nil {
 		outResp = vcrest.GetWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query work: %v", err),
 		}
 		return
@@ -52,42 +64,35 @@ func (s *Server) GetWork(ctx context.Context, request vcrest.GetWorkRequestObjec
 
 	if !kind.IsValid() {
 		outResp = vcrest.GetWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("invalid work kind in database: %s", kind),
 		}
 		return
 	}
 
-	switch kind {
-	case internal.WorkKindMovie:
-		var movieBody internal.MovieWork
-		if err := json.Unmarshal(bodyRaw, &movieBody); err != nil {
-			outResp = vcrest.GetWork500JSONResponse{
-				Message: fmt.Sprintf("failed to unmarshal movie work body: %v", err),
-			}
-			return
-		}
-		outResp = vcrest.GetWork200JSONResponse{
-			Uuid:  request.Uuid,
-			Movie: movieBody.ToAPI(),
-		}
-		return
-	case internal.WorkKindMovieEdition:
-		var editionBody internal.MovieEditionWork
-		if err := json.Unmarshal(bodyRaw, &editionBody); err != nil {
-			outResp = vcrest.GetWork500JSONResponse{
-				Message: fmt.Sprintf("failed to unmarshal movie edition work body: %v", err),
-			}
-			return
-		}
-		outResp = vcrest.GetWork200JSONResponse{
-			Uuid:         request.Uuid,
-			MovieEdition: editionBody.ToAPI(),
+	work, err := internal.WorkToAPI(requestUuid, kind, bodyRaw)
+	if err != nil {
+		outResp = vcrest.GetWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
 		}
 		return
-	default:
+	}
+	work.DeletedAt = deletedAt
+
+	if err := internal.ExpandWork(ctx, txn, work, parentUuid, expand); err != nil {
 		outResp = vcrest.GetWork500JSONResponse{
-			Message: fmt.Sprintf("unimplemented work kind: %s", kind),
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
 		}
 		return
 	}
+
+	outResp = vcrest.GetWork200JSONResponse{
+		Body: *work,
+		Headers: vcrest.GetWork200ResponseHeaders{
+			ETag: internal.ETag(version),
+		},
+	}
+	return
 }
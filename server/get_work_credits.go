@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// GetWorkCredits lists the people credited on a work.
+func (s *Server) GetWorkCredits(ctx context.Context, request vcrest.GetWorkCreditsRequestObject) (outResp vcrest.GetWorkCreditsResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.GetWorkCredits400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	var role *internal.CreditRole
+	if request.Params.Role != nil {
+		r := internal.CreditRole(*request.Params.Role)
+		if !r.IsValid() {
+			outResp = vcrest.GetWorkCredits400JSONResponse(fieldError("role", internal.ErrInvalid))
+			return
+		}
+		role = &r
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.GetWorkCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	var exists bool
+	err = txn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM works WHERE uuid = $1 AND deleted_at IS NULL AND `+internal.LibraryCondition("library_uuid", 2)+`)`, requestUuid, internal.LibraryFromContext(ctx)).Scan(&exists)
+	if err != nil {
+		outResp = vcrest.GetWorkCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query work: %v", err),
+		}
+		return
+	} else if !exists {
+		outResp = vcrest.GetWorkCredits404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	}
+
+	rows, err := txn.Query(ctx, `
+		SELECT c.person_uuid, c.role, c.character_name, c.billing_order, p.body
+		FROM credits c
+		INNER JOIN persons p ON p.uuid = c.person_uuid
+		WHERE c.work_uuid = $1 AND ($2::varchar IS NULL OR c.role = $2)
+		ORDER BY c.role, c.billing_order NULLS LAST, p.body->>'name', c.person_uuid
+	`, requestUuid, role)
+	if err != nil {
+		outResp = vcrest.GetWorkCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query credits: %v", err),
+		}
+		return
+	}
+
+	credits := []vcrest.Credit{}
+	row := internal.Credit{
+		WorkUUID: requestUuid,
+	}
+	var personUUID uuid.UUID
+	var personBody json.RawMessage
+	_, err = pgx.ForEachRow(rows, []any{&personUUID, &row.Role, &row.CharacterName, &row.BillingOrder, &personBody}, func() error {
+		row.PersonUUID = personUUID
+		credit := row.ToAPI()
+		person, err := internal.PersonToAPI(personUUID, personBody)
+		if err != nil {
+			return err
+		}
+		credit.Person = person
+		credits = append(credits, *credit)
+		return nil
+	})
+	if err != nil {
+		outResp = vcrest.GetWorkCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query and scan credits: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.GetWorkCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.GetWorkCredits200JSONResponse{
+		Credits: credits,
+	}
+	return
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+const (
+	historyPageTokenMagic = uint32(0x48495354) // "HIST" in ASCII
+)
+
+// History is listed newest first, so history page tokens record the ID of the last entry seen.
+func encodeHistoryPageToken(lastID int64) string {
+	buf := make([]byte, 4+8) // 4 bytes for magic + 8 bytes for ID
+	binary.BigEndian.PutUint32(buf[0:4], historyPageTokenMagic)
+	binary.BigEndian.PutUint64(buf[4:], uint64(lastID))
+	return base64.URLEncoding.EncodeToString(buf)
+}
+
+func decodeHistoryPageToken(tokenStr string) (int64, error) {
+	buf, err := base64.URLEncoding.DecodeString(tokenStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid page token encoding: %w", err)
+	}
+	if len(buf) != 12 {
+		return 0, fmt.Errorf("invalid page token length: expected 12, got %d", len(buf))
+	}
+	magic := binary.BigEndian.Uint32(buf[0:4])
+	if magic != historyPageTokenMagic {
+		return 0, fmt.Errorf("invalid page token magic: expected 0x%08x, got 0x%08x", historyPageTokenMagic, magic)
+	}
+	return int64(binary.BigEndian.Uint64(buf[4:])), nil
+}
+
+// GetWorkHistory retrieves the change history of the work with the given UUID
+func (s *Server) GetWorkHistory(ctx context.Context, request vcrest.GetWorkHistoryRequestObject) (outResp vcrest.GetWorkHistoryResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.GetWorkHistory400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	// Determine page size with reasonable bounds
+	pageSize := defaultPageSize
+	if request.Params.PageSize != nil {
+		ps := *request.Params.PageSize
+		if ps < minPageSize {
+			pageSize = minPageSize
+		} else if ps > maxPageSize {
+			pageSize = maxPageSize
+		} else {
+			pageSize = int(ps)
+		}
+	}
+
+	// Decode page token if provided
+	var lastID int64
+	if request.Params.PageToken != nil && *request.Params.PageToken != "" {
+		lastID, err = decodeHistoryPageToken(*request.Params.PageToken)
+		if err != nil {
+			outResp = vcrest.GetWorkHistory400JSONResponse{
+				Code:    internal.CodeInvalidPageToken,
+				Message: fmt.Sprintf("invalid page token: %v", err),
+			}
+			return
+		}
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.GetWorkHistory500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Fetch one extra to determine if there's a next page
+	entries, err := internal.ListHistory(ctx, txn, "works", requestUuid, lastID, pageSize+1)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.GetWorkHistory404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.GetWorkHistory500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.GetWorkHistory500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	hasMore := len(entries) > pageSize
+	if hasMore {
+		entries = entries[:pageSize]
+	}
+
+	response := vcrest.GetWorkHistory200JSONResponse{
+		Entries: []vcrest.WorkHistoryEntry{},
+	}
+	for _, entry := range entries {
+		apiEntry := vcrest.WorkHistoryEntry{
+			Id:         entry.ID,
+			Operation:  string(entry.Operation),
+			Actor:      entry.Actor,
+			RequestId:  entry.RequestID,
+			OnBehalfOf: entry.OnBehalfOf,
+			CreatedAt:  entry.CreatedAt,
+		}
+		if entry.OldBody != nil {
+			apiEntry.Before, err = internal.WorkToAPI(requestUuid, internal.WorkKind(entry.Kind), entry.OldBody)
+			if err != nil {
+				outResp = vcrest.GetWorkHistory500JSONResponse{
+					Code:    internal.CodeInternal,
+					Message: err.Error(),
+				}
+				return
+			}
+		}
+		apiEntry.After, err = internal.WorkToAPI(requestUuid, internal.WorkKind(entry.Kind), entry.NewBody)
+		if err != nil {
+			outResp = vcrest.GetWorkHistory500JSONResponse{
+				Code:    internal.CodeInternal,
+				Message: err.Error(),
+			}
+			return
+		}
+		response.Entries = append(response.Entries, apiEntry)
+	}
+
+	// Add next page token if there are more results
+	if hasMore {
+		token := encodeHistoryPageToken(entries[len(entries)-1].ID)
+		response.NextPageToken = &token
+	}
+
+	outResp = response
+	return
+}
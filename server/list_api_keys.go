@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ListApiKeys lists the API keys
+func (s *Server) ListApiKeys(ctx context.Context, request vcrest.ListApiKeysRequestObject) (outResp vcrest.ListApiKeysResponseObject, _ error) {
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ListApiKeys500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	apiKeys, err := internal.ListAPIKeys(ctx, txn)
+	if err != nil {
+		outResp = vcrest.ListApiKeys500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.ListApiKeys200JSONResponse{
+		ApiKeys: apiKeys,
+	}
+	return
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+const changeCursorMagic = uint32(0x43484e47) // "CHNG" in ASCII
+
+func encodeChangeCursor(cursor internal.ChangeCursor) string {
+	buf := make([]byte, 4+8+8) // 4 bytes for magic + 8 bytes for transaction ID + 8 bytes for sequence number
+	binary.BigEndian.PutUint32(buf[0:4], changeCursorMagic)
+	binary.BigEndian.PutUint64(buf[4:12], cursor.TxID)
+	binary.BigEndian.PutUint64(buf[12:20], uint64(cursor.Seq))
+	return base64.URLEncoding.EncodeToString(buf)
+}
+
+func decodeChangeCursor(cursorStr string) (internal.ChangeCursor, error) {
+	buf, err := base64.URLEncoding.DecodeString(cursorStr)
+	if err != nil {
+		return internal.ChangeCursor{}, fmt.Errorf("invalid cursor encoding: %w", err)
+	}
+	if len(buf) != 20 {
+		return internal.ChangeCursor{}, fmt.Errorf("invalid cursor length: expected 20, got %d", len(buf))
+	}
+	magic := binary.BigEndian.Uint32(buf[0:4])
+	if magic != changeCursorMagic {
+		return internal.ChangeCursor{}, fmt.Errorf("invalid cursor magic: expected 0x%08x, got 0x%08x", changeCursorMagic, magic)
+	}
+	return internal.ChangeCursor{
+		TxID: binary.BigEndian.Uint64(buf[4:12]),
+		Seq:  int64(binary.BigEndian.Uint64(buf[12:20])),
+	}, nil
+}
+
+// ListChanges lists the writes to works, sources and plans after a cursor.
+func (s *Server) ListChanges(ctx context.Context, request vcrest.ListChangesRequestObject) (outResp vcrest.ListChangesResponseObject, _ error) {
+	// Validate request.
+	pageSize := defaultPageSize
+	if request.Params.PageSize != nil {
+		pageSize = min(max(int(*request.Params.PageSize), minPageSize), maxPageSize)
+	}
+	var since internal.ChangeCursor
+	if request.Params.Since != nil && *request.Params.Since != "" {
+		var err error
+		since, err = decodeChangeCursor(*request.Params.Since)
+		if err != nil {
+			outResp = vcrest.ListChanges400JSONResponse{
+				Code:    internal.CodeInvalidPageToken,
+				Message: fmt.Sprintf("invalid cursor: %v", err),
+			}
+			return
+		}
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ListChanges500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	entries, err := internal.LoadChanges(ctx, txn, since, pageSize)
+	if err != nil {
+		outResp = vcrest.ListChanges500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ListChanges500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	response := vcrest.ListChanges200JSONResponse{
+		Changes:    make([]vcrest.Change, 0, len(entries)),
+		NextCursor: encodeChangeCursor(since),
+	}
+	for _, entry := range entries {
+		response.Changes = append(response.Changes, entry.Change)
+		response.NextCursor = encodeChangeCursor(entry.Cursor)
+	}
+	outResp = response
+	return
+}
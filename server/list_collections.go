@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ListCollections lists all collections ordered by name.
+func (s *Server) ListCollections(ctx context.Context, request vcrest.ListCollectionsRequestObject) (outResp vcrest.ListCollectionsResponseObject, _ error) {
+	rows, err := s.Pool.Query(ctx, `
+		SELECT uuid, body
+		FROM collections
+		ORDER BY lower(body->>'name'), uuid
+	`)
+	if err != nil {
+		outResp = vcrest.ListCollections500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query collections: %v", err),
+		}
+		return
+	}
+
+	collections := []vcrest.Collection{}
+	var id uuid.UUID
+	var bodyRaw json.RawMessage
+	_, err = pgx.ForEachRow(rows, []any{&id, &bodyRaw}, func() error {
+		collection, err := internal.CollectionToAPI(id, bodyRaw)
+		if err != nil {
+			return err
+		}
+		collections = append(collections, *collection)
+		return nil
+	})
+	if err != nil {
+		outResp = vcrest.ListCollections500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query and scan collections: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.ListCollections200JSONResponse{
+		Collections: collections,
+	}
+	return
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ListGenres lists the controlled vocabulary of genres.
+func (s *Server) ListGenres(ctx context.Context, request vcrest.ListGenresRequestObject) (outResp vcrest.ListGenresResponseObject, _ error) {
+	rows, err := s.Pool.Query(ctx, `SELECT name FROM genres ORDER BY name`)
+	if err != nil {
+		outResp = vcrest.ListGenres500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query genres: %v", err),
+		}
+		return
+	}
+	genres, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		outResp = vcrest.ListGenres500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to scan genres: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.ListGenres200JSONResponse{
+		Genres: genres,
+	}
+	return
+}
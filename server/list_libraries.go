@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ListLibraries lists the libraries
+func (s *Server) ListLibraries(ctx context.Context, request vcrest.ListLibrariesRequestObject) (outResp vcrest.ListLibrariesResponseObject, _ error) {
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ListLibraries500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	libraries, err := internal.ListLibraries(ctx, txn)
+	if err != nil {
+		outResp = vcrest.ListLibraries500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.ListLibraries200JSONResponse{
+		Libraries: libraries,
+	}
+	return
+}
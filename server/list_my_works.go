@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ListMyWorks lists the works in the library with the caller's watch state of each, such as the unwatched
+// movies that are in the collection.
+func (s *Server) ListMyWorks(ctx context.Context, request vcrest.ListMyWorksRequestObject) (outResp vcrest.ListMyWorksResponseObject, _ error) {
+	// Validate request.
+	pageSize, lastUUID, err := reportPageParams(request.Params.PageSize, request.Params.PageToken)
+	if err != nil {
+		outResp = vcrest.ListMyWorks400JSONResponse{
+			Code:    internal.CodeInvalidPageToken,
+			Message: fmt.Sprintf("invalid page token: %v", err),
+		}
+		return
+	}
+	filter := internal.WatchFilter{
+		Watched:     request.Params.Watched,
+		OnWatchlist: request.Params.OnWatchlist,
+		MinRating:   request.Params.MinRating,
+		Completed:   request.Params.Completed,
+	}
+	if request.Params.Kind != nil {
+		kind := internal.WorkKind(*request.Params.Kind)
+		if !kind.IsValid() {
+			outResp = vcrest.ListMyWorks400JSONResponse(fieldError("kind", internal.ErrInvalid))
+			return
+		}
+		filter.Kind = &kind
+	}
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		outResp = vcrest.ListMyWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ListMyWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Fetch one extra to determine if there's a next page.
+	works, err := internal.ListWatchedWorks(ctx, txn, userID, filter, lastUUID, pageSize+1)
+	if err != nil {
+		outResp = vcrest.ListMyWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ListMyWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	response := vcrest.ListMyWorks200JSONResponse{
+		Works: works[:min(len(works), pageSize)],
+	}
+	if len(works) > pageSize {
+		token := encodeReportPageToken(uuid.UUID(response.Works[pageSize-1].Work.Uuid))
+		response.NextPageToken = &token
+	}
+
+	outResp = response
+	return
+}
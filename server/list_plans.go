@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -76,12 +77,19 @@ func (s *Server) ListPlans(ctx context.Context, request vcrest.ListPlansRequestO
 		lastUUID, err = decodePageToken(*request.Params.PageToken)
 		if err != nil {
 			outResp = vcrest.ListPlans400JSONResponse{
+				Code:    internal.CodeInvalidPageToken,
 				Message: fmt.Sprintf("invalid page token: %v", err),
 			}
 			return
 		}
 	}
 
+	expand, err := internal.ParseExpand(request.Params.Expand, internal.RelationSource, internal.RelationWork)
+	if err != nil {
+		outResp = vcrest.ListPlans400JSONResponse(invalidRequest(err))
+		return
+	}
+
 	// Parse optional filter UUIDs
 	var sourceUUID uuid.UUID
 	var workUUID uuid.UUID
@@ -89,9 +97,7 @@ func (s *Server) ListPlans(ctx context.Context, request vcrest.ListPlansRequestO
 		var err error
 		sourceUUID, err = internal.AsUUID(request.Params.SourceUuid)
 		if err != nil {
-			outResp = vcrest.ListPlans400JSONResponse{
-				Message: "invalid sourceUuid format",
-			}
+			outResp = vcrest.ListPlans400JSONResponse(fieldError("sourceUuid", internal.ErrInvalidUUID))
 			return
 		}
 	}
@@ -99,9 +105,7 @@ func (s *Server) ListPlans(ctx context.Context, request vcrest.ListPlansRequestO
 		var err error
 		workUUID, err = internal.AsUUID(request.Params.WorkUuid)
 		if err != nil {
-			outResp = vcrest.ListPlans400JSONResponse{
-				Message: "invalid workUuid format",
-			}
+			outResp = vcrest.ListPlans400JSONResponse(fieldError("workUuid", internal.ErrInvalidUUID))
 			return
 		}
 	}
@@ -109,6 +113,7 @@ func (s *Server) ListPlans(ctx context.Context, request vcrest.ListPlansRequestO
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.ListPlans500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
@@ -117,13 +122,25 @@ func (s *Server) ListPlans(ctx context.Context, request vcrest.ListPlansRequestO
 
 	// Build query with optional joins and filters
 	query := `
-		SELECT DISTINCT p.uuid, p.kind, p.body
+		SELECT DISTINCT p.uuid, p.kind, p.body, p.deleted_at, p.completed_at
 		FROM plans p`
 
 	args := []any{}
 	argIdx := 1
 	whereConditions := []string{}
 
+	// Hide deleted plans unless asked for them
+	if request.Params.IncludeDeleted == nil || !*request.Params.IncludeDeleted {
+		whereConditions = append(whereConditions, "p.deleted_at IS NULL")
+	}
+
+	// Limit to the requested library
+	if library := internal.LibraryFromContext(ctx); library != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("p.library_uuid = $%d", argIdx))
+		args = append(args, *library)
+		argIdx++
+	}
+
 	// Add join for source UUID filter
 	if sourceUUID != uuid.Nil {
 		query += `
@@ -142,6 +159,21 @@ func (s *Server) ListPlans(ctx context.Context, request vcrest.ListPlansRequestO
 		argIdx++
 	}
 
+	// Add tag filter, matching tags on either the works or the sources of the plan
+	if request.Params.Tag != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf(`(
+			EXISTS (
+				SELECT 1 FROM plan_outputs tpo
+				INNER JOIN work_tags wt ON wt.work_uuid = tpo.work_uuid
+				WHERE tpo.plan_uuid = p.uuid AND wt.tag = $%d)
+			OR EXISTS (
+				SELECT 1 FROM plan_inputs tpi
+				INNER JOIN source_tags st ON st.source_uuid = tpi.source_uuid
+				WHERE tpi.plan_uuid = p.uuid AND st.tag = $%d))`, argIdx, argIdx))
+		args = append(args, *request.Params.Tag)
+		argIdx++
+	}
+
 	// Add pagination filter
 	if lastUUID != uuid.Nil {
 		whereConditions = append(whereConditions, fmt.Sprintf("p.uuid > $%d", argIdx))
@@ -171,21 +203,24 @@ func (s *Server) ListPlans(ctx context.Context, request vcrest.ListPlansRequestO
 	hasMore := false
 
 	type planRow struct {
-		uuid    uuid.UUID
-		kind    internal.PlanKind
-		bodyRaw json.RawMessage
+		uuid        uuid.UUID
+		kind        internal.PlanKind
+		bodyRaw     json.RawMessage
+		deletedAt   *time.Time
+		completedAt *time.Time
 	}
 
 	var row planRow
 	rows, err := txn.Query(ctx, query, args...)
 	if err != nil {
 		outResp = vcrest.ListPlans500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query plans: %v", err),
 		}
 		return
 	}
 
-	_, err = pgx.ForEachRow(rows, []any{&row.uuid, &row.kind, &row.bodyRaw}, func() error {
+	_, err = pgx.ForEachRow(rows, []any{&row.uuid, &row.kind, &row.bodyRaw, &row.deletedAt, &row.completedAt}, func() error {
 		if len(plans) >= pageSize {
 			hasMore = true
 			return nil
@@ -196,7 +231,9 @@ func (s *Server) ListPlans(ctx context.Context, request vcrest.ListPlansRequestO
 		}
 
 		plan := vcrest.Plan{
-			Uuid: openapi_types.UUID(row.uuid),
+			Uuid:        openapi_types.UUID(row.uuid),
+			DeletedAt:   row.deletedAt,
+			CompletedAt: row.completedAt,
 		}
 
 		switch row.kind {
@@ -222,13 +259,28 @@ func (s *Server) ListPlans(ctx context.Context, request vcrest.ListPlansRequestO
 	})
 	if err != nil {
 		outResp = vcrest.ListPlans500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query and scan plans: %v", err),
 		}
 		return
 	}
 
+	// Related entities are read for the whole page at once.
+	planPtrs := make([]*vcrest.Plan, len(plans))
+	for i := range plans {
+		planPtrs[i] = &plans[i]
+	}
+	if err := internal.ExpandPlans(ctx, txn, planPtrs, expand); err != nil {
+		outResp = vcrest.ListPlans500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
 	if err := txn.Commit(ctx); err != nil {
 		outResp = vcrest.ListPlans500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to commit transaction: %v", err),
 		}
 		return
@@ -244,6 +296,7 @@ func (s *Server) ListPlans(ctx context.Context, request vcrest.ListPlansRequestO
 		token, err := encodePageToken(nextPageLastUUID)
 		if err != nil {
 			outResp = vcrest.ListPlans500JSONResponse{
+				Code:    internal.CodeInternal,
 				Message: fmt.Sprintf("failed to encode page token: %v", err),
 			}
 			return
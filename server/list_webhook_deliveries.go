@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+const deliveryPageTokenMagic = uint32(0x57484b44) // "WHKD" in ASCII
+
+func encodeDeliveryPageToken(lastID int64) string {
+	buf := make([]byte, 4+8) // 4 bytes for magic + 8 bytes for delivery ID
+	binary.BigEndian.PutUint32(buf[0:4], deliveryPageTokenMagic)
+	binary.BigEndian.PutUint64(buf[4:], uint64(lastID))
+	return base64.URLEncoding.EncodeToString(buf)
+}
+
+func decodeDeliveryPageToken(tokenStr string) (int64, error) {
+	buf, err := base64.URLEncoding.DecodeString(tokenStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid page token encoding: %w", err)
+	}
+	if len(buf) != 12 {
+		return 0, fmt.Errorf("invalid page token length: expected 12, got %d", len(buf))
+	}
+	magic := binary.BigEndian.Uint32(buf[0:4])
+	if magic != deliveryPageTokenMagic {
+		return 0, fmt.Errorf("invalid page token magic: expected 0x%08x, got 0x%08x", deliveryPageTokenMagic, magic)
+	}
+	return int64(binary.BigEndian.Uint64(buf[4:])), nil
+}
+
+// ListWebhookDeliveries lists the deliveries of events to a webhook, newest first
+func (s *Server) ListWebhookDeliveries(ctx context.Context, request vcrest.ListWebhookDeliveriesRequestObject) (outResp vcrest.ListWebhookDeliveriesResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.ListWebhookDeliveries400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	pageSize := defaultPageSize
+	if request.Params.PageSize != nil {
+		pageSize = min(max(int(*request.Params.PageSize), minPageSize), maxPageSize)
+	}
+	var lastID int64
+	if request.Params.PageToken != nil && *request.Params.PageToken != "" {
+		lastID, err = decodeDeliveryPageToken(*request.Params.PageToken)
+		if err != nil {
+			outResp = vcrest.ListWebhookDeliveries400JSONResponse{
+				Code:    internal.CodeInvalidPageToken,
+				Message: fmt.Sprintf("invalid page token: %v", err),
+			}
+			return
+		}
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ListWebhookDeliveries500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	if _, err := internal.GetWebhook(ctx, txn, requestUuid); errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.ListWebhookDeliveries404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "webhook not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.ListWebhookDeliveries500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	// Fetch one extra to determine if there's a next page.
+	deliveries, err := internal.ListWebhookDeliveries(ctx, txn, requestUuid, lastID, pageSize+1)
+	if err != nil {
+		outResp = vcrest.ListWebhookDeliveries500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ListWebhookDeliveries500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	response := vcrest.ListWebhookDeliveries200JSONResponse{}
+	if len(deliveries) > pageSize {
+		deliveries = deliveries[:pageSize]
+		token := encodeDeliveryPageToken(deliveries[pageSize-1].Id)
+		response.NextPageToken = &token
+	}
+	response.Deliveries = deliveries
+	outResp = response
+	return
+}
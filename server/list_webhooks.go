@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ListWebhooks lists the webhook subscriptions
+func (s *Server) ListWebhooks(ctx context.Context, request vcrest.ListWebhooksRequestObject) (outResp vcrest.ListWebhooksResponseObject, _ error) {
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ListWebhooks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	webhooks, err := internal.ListWebhooks(ctx, txn)
+	if err != nil {
+		outResp = vcrest.ListWebhooks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.ListWebhooks200JSONResponse{
+		Webhooks: webhooks,
+	}
+	return
+}
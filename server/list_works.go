@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+const (
+	workPageTokenMagic = uint32(0x574f524b) // "WORK" in ASCII
+)
+
+// Works are ordered by sort title, so work page tokens record the sort title of the last work
+// seen in addition to its UUID.
+type workPageToken struct {
+	Magic         uint32
+	LastUUID      uuid.UUID
+	LastSortTitle string
+}
+
+func encodeWorkPageToken(lastUUID uuid.UUID, lastSortTitle string) string {
+	token := workPageToken{
+		Magic:         workPageTokenMagic,
+		LastUUID:      lastUUID,
+		LastSortTitle: lastSortTitle,
+	}
+	buf := make([]byte, 4+16+len(token.LastSortTitle)) // 4 bytes for magic + 16 bytes for UUID + sort title
+	binary.BigEndian.PutUint32(buf[0:4], token.Magic)
+	copy(buf[4:20], token.LastUUID[:])
+	copy(buf[20:], token.LastSortTitle)
+	return base64.URLEncoding.EncodeToString(buf)
+}
+
+func decodeWorkPageToken(tokenStr string) (uuid.UUID, string, error) {
+	buf, err := base64.URLEncoding.DecodeString(tokenStr)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("invalid page token encoding: %w", err)
+	}
+	if len(buf) < 20 {
+		return uuid.Nil, "", fmt.Errorf("invalid page token length: expected at least 20, got %d", len(buf))
+	}
+	magic := binary.BigEndian.Uint32(buf[0:4])
+	if magic != workPageTokenMagic {
+		return uuid.Nil, "", fmt.Errorf("invalid page token magic: expected 0x%08x, got 0x%08x", workPageTokenMagic, magic)
+	}
+	var lastUUID uuid.UUID
+	copy(lastUUID[:], buf[4:20])
+	return lastUUID, string(buf[20:]), nil
+}
+
+// ListWorks lists works ordered by sort title.
+func (s *Server) ListWorks(ctx context.Context, request vcrest.ListWorksRequestObject) (outResp vcrest.ListWorksResponseObject, _ error) {
+	// Determine page size with reasonable bounds
+	pageSize := defaultPageSize
+	if request.Params.PageSize != nil {
+		ps := *request.Params.PageSize
+		if ps < minPageSize {
+			pageSize = minPageSize
+		} else if ps > maxPageSize {
+			pageSize = maxPageSize
+		} else {
+			pageSize = int(ps)
+		}
+	}
+
+	// Decode page token if provided
+	var lastUUID uuid.UUID
+	var lastSortTitle string
+	if request.Params.PageToken != nil && *request.Params.PageToken != "" {
+		var err error
+		lastUUID, lastSortTitle, err = decodeWorkPageToken(*request.Params.PageToken)
+		if err != nil {
+			outResp = vcrest.ListWorks400JSONResponse{
+				Code:    internal.CodeInvalidPageToken,
+				Message: fmt.Sprintf("invalid page token: %v", err),
+			}
+			return
+		}
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ListWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Build query with optional filters
+	query := `
+		SELECT w.uuid, w.kind, w.body, work_sort_title(w.body), w.deleted_at
+		FROM works w`
+
+	args := []any{}
+	argIdx := 1
+	whereConditions := []string{}
+
+	// Hide deleted works unless asked for them
+	if request.Params.IncludeDeleted == nil || !*request.Params.IncludeDeleted {
+		whereConditions = append(whereConditions, "w.deleted_at IS NULL")
+	}
+
+	// Limit to the requested library
+	if library := internal.LibraryFromContext(ctx); library != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("w.library_uuid = $%d", argIdx))
+		args = append(args, *library)
+		argIdx++
+	}
+
+	// Add tag filter
+	if request.Params.Tag != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("EXISTS (SELECT 1 FROM work_tags wt WHERE wt.work_uuid = w.uuid AND wt.tag = $%d)", argIdx))
+		args = append(args, *request.Params.Tag)
+		argIdx++
+	}
+
+	// Add pagination filter
+	if lastUUID != uuid.Nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("(work_sort_title(w.body), w.uuid) > ($%d, $%d)", argIdx, argIdx+1))
+		args = append(args, lastSortTitle, lastUUID)
+		argIdx += 2
+	}
+
+	// Add WHERE clause if we have conditions
+	if len(whereConditions) > 0 {
+		query += "\n\t\tWHERE "
+		for i, cond := range whereConditions {
+			if i > 0 {
+				query += " AND "
+			}
+			query += cond
+		}
+	}
+
+	// Add ordering and limit
+	query += fmt.Sprintf(`
+		ORDER BY work_sort_title(w.body), w.uuid
+		LIMIT $%d`, argIdx)
+	args = append(args, pageSize+1) // Fetch one extra to determine if there's a next page
+
+	works := []vcrest.Work{}
+	var nextPageLastUUID uuid.UUID
+	var nextPageLastSortTitle string
+	hasMore := false
+
+	type workRow struct {
+		uuid      uuid.UUID
+		kind      internal.WorkKind
+		bodyRaw   json.RawMessage
+		sortTitle string
+		deletedAt *time.Time
+	}
+
+	var row workRow
+	rows, err := txn.Query(ctx, query, args...)
+	if err != nil {
+		outResp = vcrest.ListWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query works: %v", err),
+		}
+		return
+	}
+
+	_, err = pgx.ForEachRow(rows, []any{&row.uuid, &row.kind, &row.bodyRaw, &row.sortTitle, &row.deletedAt}, func() error {
+		if len(works) >= pageSize {
+			hasMore = true
+			return nil
+		}
+
+		if !row.kind.IsValid() {
+			return fmt.Errorf("invalid work kind in database: %s", row.kind)
+		}
+
+		work, err := internal.WorkToAPI(row.uuid, row.kind, row.bodyRaw)
+		if err != nil {
+			return err
+		}
+		work.DeletedAt = row.deletedAt
+
+		works = append(works, *work)
+		nextPageLastUUID = row.uuid
+		nextPageLastSortTitle = row.sortTitle
+		return nil
+	})
+	if err != nil {
+		outResp = vcrest.ListWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query and scan works: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ListWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	// Build response
+	response := vcrest.ListWorks200JSONResponse{
+		Works: works,
+	}
+
+	// Add next page token if there are more results
+	if hasMore && nextPageLastUUID != uuid.Nil {
+		token := encodeWorkPageToken(nextPageLastUUID, nextPageLastSortTitle)
+		response.NextPageToken = &token
+	}
+
+	outResp = response
+	return
+}
@@ -2,20 +2,57 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/krelinga/video-catalog/internal"
-	"github.com/krelinga/video-catalog/vcrest"
+	"github.com/riverqueue/river"
+)
+
+const (
+	// healthcheckTimeout bounds the request made by the healthcheck command.
+	healthcheckTimeout = 5 * time.Second
+
+	// jobCancelTimeout is how long the server waits for cancelled background jobs to return, once the
+	// shutdown deadline has passed.
+	jobCancelTimeout = 5 * time.Second
 )
 
 func main() {
+	// The image has no HTTP client, so the server checks itself for the HEALTHCHECK of the Dockerfile.
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		if err := healthcheck(); err != nil {
+			log.Fatalf("health check failed: %v", err)
+		}
+		return
+	}
+
 	if err := run(); err != nil {
 		log.Fatalf("server error: %v", err)
 	}
 }
 
+// healthcheck checks that the server listening on the configured port is alive.
+func healthcheck() error {
+	client := &http.Client{Timeout: healthcheckTimeout}
+	resp, err := client.Get(fmt.Sprintf("http://localhost:%s/healthz", os.Getenv(internal.EnvServerPort)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET /healthz returned %s", resp.Status)
+	}
+	return nil
+}
+
 func run() error {
 	ctx := context.Background()
 
@@ -36,24 +73,94 @@ func run() error {
 	}
 	log.Println("Migrations complete")
 
+	// Start background jobs
+	riverClient, err := internal.NewRiverClient(pool, cfg)
+	if err != nil {
+		return err
+	}
+	if err := riverClient.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start background jobs: %w", err)
+	}
+
+	// Start listening for changes
+	notifier := internal.NewChangeNotifier(pool)
+	notifyCtx, stopNotifier := context.WithCancel(ctx)
+	defer stopNotifier()
+	go notifier.Run(notifyCtx)
+
 	// Create server instance
+	stopping := make(chan struct{})
 	srv := &Server{
-		Config: cfg,
-		Pool:  pool,
+		Config:   cfg,
+		Pool:     pool,
+		Notifier: notifier,
+		Jobs:     riverClient,
+		Stopping: stopping,
+	}
+	if cfg.OIDC != nil {
+		srv.Tokens = internal.NewTokenVerifier(cfg.OIDC)
 	}
-	strictHandler := vcrest.NewStrictHandler(srv, nil)
-	httpHandler := vcrest.Handler(strictHandler)
-
 	httpServer := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.ServerPort),
-		Handler: httpHandler,
+		Addr:         fmt.Sprintf(":%d", cfg.ServerPort),
+		Handler:      withAudit(withRecovery(withMaxBodySize(srv.Handler(), cfg.MaxBodyBytes))),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
 	}
+	httpServer.RegisterOnShutdown(func() { close(stopping) })
+
+	// Shut down gracefully when the container is stopped or the server is interrupted.  The signals are only
+	// caught from here on, since there is nothing to drain before the server starts.
+	signalCtx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
 
 	// Start HTTP server
-	log.Println("Starting HTTP server on port", cfg.ServerPort)
-	if err := httpServer.ListenAndServe(); err != nil {
+	log.Printf("Starting HTTP server %s (%s) on port %d", internal.Version, internal.BuildCommit(), cfg.ServerPort)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+	select {
+	case err := <-serveErr:
+		stopCtx, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+		defer cancel()
+		stopJobs(stopCtx, riverClient)
 		return fmt.Errorf("server failed: %w", err)
+	case <-signalCtx.Done():
 	}
 
+	// Stop accepting requests and let the ones in flight finish, so that their transactions are not cut
+	// off.  Requests still running at the deadline are cancelled.  A second signal kills the server.
+	log.Printf("Shutting down, waiting up to %s for requests and jobs to finish", cfg.ShutdownTimeout)
+	stop()
+	shutdownCtx, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Requests did not finish in time: %v", err)
+		httpServer.Close()
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+
+	// Requests can enqueue jobs, so jobs are stopped once the requests are done, within what is left of
+	// the deadline.
+	stopJobs(shutdownCtx, riverClient)
+	log.Println("Shutdown complete")
 	return nil
 }
+
+// stopJobs stops the background jobs, letting the running ones finish until ctx is done and then cancelling
+// them.
+func stopJobs(ctx context.Context, client *river.Client[pgx.Tx]) {
+	err := client.Stop(ctx)
+	if err == nil {
+		return
+	}
+	log.Printf("Background jobs did not finish in time: %v", err)
+	cancelCtx, cancel := context.WithTimeout(context.Background(), jobCancelTimeout)
+	defer cancel()
+	if err := client.StopAndCancel(cancelCtx); err != nil {
+		log.Printf("Failed to cancel background jobs: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+)
+
+const (
+	headerRequestID  = "X-Request-Id"
+	headerOnBehalfOf = "X-On-Behalf-Of"
+	headerLibrary    = "X-Library"
+)
+
+// libraryPathPrefix starts the paths of requests that name their library in the path.
+const libraryPathPrefix = "/libraries/"
+
+// withAudit tags each request with a request ID and whoever the client says it acts on behalf of, so that
+// changes made while handling it can be attributed in the entity history.  The actor is always the
+// authenticated caller, which withAuthentication adds.  A request ID supplied by the client is kept,
+// otherwise a new one is generated; either way it is echoed back in the response.
+func withAudit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(headerRequestID)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(headerRequestID, requestID)
+
+		ctx := internal.WithAudit(r.Context(), internal.Audit{
+			RequestID:  requestID,
+			OnBehalfOf: r.Header.Get(headerOnBehalfOf),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withLibrary scopes each request to the library named by a /libraries/{uuid} prefix of its path or by the
+// X-Library header, and strips the prefix so that the rest of the path is routed as usual.  Requests that
+// name neither keep the library of the context they are made in, which for requests from clients is the
+// default library.  Requests that name a library that does not exist are rejected with a 404 error.
+func (s *Server) withLibrary(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var fromPath, fromHeader *uuid.UUID
+		if rawID, path, ok := cutLibraryPrefix(r.URL.Path); ok {
+			id, err := uuid.Parse(rawID)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fieldError("libraryUuid", internal.ErrInvalidUUID))
+				return
+			}
+			fromPath = &id
+			u := *r.URL
+			u.Path, u.RawPath = path, ""
+			r = r.WithContext(ctx)
+			r.URL = &u
+		}
+		if raw := r.Header.Get(headerLibrary); raw != "" {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fieldError(headerLibrary, internal.ErrInvalidUUID))
+				return
+			}
+			fromHeader = &id
+		}
+
+		var library uuid.UUID
+		switch {
+		case fromPath != nil && fromHeader != nil && *fromPath != *fromHeader:
+			writeError(w, http.StatusBadRequest, apiError(internal.CodeInvalidRequest, "the library in the path and the X-Library header differ"))
+			return
+		case fromPath != nil:
+			library = *fromPath
+		case fromHeader != nil:
+			library = *fromHeader
+		case internal.LibraryFromContext(ctx) != nil:
+			next.ServeHTTP(w, r)
+			return
+		default:
+			next.ServeHTTP(w, r.WithContext(internal.WithLibrary(ctx, internal.DefaultLibrary)))
+			return
+		}
+
+		if _, err := internal.GetLibrary(ctx, s.Pool, library); errors.Is(err, internal.ErrNotFound) {
+			writeError(w, http.StatusNotFound, apiError(internal.CodeNotFound, "library not found"))
+			return
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, err.Error()))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(internal.WithLibrary(ctx, library)))
+	})
+}
+
+// cutLibraryPrefix splits a path that starts with /libraries/{uuid}/ into the library UUID and the rest of
+// the path.  A path with nothing after the library UUID is about the library itself, so it is not cut.
+func cutLibraryPrefix(path string) (rawID, rest string, ok bool) {
+	after, ok := strings.CutPrefix(path, libraryPathPrefix)
+	if !ok {
+		return "", "", false
+	}
+	rawID, rest, ok = strings.Cut(after, "/")
+	if !ok || rest == "" {
+		return "", "", false
+	}
+	return rawID, "/" + rest, true
+}
+
+// withRecovery turns a panic in a handler into a 500 error, so that one bad request does not take down the
+// server.  If the handler already started its response, the status cannot change, so the connection is
+// aborted instead and the client sees a truncated response.
+func withRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recoveryWriter{ResponseWriter: w}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			log.Printf("Panic handling %s %s (request %s): %v\n%s", r.Method, r.URL.Path, w.Header().Get(headerRequestID), rec, debug.Stack())
+			if rw.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, "internal server error"))
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// recoveryWriter records whether a response has been started.
+type recoveryWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *recoveryWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recoveryWriter) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, which event streams need to flush.
+func (w *recoveryWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// withMaxBodySize rejects request bodies larger than limit bytes with a 413 error.  Bodies that declare
+// their length are rejected up front; others fail when they are read past the limit.  A limit of zero or
+// less means no limit.
+func withMaxBodySize(next http.Handler, limit int64) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			err := &http.MaxBytesError{Limit: limit}
+			body, _ := requestTooLarge(err)
+			writeError(w, http.StatusRequestEntityTooLarge, body)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -16,13 +16,12 @@ func (s *Server) PatchChapterRangePlan(ctx context.Context, request vcrest.Patch
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PatchChapterRangePlan400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PatchChapterRangePlan400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PatchChapterRangePlan400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
@@ -31,9 +30,7 @@ func (s *Server) PatchChapterRangePlan(ctx context.Context, request vcrest.Patch
 		internal.FieldNotNull(request.Body.SourceUuid),
 		internal.FieldValidUUID(request.Body.SourceUuid),
 	); err != nil {
-		outResp = vcrest.PatchChapterRangePlan400JSONResponse{
-			Message: fmt.Sprintf("SourceUuid: %v", err),
-		}
+		outResp = vcrest.PatchChapterRangePlan400JSONResponse(fieldError("sourceUuid", err))
 		return
 	}
 	sourceUuid := internal.FieldMayUUID(request.Body.SourceUuid)
@@ -42,9 +39,7 @@ func (s *Server) PatchChapterRangePlan(ctx context.Context, request vcrest.Patch
 		internal.FieldNotNull(request.Body.WorkUuid),
 		internal.FieldValidUUID(request.Body.WorkUuid),
 	); err != nil {
-		outResp = vcrest.PatchChapterRangePlan400JSONResponse{
-			Message: fmt.Sprintf("WorkUuid: %v", err),
-		}
+		outResp = vcrest.PatchChapterRangePlan400JSONResponse(fieldError("workUuid", err))
 		return
 	}
 	workUuid := internal.FieldMayUUID(request.Body.WorkUuid)
@@ -52,6 +47,7 @@ func (s *Server) PatchChapterRangePlan(ctx context.Context, request vcrest.Patch
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.PatchChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
@@ -60,32 +56,45 @@ func (s *Server) PatchChapterRangePlan(ctx context.Context, request vcrest.Patch
 
 	var kind internal.PlanKind
 	var rawBody json.RawMessage
+	var version int64
 	row := txn.QueryRow(ctx, `
-		SELECT kind, body
+		SELECT kind, body, version
 		FROM plans
-		WHERE uuid = $1
-	`, requestUuid)
-	err = row.Scan(&kind, &rawBody)
+		WHERE uuid = $1 AND deleted_at IS NULL AND `+internal.LibraryCondition("library_uuid", 2)+`
+		FOR UPDATE
+	`, requestUuid, internal.LibraryFromContext(ctx))
+	err = row.Scan(&kind, &rawBody, &version)
 	if errors.Is(err, pgx.ErrNoRows) {
 		outResp = vcrest.PatchChapterRangePlan404JSONResponse{
+			Code:    internal.CodeNotFound,
 			Message: "plan not found",
 		}
 		return
 	} else if err != nil {
 		outResp = vcrest.PatchChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query plan: %v", err),
 		}
 		return
 	} else if kind != internal.PlanKindChapterRange {
 		outResp = vcrest.PatchChapterRangePlan409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "plan is not a chapter range plan",
 		}
 		return
 	}
+	if err := (internal.Preconditions{IfMatch: request.Params.IfMatch}).Check(&version); err != nil {
+		outResp = vcrest.PatchChapterRangePlan412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "plan does not match the If-Match precondition",
+		}
+		return
+	}
 
 	var body internal.ChapterRangePlan
 	if err := json.Unmarshal(rawBody, &body); err != nil {
 		outResp = vcrest.PatchChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to unmarshal plan body: %v", err),
 		}
 		return
@@ -100,29 +109,45 @@ func (s *Server) PatchChapterRangePlan(ctx context.Context, request vcrest.Patch
 	internal.FieldSetClear(request.Body.StartChapter, &body.StartChapter)
 	internal.FieldSetClear(request.Body.EndChapter, &body.EndChapter)
 
+	oldBody := rawBody
 	rawBody, err = json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PatchChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
 	}
 
-	_, err = txn.Exec(ctx, `
+	err = txn.QueryRow(ctx, `
 		UPDATE plans
 		SET body = $2
 		WHERE uuid = $1
-	`, requestUuid, rawBody)
+		RETURNING version
+	`, requestUuid, rawBody).Scan(&version)
 	if err != nil {
 		outResp = vcrest.PatchChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to update plan: %v", err),
 		}
 		return
 	}
 
+	if err := internal.RecordHistory(ctx, txn, "plans", requestUuid, kind, internal.HistoryUpdate, oldBody, rawBody); err != nil {
+		outResp = vcrest.PatchChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
 	if sourceUuid != nil {
-		if err := internal.UpdatePlanInputs(ctx, txn, requestUuid, *sourceUuid); err != nil {
+		if err := internal.UpdatePlanInputs(ctx, txn, requestUuid, *sourceUuid); errors.Is(err, internal.ErrMissingReference) {
+			outResp = vcrest.PatchChapterRangePlan409JSONResponse(missingReference(err))
+			return
+		} else if err != nil {
 			outResp = vcrest.PatchChapterRangePlan500JSONResponse{
+				Code:    internal.CodeInternal,
 				Message: err.Error(),
 			}
 			return
@@ -130,8 +155,12 @@ func (s *Server) PatchChapterRangePlan(ctx context.Context, request vcrest.Patch
 	}
 
 	if workUuid != nil {
-		if err := internal.UpdatePlanOutputs(ctx, txn, requestUuid, *workUuid); err != nil {
+		if err := internal.UpdatePlanOutputs(ctx, txn, requestUuid, *workUuid); errors.Is(err, internal.ErrMissingReference) {
+			outResp = vcrest.PatchChapterRangePlan409JSONResponse(missingReference(err))
+			return
+		} else if err != nil {
 			outResp = vcrest.PatchChapterRangePlan500JSONResponse{
+				Code:    internal.CodeInternal,
 				Message: err.Error(),
 			}
 			return
@@ -140,11 +169,16 @@ func (s *Server) PatchChapterRangePlan(ctx context.Context, request vcrest.Patch
 
 	if err := txn.Commit(ctx); err != nil {
 		outResp = vcrest.PatchChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to commit transaction: %v", err),
 		}
 		return
 	}
 
-	outResp = vcrest.PatchChapterRangePlan200Response{}
+	outResp = vcrest.PatchChapterRangePlan200Response{
+		Headers: vcrest.PatchChapterRangePlan200ResponseHeaders{
+			ETag: internal.ETag(version),
+		},
+	}
 	return
 }
@@ -16,13 +16,12 @@ func (s *Server) PatchDirectPlan(ctx context.Context, request vcrest.PatchDirect
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PatchDirectPlan400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PatchDirectPlan400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PatchDirectPlan400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
@@ -32,9 +31,7 @@ func (s *Server) PatchDirectPlan(ctx context.Context, request vcrest.PatchDirect
 		internal.FieldNotNull(request.Body.SourceUuid),
 		internal.FieldValidUUID(request.Body.SourceUuid),
 	); err != nil {
-		outResp = vcrest.PatchDirectPlan400JSONResponse{
-			Message: fmt.Sprintf("SourceUuid: %v", err),
-		}
+		outResp = vcrest.PatchDirectPlan400JSONResponse(fieldError("sourceUuid", err))
 		return
 	}
 	sourceUuid := internal.FieldMayUUID(request.Body.SourceUuid)
@@ -43,9 +40,7 @@ func (s *Server) PatchDirectPlan(ctx context.Context, request vcrest.PatchDirect
 		internal.FieldNotNull(request.Body.WorkUuid),
 		internal.FieldValidUUID(request.Body.WorkUuid),
 	); err != nil {
-		outResp = vcrest.PatchDirectPlan400JSONResponse{
-			Message: fmt.Sprintf("WorkUuid: %v", err),
-		}
+		outResp = vcrest.PatchDirectPlan400JSONResponse(fieldError("workUuid", err))
 		return
 	}
 	workUuid := internal.FieldMayUUID(request.Body.WorkUuid)
@@ -53,6 +48,7 @@ func (s *Server) PatchDirectPlan(ctx context.Context, request vcrest.PatchDirect
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.PatchDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
@@ -61,32 +57,45 @@ func (s *Server) PatchDirectPlan(ctx context.Context, request vcrest.PatchDirect
 
 	var kind internal.PlanKind
 	var rawBody json.RawMessage
+	var version int64
 	row := txn.QueryRow(ctx, `
-		SELECT kind, body
+		SELECT kind, body, version
 		FROM plans
-		WHERE uuid = $1
-	`, requestUuid)
-	err = row.Scan(&kind, &rawBody)
+		WHERE uuid = $1 AND deleted_at IS NULL AND `+internal.LibraryCondition("library_uuid", 2)+`
+		FOR UPDATE
+	`, requestUuid, internal.LibraryFromContext(ctx))
+	err = row.Scan(&kind, &rawBody, &version)
 	if errors.Is(err, pgx.ErrNoRows) {
 		outResp = vcrest.PatchDirectPlan404JSONResponse{
+			Code:    internal.CodeNotFound,
 			Message: "plan not found",
 		}
 		return
 	} else if err != nil {
 		outResp = vcrest.PatchDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query plan: %v", err),
 		}
 		return
 	} else if kind != internal.PlanKindDirect {
 		outResp = vcrest.PatchDirectPlan409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "plan is not a direct plan",
 		}
 		return
 	}
+	if err := (internal.Preconditions{IfMatch: request.Params.IfMatch}).Check(&version); err != nil {
+		outResp = vcrest.PatchDirectPlan412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "plan does not match the If-Match precondition",
+		}
+		return
+	}
 
 	var body internal.DirectPlan
 	if err := json.Unmarshal(rawBody, &body); err != nil {
 		outResp = vcrest.PatchDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to unmarshal plan body: %v", err),
 		}
 		return
@@ -99,29 +108,45 @@ func (s *Server) PatchDirectPlan(ctx context.Context, request vcrest.PatchDirect
 		body.WorkUUID = *workUuid
 	}
 
+	oldBody := rawBody
 	rawBody, err = json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PatchDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
 	}
 
-	_, err = txn.Exec(ctx, `
+	err = txn.QueryRow(ctx, `
 		UPDATE plans
 		SET body = $2
 		WHERE uuid = $1
-	`, requestUuid, rawBody)
+		RETURNING version
+	`, requestUuid, rawBody).Scan(&version)
 	if err != nil {
 		outResp = vcrest.PatchDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to update plan: %v", err),
 		}
 		return
 	}
 
+	if err := internal.RecordHistory(ctx, txn, "plans", requestUuid, kind, internal.HistoryUpdate, oldBody, rawBody); err != nil {
+		outResp = vcrest.PatchDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
 	if sourceUuid != nil {
-		if err := internal.UpdatePlanInputs(ctx, txn, requestUuid, *sourceUuid); err != nil {
+		if err := internal.UpdatePlanInputs(ctx, txn, requestUuid, *sourceUuid); errors.Is(err, internal.ErrMissingReference) {
+			outResp = vcrest.PatchDirectPlan409JSONResponse(missingReference(err))
+			return
+		} else if err != nil {
 			outResp = vcrest.PatchDirectPlan500JSONResponse{
+				Code:    internal.CodeInternal,
 				Message: err.Error(),
 			}
 			return
@@ -129,8 +154,12 @@ func (s *Server) PatchDirectPlan(ctx context.Context, request vcrest.PatchDirect
 	}
 
 	if workUuid != nil {
-		if err := internal.UpdatePlanOutputs(ctx, txn, requestUuid, *workUuid); err != nil {
+		if err := internal.UpdatePlanOutputs(ctx, txn, requestUuid, *workUuid); errors.Is(err, internal.ErrMissingReference) {
+			outResp = vcrest.PatchDirectPlan409JSONResponse(missingReference(err))
+			return
+		} else if err != nil {
 			outResp = vcrest.PatchDirectPlan500JSONResponse{
+				Code:    internal.CodeInternal,
 				Message: err.Error(),
 			}
 			return
@@ -139,11 +168,16 @@ func (s *Server) PatchDirectPlan(ctx context.Context, request vcrest.PatchDirect
 
 	if err := txn.Commit(ctx); err != nil {
 		outResp = vcrest.PatchDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to commit transaction: %v", err),
 		}
 		return
 	}
 
-	outResp = vcrest.PatchDirectPlan200Response{}
+	outResp = vcrest.PatchDirectPlan200Response{
+		Headers: vcrest.PatchDirectPlan200ResponseHeaders{
+			ETag: internal.ETag(version),
+		},
+	}
 	return
 }
@@ -16,43 +16,37 @@ func (s *Server) PatchDiscSource(ctx context.Context, request vcrest.PatchDiscSo
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PatchDiscSource400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PatchDiscSource400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PatchDiscSource400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
 	}
 	if err := internal.FieldNotEmpty(request.Body.OrigDirName); err != nil {
-		outResp = vcrest.PatchDiscSource400JSONResponse{
-			Message: fmt.Sprintf("OrigDirName: %v", err),
-		}
+		outResp = vcrest.PatchDiscSource400JSONResponse(fieldError("origDirName", err))
 		return
 	}
 	origDirName := internal.FieldMay(request.Body.OrigDirName)
 
 	if err := internal.FieldNotEmpty(request.Body.Path); err != nil {
-		outResp = vcrest.PatchDiscSource400JSONResponse{
-			Message: fmt.Sprintf("Path: %v", err),
-		}
+		outResp = vcrest.PatchDiscSource400JSONResponse(fieldError("path", err))
 		return
 	}
 	path := internal.FieldMay(request.Body.Path)
 
 	if err := internal.FieldNotNull(request.Body.AllFilesAdded); err != nil {
-		outResp = vcrest.PatchDiscSource400JSONResponse{
-			Message: fmt.Sprintf("AllFilesAdded: %v", err),
-		}
+		outResp = vcrest.PatchDiscSource400JSONResponse(fieldError("allFilesAdded", err))
 		return
 	}
 
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.PatchDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
@@ -61,31 +55,44 @@ func (s *Server) PatchDiscSource(ctx context.Context, request vcrest.PatchDiscSo
 
 	var kind internal.SourceKind
 	var rawBody json.RawMessage
+	var version int64
 	row := txn.QueryRow(ctx, `
-		SELECT kind, body
+		SELECT kind, body, version
 		FROM sources
-		WHERE uuid = $1
-	`, requestUuid)
-	err = row.Scan(&kind, &rawBody)
+		WHERE uuid = $1 AND deleted_at IS NULL AND `+internal.LibraryCondition("library_uuid", 2)+`
+		FOR UPDATE
+	`, requestUuid, internal.LibraryFromContext(ctx))
+	err = row.Scan(&kind, &rawBody, &version)
 	if errors.Is(err, pgx.ErrNoRows) {
 		outResp = vcrest.PatchDiscSource404JSONResponse{
+			Code:    internal.CodeNotFound,
 			Message: "source not found",
 		}
 		return
 	} else if err != nil {
 		outResp = vcrest.PatchDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query source: %v", err),
 		}
 		return
 	} else if kind != internal.SourceKindDisc {
 		outResp = vcrest.PatchDiscSource409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "source is not a disc",
 		}
 		return
 	}
+	if err := (internal.Preconditions{IfMatch: request.Params.IfMatch}).Check(&version); err != nil {
+		outResp = vcrest.PatchDiscSource412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "source does not match the If-Match precondition",
+		}
+		return
+	}
 	var body internal.DiscSource
 	if err := json.Unmarshal(rawBody, &body); err != nil {
 		outResp = vcrest.PatchDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to unmarshal source body: %v", err),
 		}
 		return
@@ -99,33 +106,50 @@ func (s *Server) PatchDiscSource(ctx context.Context, request vcrest.PatchDiscSo
 	}
 	internal.FieldSet(request.Body.AllFilesAdded, &body.AllFilesAdded)
 
+	oldBody := rawBody
 	rawBody, err = json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PatchDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
 	}
 
-	_, err = txn.Exec(ctx, `
+	err = txn.QueryRow(ctx, `
 		UPDATE sources
 		SET body = $2
 		WHERE uuid = $1
-	`, requestUuid, rawBody)
+		RETURNING version
+	`, requestUuid, rawBody).Scan(&version)
 	if err != nil {
 		outResp = vcrest.PatchDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to update source: %v", err),
 		}
 		return
 	}
 
+	if err := internal.RecordHistory(ctx, txn, "sources", requestUuid, kind, internal.HistoryUpdate, oldBody, rawBody); err != nil {
+		outResp = vcrest.PatchDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
 	if err := txn.Commit(ctx); err != nil {
 		outResp = vcrest.PatchDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to commit transaction: %v", err),
 		}
 		return
 	}
 
-	outResp = vcrest.PatchDiscSource200Response{}
+	outResp = vcrest.PatchDiscSource200Response{
+		Headers: vcrest.PatchDiscSource200ResponseHeaders{
+			ETag: internal.ETag(version),
+		},
+	}
 	return
 }
@@ -16,28 +16,30 @@ func (s *Server) PatchFileSource(ctx context.Context, request vcrest.PatchFileSo
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PatchFileSource400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PatchFileSource400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PatchFileSource400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
 	}
 	if err := internal.FieldNotEmpty(request.Body.Path); err != nil {
-		outResp = vcrest.PatchFileSource400JSONResponse{
-			Message: fmt.Sprintf("Path: %v", err),
-		}
+		outResp = vcrest.PatchFileSource400JSONResponse(fieldError("path", err))
 		return
 	}
 	path := internal.FieldMay(request.Body.Path)
+	if err := internal.FieldValidUUID(request.Body.DiscUuid); err != nil {
+		outResp = vcrest.PatchFileSource400JSONResponse(fieldError("discUuid", err))
+		return
+	}
 
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.PatchFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
@@ -46,31 +48,44 @@ func (s *Server) PatchFileSource(ctx context.Context, request vcrest.PatchFileSo
 
 	var kind internal.SourceKind
 	var rawBody json.RawMessage
+	var version int64
 	row := txn.QueryRow(ctx, `
-		SELECT kind, body
+		SELECT kind, body, version
 		FROM sources
-		WHERE uuid = $1
-	`, requestUuid)
-	err = row.Scan(&kind, &rawBody)
+		WHERE uuid = $1 AND deleted_at IS NULL AND `+internal.LibraryCondition("library_uuid", 2)+`
+		FOR UPDATE
+	`, requestUuid, internal.LibraryFromContext(ctx))
+	err = row.Scan(&kind, &rawBody, &version)
 	if errors.Is(err, pgx.ErrNoRows) {
 		outResp = vcrest.PatchFileSource404JSONResponse{
+			Code:    internal.CodeNotFound,
 			Message: "source not found",
 		}
 		return
 	} else if err != nil {
 		outResp = vcrest.PatchFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query source: %v", err),
 		}
 		return
 	} else if kind != internal.SourceKindFile {
 		outResp = vcrest.PatchFileSource409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "source is not a file",
 		}
 		return
 	}
+	if err := (internal.Preconditions{IfMatch: request.Params.IfMatch}).Check(&version); err != nil {
+		outResp = vcrest.PatchFileSource412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "source does not match the If-Match precondition",
+		}
+		return
+	}
 	var body internal.FileSource
 	if err := json.Unmarshal(rawBody, &body); err != nil {
 		outResp = vcrest.PatchFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to unmarshal source body: %v", err),
 		}
 		return
@@ -79,34 +94,65 @@ func (s *Server) PatchFileSource(ctx context.Context, request vcrest.PatchFileSo
 	if path != nil {
 		body.Path = *path
 	}
+	if parent := internal.FieldMayUUID(request.Body.DiscUuid); parent != nil {
+		err := internal.CheckParent(ctx, txn, "sources", requestUuid, *parent, internal.SourceKindDisc)
+		if errors.Is(err, internal.ErrMissingReference) {
+			outResp = vcrest.PatchFileSource409JSONResponse(missingReference(internal.NewFieldError("discUuid", err)))
+			return
+		} else if err != nil {
+			outResp = vcrest.PatchFileSource500JSONResponse{
+				Code:    internal.CodeInternal,
+				Message: err.Error(),
+			}
+			return
+		}
+	}
+	internal.FieldSetClear(request.Body.DiscUuid, &body.DiscUUID)
 
+	oldBody := rawBody
 	rawBody, err = json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PatchFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
 	}
 
-	_, err = txn.Exec(ctx, `
+	err = txn.QueryRow(ctx, `
 		UPDATE sources
 		SET body = $2
 		WHERE uuid = $1
-	`, requestUuid, rawBody)
+		RETURNING version
+	`, requestUuid, rawBody).Scan(&version)
 	if err != nil {
 		outResp = vcrest.PatchFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to update source: %v", err),
 		}
 		return
 	}
 
+	if err := internal.RecordHistory(ctx, txn, "sources", requestUuid, kind, internal.HistoryUpdate, oldBody, rawBody); err != nil {
+		outResp = vcrest.PatchFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
 	if err := txn.Commit(ctx); err != nil {
 		outResp = vcrest.PatchFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to commit transaction: %v", err),
 		}
 		return
 	}
 
-	outResp = vcrest.PatchFileSource200Response{}
+	outResp = vcrest.PatchFileSource200Response{
+		Headers: vcrest.PatchFileSource200ResponseHeaders{
+			ETag: internal.ETag(version),
+		},
+	}
 	return
 }
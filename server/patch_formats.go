@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+)
+
+const (
+	mediaTypeMergePatch = "application/merge-patch+json"
+	mediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+// patchRoute identifies a PATCH route by the table it changes and the last segment of its path,
+// which is empty for routes that change a whole resource.
+type patchRoute struct {
+	table string
+	kind  string
+}
+
+// patchRouteFields maps each PATCH route to the field of its GET representation that the route changes.
+var patchRouteFields = map[patchRoute]string{
+	{"works", "movie"}:         "movie",
+	{"works", "movie_edition"}: "movieEdition",
+	{"sources", "disc"}:        "disc",
+	{"sources", "file"}:        "file",
+	{"plans", "direct"}:        "direct",
+	{"plans", "chapter_range"}: "chapterRange",
+	{"persons", ""}:            "details",
+}
+
+// withPatchFormats serves PATCH requests with JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902) bodies.
+// The patch is applied to the current representation of the resource, which is then written with a PUT to
+// the same path so that it is validated exactly like a full replacement.  Other requests go to next.  The
+// request must already be authenticated and scoped to its library, since the GET and the PUT are made on
+// behalf of the caller in the library of the request.
+func (s *Server) withPatchFormats(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			next.ServeHTTP(w, r)
+			return
+		}
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || (mediaType != mediaTypeMergePatch && mediaType != mediaTypeJSONPatch) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		route := patchRoute{table: segments[0]}
+		if len(segments) == 3 {
+			route.kind = segments[2]
+		}
+		field, ok := patchRouteFields[route]
+		if !ok || len(segments) < 2 || len(segments) > 3 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		s.servePatchDocument(w, r, mediaType, route, segments[1], field)
+	})
+}
+
+// servePatchDocument applies a patch document to the field of the resource that a PATCH route changes.
+func (s *Server) servePatchDocument(w http.ResponseWriter, r *http.Request, mediaType string, route patchRoute, rawID, field string) {
+	ctx := r.Context()
+
+	// The caller's scopes are only checked by authorize once the PUT is served, so callers that cannot
+	// write are turned away here, before the row is locked.
+	if principal, ok := internal.PrincipalFromContext(ctx); !ok || !principal.Allows(internal.ScopeWrite) {
+		writeError(w, http.StatusForbidden, apiError(internal.CodeForbidden, fmt.Sprintf("caller does not have the %s scope", internal.ScopeWrite)))
+		return
+	}
+
+	// Validate request.
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	patchRaw, err := io.ReadAll(r.Body)
+	if body, ok := requestTooLarge(err); ok {
+		writeError(w, http.StatusRequestEntityTooLarge, body)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusBadRequest, apiError(internal.CodeInvalidRequest, fmt.Sprintf("failed to read request body: %v", err)))
+		return
+	}
+	var mergePatch any
+	var jsonPatch []internal.JSONPatchOp
+	if mediaType == mediaTypeMergePatch {
+		if err := json.Unmarshal(patchRaw, &mergePatch); err != nil {
+			writeError(w, http.StatusBadRequest, apiError(internal.CodeInvalidRequest, fmt.Sprintf("invalid JSON Merge Patch document: %v", err)))
+			return
+		}
+	} else {
+		jsonPatch, err = internal.ParseJSONPatch(patchRaw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, invalidRequest(err))
+			return
+		}
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, fmt.Sprintf("failed to begin transaction: %v", err)))
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Lock the row, so that it cannot change between reading the current representation and writing the new one.
+	// Persons are shared by all libraries; other entities are only locked in the library of the request.
+	entity := strings.TrimSuffix(route.table, "s")
+	query := fmt.Sprintf(`SELECT 1 FROM %s WHERE uuid = $1 FOR UPDATE`, route.table)
+	args := []any{id}
+	if route.table != "persons" {
+		query = fmt.Sprintf(`SELECT 1 FROM %s WHERE uuid = $1 AND %s FOR UPDATE`, route.table, internal.LibraryCondition("library_uuid", 2))
+		args = append(args, internal.LibraryFromContext(ctx))
+	}
+	var one int
+	err = txn.QueryRow(ctx, query, args...).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, apiError(internal.CodeNotFound, entity+" not found"))
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, fmt.Sprintf("failed to query %s: %v", entity, err)))
+		return
+	}
+
+	handler := (&Server{Config: s.Config, Pool: txn}).Handler()
+	resourcePath := fmt.Sprintf("/%s/%s", route.table, id)
+	got, err := serveInternal(ctx, handler, http.MethodGet, resourcePath, nil, nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, err.Error()))
+		return
+	} else if got.status != http.StatusOK {
+		got.copyTo(w)
+		return
+	}
+	var representation map[string]any
+	if err := json.Unmarshal(got.body.Bytes(), &representation); err != nil {
+		writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, fmt.Sprintf("failed to unmarshal %s: %v", entity, err)))
+		return
+	}
+	current, ok := representation[field]
+	if !ok && route.kind != "" {
+		writeError(w, http.StatusConflict, apiError(internal.CodeKindConflict, fmt.Sprintf("%s is not a %s", entity, strings.ReplaceAll(route.kind, "_", " "))))
+		return
+	} else if !ok {
+		current = map[string]any{}
+	}
+
+	var patched any
+	if mediaType == mediaTypeMergePatch {
+		patched = internal.MergePatch(current, mergePatch)
+	} else {
+		patched, err = internal.ApplyJSONPatch(current, jsonPatch)
+		if errors.Is(err, internal.ErrPatchConflict) {
+			writeError(w, http.StatusConflict, apiError(internal.CodePatchConflict, err.Error()))
+			return
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, err.Error()))
+			return
+		}
+	}
+	patchedRaw, err := json.Marshal(patched)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, fmt.Sprintf("failed to marshal %s: %v", entity, err)))
+		return
+	}
+
+	header := http.Header{}
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		header.Set("If-Match", ifMatch)
+	}
+	put, err := serveInternal(ctx, handler, http.MethodPut, r.URL.Path, header, patchedRaw)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, err.Error()))
+		return
+	} else if put.status >= http.StatusBadRequest {
+		put.copyTo(w)
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, fmt.Sprintf("failed to commit transaction: %v", err)))
+		return
+	}
+	put.copyTo(w)
+}
@@ -16,28 +16,30 @@ func (s *Server) PatchMovieEdition(ctx context.Context, request vcrest.PatchMovi
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PatchMovieEdition400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PatchMovieEdition400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PatchMovieEdition400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
 	}
 	if err := internal.FieldNotEmpty(request.Body.EditionType); err != nil {
-		outResp = vcrest.PatchMovieEdition400JSONResponse{
-			Message: fmt.Sprintf("EditionType: %v", err),
-		}
+		outResp = vcrest.PatchMovieEdition400JSONResponse(fieldError("editionType", err))
 		return
 	}
 	editionType := internal.FieldMay(request.Body.EditionType)
+	if err := internal.FieldValidUUID(request.Body.MovieUuid); err != nil {
+		outResp = vcrest.PatchMovieEdition400JSONResponse(fieldError("movieUuid", err))
+		return
+	}
 
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.PatchMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
@@ -46,31 +48,44 @@ func (s *Server) PatchMovieEdition(ctx context.Context, request vcrest.PatchMovi
 
 	var kind internal.WorkKind
 	var rawBody json.RawMessage
+	var version int64
 	row := txn.QueryRow(ctx, `
-		SELECT kind, body
+		SELECT kind, body, version
 		FROM works
-		WHERE uuid = $1
-	`, requestUuid)
-	err = row.Scan(&kind, &rawBody)
+		WHERE uuid = $1 AND deleted_at IS NULL AND `+internal.LibraryCondition("library_uuid", 2)+`
+		FOR UPDATE
+	`, requestUuid, internal.LibraryFromContext(ctx))
+	err = row.Scan(&kind, &rawBody, &version)
 	if errors.Is(err, pgx.ErrNoRows) {
 		outResp = vcrest.PatchMovieEdition404JSONResponse{
+			Code:    internal.CodeNotFound,
 			Message: "work not found",
 		}
 		return
 	} else if err != nil {
 		outResp = vcrest.PatchMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query work: %v", err),
 		}
 		return
 	} else if kind != internal.WorkKindMovieEdition {
 		outResp = vcrest.PatchMovieEdition409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "work is not a movie edition",
 		}
 		return
 	}
+	if err := (internal.Preconditions{IfMatch: request.Params.IfMatch}).Check(&version); err != nil {
+		outResp = vcrest.PatchMovieEdition412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "work does not match the If-Match precondition",
+		}
+		return
+	}
 	var body internal.MovieEditionWork
 	if err := json.Unmarshal(rawBody, &body); err != nil {
 		outResp = vcrest.PatchMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to unmarshal work body: %v", err),
 		}
 		return
@@ -79,34 +94,65 @@ func (s *Server) PatchMovieEdition(ctx context.Context, request vcrest.PatchMovi
 	if editionType != nil {
 		body.EditionType = *editionType
 	}
+	if parent := internal.FieldMayUUID(request.Body.MovieUuid); parent != nil {
+		err := internal.CheckParent(ctx, txn, "works", requestUuid, *parent, internal.WorkKindMovie)
+		if errors.Is(err, internal.ErrMissingReference) {
+			outResp = vcrest.PatchMovieEdition409JSONResponse(missingReference(internal.NewFieldError("movieUuid", err)))
+			return
+		} else if err != nil {
+			outResp = vcrest.PatchMovieEdition500JSONResponse{
+				Code:    internal.CodeInternal,
+				Message: err.Error(),
+			}
+			return
+		}
+	}
+	internal.FieldSetClear(request.Body.MovieUuid, &body.MovieUUID)
 
+	oldBody := rawBody
 	rawBody, err = json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PatchMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
 	}
 
-	_, err = txn.Exec(ctx, `
+	err = txn.QueryRow(ctx, `
 		UPDATE works
 		SET body = $2
 		WHERE uuid = $1
-	`, requestUuid, rawBody)
+		RETURNING version
+	`, requestUuid, rawBody).Scan(&version)
 	if err != nil {
 		outResp = vcrest.PatchMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to update work: %v", err),
 		}
 		return
 	}
 
+	if err := internal.RecordHistory(ctx, txn, "works", requestUuid, kind, internal.HistoryUpdate, oldBody, rawBody); err != nil {
+		outResp = vcrest.PatchMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
 	if err := txn.Commit(ctx); err != nil {
 		outResp = vcrest.PatchMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to commit transaction: %v", err),
 		}
 		return
 	}
 
-	outResp = vcrest.PatchMovieEdition200Response{}
+	outResp = vcrest.PatchMovieEdition200Response{
+		Headers: vcrest.PatchMovieEdition200ResponseHeaders{
+			ETag: internal.ETag(version),
+		},
+	}
 	return
 }
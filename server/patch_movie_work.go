@@ -16,28 +16,42 @@ func (s *Server) PatchMovieWork(ctx context.Context, request vcrest.PatchMovieWo
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PatchMovieWork400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PatchMovieWork400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PatchMovieWork400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
 	}
 	if err := internal.FieldNotEmpty(request.Body.Title); err != nil {
-		outResp = vcrest.PatchMovieWork400JSONResponse{
-			Message: fmt.Sprintf("Title: %v", err),
-		}
+		outResp = vcrest.PatchMovieWork400JSONResponse(fieldError("title", err))
 		return
 	}
 	title := internal.FieldMay(request.Body.Title)
+	if err := internal.FieldNotEmpty(request.Body.OriginalTitle); err != nil {
+		outResp = vcrest.PatchMovieWork400JSONResponse(fieldError("originalTitle", err))
+		return
+	}
+	if err := internal.FieldNotEmpty(request.Body.SortTitle); err != nil {
+		outResp = vcrest.PatchMovieWork400JSONResponse(fieldError("sortTitle", err))
+		return
+	}
+	var alternateTitles []internal.AlternateTitle
+	if alt := internal.FieldMay(request.Body.AlternateTitles); alt != nil {
+		alternateTitles, err = internal.AlternateTitlesFromAPI(*alt)
+		if err != nil {
+			outResp = vcrest.PatchMovieWork400JSONResponse(invalidRequest(err))
+			return
+		}
+	}
 
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.PatchMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
@@ -46,31 +60,44 @@ func (s *Server) PatchMovieWork(ctx context.Context, request vcrest.PatchMovieWo
 
 	var kind internal.WorkKind
 	var rawBody json.RawMessage
+	var version int64
 	row := txn.QueryRow(ctx, `
-		SELECT kind, body
+		SELECT kind, body, version
 		FROM works
-		WHERE uuid = $1
-	`, requestUuid)
-	err = row.Scan(&kind, &rawBody)
+		WHERE uuid = $1 AND deleted_at IS NULL AND `+internal.LibraryCondition("library_uuid", 2)+`
+		FOR UPDATE
+	`, requestUuid, internal.LibraryFromContext(ctx))
+	err = row.Scan(&kind, &rawBody, &version)
 	if errors.Is(err, pgx.ErrNoRows) {
 		outResp = vcrest.PatchMovieWork404JSONResponse{
+			Code:    internal.CodeNotFound,
 			Message: "work not found",
 		}
 		return
 	} else if err != nil {
 		outResp = vcrest.PatchMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to query work: %v", err),
 		}
 		return
 	} else if kind != internal.WorkKindMovie {
 		outResp = vcrest.PatchMovieWork409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "work is not a movie",
 		}
 		return
 	}
+	if err := (internal.Preconditions{IfMatch: request.Params.IfMatch}).Check(&version); err != nil {
+		outResp = vcrest.PatchMovieWork412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "work does not match the If-Match precondition",
+		}
+		return
+	}
 	var body internal.MovieWork
 	if err := json.Unmarshal(rawBody, &body); err != nil {
 		outResp = vcrest.PatchMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to unmarshal work body: %v", err),
 		}
 		return
@@ -79,36 +106,58 @@ func (s *Server) PatchMovieWork(ctx context.Context, request vcrest.PatchMovieWo
 	if title != nil {
 		body.Title = *title
 	}
+	internal.FieldSetClear(request.Body.OriginalTitle, &body.OriginalTitle)
+	internal.FieldSetClear(request.Body.SortTitle, &body.SortTitle)
+	if request.Body.AlternateTitles.IsSpecified() {
+		body.AlternateTitles = alternateTitles
+	}
 	internal.FieldSetClear(request.Body.ReleaseYear, &body.ReleaseYear)
 	internal.FieldSetClear(request.Body.TmdbId, &body.TmdbId)
 
+	oldBody := rawBody
 	rawBody, err = json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PatchMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
 	}
 
-	_, err = txn.Exec(ctx, `
+	err = txn.QueryRow(ctx, `
 		UPDATE works
 		SET body = $2
 		WHERE uuid = $1
-	`, requestUuid, rawBody)
+		RETURNING version
+	`, requestUuid, rawBody).Scan(&version)
 	if err != nil {
 		outResp = vcrest.PatchMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to update work: %v", err),
 		}
 		return
 	}
 
+	if err := internal.RecordHistory(ctx, txn, "works", requestUuid, kind, internal.HistoryUpdate, oldBody, rawBody); err != nil {
+		outResp = vcrest.PatchMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
 	if err := txn.Commit(ctx); err != nil {
 		outResp = vcrest.PatchMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to commit transaction: %v", err),
 		}
 		return
 	}
 
-	outResp = vcrest.PatchMovieWork200Response{}
+	outResp = vcrest.PatchMovieWork200Response{
+		Headers: vcrest.PatchMovieWork200ResponseHeaders{
+			ETag: internal.ETag(version),
+		},
+	}
 	return
 }
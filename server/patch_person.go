@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// PatchPerson updates fields of a person with the given UUID
+func (s *Server) PatchPerson(ctx context.Context, request vcrest.PatchPersonRequestObject) (outResp vcrest.PatchPersonResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.PatchPerson400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	if request.Body == nil {
+		outResp = vcrest.PatchPerson400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
+			Message: "request body is required",
+		}
+		return
+	}
+	if err := internal.FieldNotEmpty(request.Body.Name); err != nil {
+		outResp = vcrest.PatchPerson400JSONResponse(fieldError("name", err))
+		return
+	}
+	name := internal.FieldMay(request.Body.Name)
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PatchPerson500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	var rawBody json.RawMessage
+	row := txn.QueryRow(ctx, `
+		SELECT body
+		FROM persons
+		WHERE uuid = $1
+	`, requestUuid)
+	err = row.Scan(&rawBody)
+	if errors.Is(err, pgx.ErrNoRows) {
+		outResp = vcrest.PatchPerson404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "person not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.PatchPerson500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query person: %v", err),
+		}
+		return
+	}
+	var body internal.Person
+	if err := json.Unmarshal(rawBody, &body); err != nil {
+		outResp = vcrest.PatchPerson500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to unmarshal person body: %v", err),
+		}
+		return
+	}
+
+	if name != nil {
+		body.Name = *name
+	}
+	internal.FieldSetClear(request.Body.TmdbId, &body.TmdbId)
+
+	rawBody, err = json.Marshal(body)
+	if err != nil {
+		outResp = vcrest.PatchPerson500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to marshal database body: %v", err),
+		}
+		return
+	}
+
+	_, err = txn.Exec(ctx, `
+		UPDATE persons
+		SET body = $2
+		WHERE uuid = $1
+	`, requestUuid, rawBody)
+	if err != nil {
+		outResp = vcrest.PatchPerson500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to update person: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PatchPerson500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.PatchPerson200Response{}
+	return
+}
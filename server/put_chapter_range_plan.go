@@ -7,7 +7,6 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
 	"github.com/krelinga/video-catalog/internal"
 	"github.com/krelinga/video-catalog/vcrest"
 )
@@ -17,19 +16,16 @@ func (s *Server) PutChapterRangePlan(ctx context.Context, request vcrest.PutChap
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PutChapterRangePlan400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PutChapterRangePlan400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if requestUuid == uuid.Nil {
-		outResp = vcrest.PutChapterRangePlan400JSONResponse{
-			Message: "UUID cannot be zero",
-		}
+		outResp = vcrest.PutChapterRangePlan400JSONResponse(fieldError("uuid", internal.ErrEmpty))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PutChapterRangePlan400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
@@ -39,9 +35,7 @@ func (s *Server) PutChapterRangePlan(ctx context.Context, request vcrest.PutChap
 		internal.FieldNotNull(request.Body.SourceUuid),
 		internal.FieldValidUUID(request.Body.SourceUuid),
 	); err != nil {
-		outResp = vcrest.PutChapterRangePlan400JSONResponse{
-			Message: fmt.Sprintf("SourceUuid: %v", err),
-		}
+		outResp = vcrest.PutChapterRangePlan400JSONResponse(fieldError("sourceUuid", err))
 		return
 	}
 	sourceUuid := internal.FieldMustUUID(request.Body.SourceUuid)
@@ -51,9 +45,7 @@ func (s *Server) PutChapterRangePlan(ctx context.Context, request vcrest.PutChap
 		internal.FieldNotNull(request.Body.WorkUuid),
 		internal.FieldValidUUID(request.Body.WorkUuid),
 	); err != nil {
-		outResp = vcrest.PutChapterRangePlan400JSONResponse{
-			Message: fmt.Sprintf("WorkUuid: %v", err),
-		}
+		outResp = vcrest.PutChapterRangePlan400JSONResponse(fieldError("workUuid", err))
 		return
 	}
 	workUuid := internal.FieldMustUUID(request.Body.WorkUuid)
@@ -68,6 +60,7 @@ func (s *Server) PutChapterRangePlan(ctx context.Context, request vcrest.PutChap
 	bodyRaw, err := json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PutChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
@@ -76,44 +69,62 @@ func (s *Server) PutChapterRangePlan(ctx context.Context, request vcrest.PutChap
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.PutChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
 	}
 	defer txn.Rollback(ctx)
 
-	row := txn.QueryRow(ctx, `
-		INSERT INTO plans (uuid, kind, body)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (uuid) DO UPDATE
-		SET body = EXCLUDED.body
-		WHERE plans.kind = $2
-		RETURNING xmax`,
-		requestUuid, internal.PlanKindChapterRange, bodyRaw)
-	var xmax uint32
-	if err := row.Scan(&xmax); errors.Is(err, pgx.ErrNoRows) {
+	result, version, err := internal.UpsertEntity(ctx, txn, "plans", requestUuid, internal.PlanKindChapterRange, bodyRaw, internal.Preconditions{
+		IfMatch:     request.Params.IfMatch,
+		IfNoneMatch: request.Params.IfNoneMatch,
+	})
+	if errors.Is(err, internal.ErrUpsertType) {
 		outResp = vcrest.PutChapterRangePlan409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "plan with given UUID already exists with different kind",
 		}
 		return
+	} else if errors.Is(err, internal.ErrLibraryConflict) {
+		outResp = vcrest.PutChapterRangePlan409JSONResponse{
+			Code:    internal.CodeLibraryConflict,
+			Message: "plan with given UUID already exists in a different library",
+		}
+		return
+	} else if errors.Is(err, internal.ErrPreconditionFailed) {
+		outResp = vcrest.PutChapterRangePlan412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "plan does not match the If-Match or If-None-Match precondition",
+		}
+		return
 	} else if err != nil {
 		outResp = vcrest.PutChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to insert/update plan: %v", err),
 		}
 		return
 	}
 
 	// Update plan_inputs
-	if err := internal.UpdatePlanInputs(ctx, txn, requestUuid, sourceUuid); err != nil {
+	if err := internal.UpdatePlanInputs(ctx, txn, requestUuid, sourceUuid); errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.PutChapterRangePlan409JSONResponse(missingReference(err))
+		return
+	} else if err != nil {
 		outResp = vcrest.PutChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to update plan_inputs: %v", err),
 		}
 		return
 	}
 
 	// Update plan_outputs
-	if err := internal.UpdatePlanOutputs(ctx, txn, requestUuid, workUuid); err != nil {
+	if err := internal.UpdatePlanOutputs(ctx, txn, requestUuid, workUuid); errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.PutChapterRangePlan409JSONResponse(missingReference(err))
+		return
+	} else if err != nil {
 		outResp = vcrest.PutChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to update plan_outputs: %v", err),
 		}
 		return
@@ -121,15 +132,24 @@ func (s *Server) PutChapterRangePlan(ctx context.Context, request vcrest.PutChap
 
 	if err := txn.Commit(ctx); err != nil {
 		outResp = vcrest.PutChapterRangePlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to commit transaction: %v", err),
 		}
 		return
 	}
 
-	if xmax == 0 {
-		outResp = vcrest.PutChapterRangePlan201Response{}
+	if result == internal.UpsertCreated {
+		outResp = vcrest.PutChapterRangePlan201Response{
+			Headers: vcrest.PutChapterRangePlan201ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	} else {
-		outResp = vcrest.PutChapterRangePlan200Response{}
+		outResp = vcrest.PutChapterRangePlan200Response{
+			Headers: vcrest.PutChapterRangePlan200ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	}
 	return
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// PutCollection adds or updates a collection with the given UUID
+func (s *Server) PutCollection(ctx context.Context, request vcrest.PutCollectionRequestObject) (outResp vcrest.PutCollectionResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.PutCollection400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	if request.Body == nil {
+		outResp = vcrest.PutCollection400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
+			Message: "request body is required",
+		}
+		return
+	}
+	if err := errors.Join(
+		internal.FieldRequired(request.Body.Name),
+		internal.FieldNotNull(request.Body.Name),
+		internal.FieldNotEmpty(request.Body.Name),
+	); err != nil {
+		outResp = vcrest.PutCollection400JSONResponse(fieldError("name", err))
+		return
+	}
+	if err := internal.FieldNotEmpty(request.Body.Description); err != nil {
+		outResp = vcrest.PutCollection400JSONResponse(fieldError("description", err))
+		return
+	}
+	body := internal.Collection{
+		Name: request.Body.Name.MustGet(),
+	}
+	internal.FieldSetPtr(request.Body.Description, &body.Description)
+
+	bodyRaw, err := json.Marshal(body)
+	if err != nil {
+		outResp = vcrest.PutCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to marshal database body: %v", err),
+		}
+		return
+	}
+
+	var xmax uint32
+	err = s.Pool.QueryRow(ctx, `
+		INSERT INTO collections (uuid, body)
+		VALUES ($1, $2)
+		ON CONFLICT (uuid) DO UPDATE
+		SET body = EXCLUDED.body
+		RETURNING xmax
+	`, requestUuid, bodyRaw).Scan(&xmax)
+	if err != nil {
+		outResp = vcrest.PutCollection500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to insert/update collection: %v", err),
+		}
+		return
+	}
+
+	if xmax == 0 {
+		outResp = vcrest.PutCollection201Response{}
+	} else {
+		outResp = vcrest.PutCollection200Response{}
+	}
+	return
+}
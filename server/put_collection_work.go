@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// PutCollectionWork adds a work to a collection, or moves it within the collection
+func (s *Server) PutCollectionWork(ctx context.Context, request vcrest.PutCollectionWorkRequestObject) (outResp vcrest.PutCollectionWorkResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.PutCollectionWork400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	workUuid, err := internal.AsUUID(request.WorkUuid)
+	if err != nil {
+		outResp = vcrest.PutCollectionWork400JSONResponse(fieldError("workUuid", internal.ErrInvalidUUID))
+		return
+	}
+	if request.Params.Position != nil && *request.Params.Position < 0 {
+		outResp = vcrest.PutCollectionWork400JSONResponse(fieldError("position", internal.ErrNegative))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PutCollectionWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	added, err := internal.PutCollectionWork(ctx, txn, requestUuid, workUuid, request.Params.Position)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.PutCollectionWork404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: err.Error(),
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.PutCollectionWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to add work to collection: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PutCollectionWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	if added {
+		outResp = vcrest.PutCollectionWork201Response{}
+	} else {
+		outResp = vcrest.PutCollectionWork200Response{}
+	}
+	return
+}
@@ -16,19 +16,16 @@ func (s *Server) PutDirectPlan(ctx context.Context, request vcrest.PutDirectPlan
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PutDirectPlan400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PutDirectPlan400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if requestUuid == uuid.Nil {
-		outResp = vcrest.PutDirectPlan400JSONResponse{
-			Message: "UUID cannot be zero",
-		}
+		outResp = vcrest.PutDirectPlan400JSONResponse(fieldError("uuid", internal.ErrEmpty))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PutDirectPlan400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
@@ -38,9 +35,7 @@ func (s *Server) PutDirectPlan(ctx context.Context, request vcrest.PutDirectPlan
 		internal.FieldNotNull(request.Body.SourceUuid),
 		internal.FieldValidUUID(request.Body.SourceUuid),
 	); err != nil {
-		outResp = vcrest.PutDirectPlan400JSONResponse{
-			Message: fmt.Sprintf("SourceUuid: %v", err),
-		}
+		outResp = vcrest.PutDirectPlan400JSONResponse(fieldError("sourceUuid", err))
 		return
 	}
 	sourceUuid := internal.FieldMustUUID(request.Body.SourceUuid)
@@ -50,9 +45,7 @@ func (s *Server) PutDirectPlan(ctx context.Context, request vcrest.PutDirectPlan
 		internal.FieldNotNull(request.Body.WorkUuid),
 		internal.FieldValidUUID(request.Body.WorkUuid),
 	); err != nil {
-		outResp = vcrest.PutDirectPlan400JSONResponse{
-			Message: fmt.Sprintf("WorkUuid: %v", err),
-		}
+		outResp = vcrest.PutDirectPlan400JSONResponse(fieldError("workUuid", err))
 		return
 	}
 
@@ -66,6 +59,7 @@ func (s *Server) PutDirectPlan(ctx context.Context, request vcrest.PutDirectPlan
 	bodyRaw, err := json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PutDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
@@ -74,34 +68,60 @@ func (s *Server) PutDirectPlan(ctx context.Context, request vcrest.PutDirectPlan
 	txn, err := s.Pool.Begin(ctx)
 	if err != nil {
 		outResp = vcrest.PutDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to begin transaction: %v", err),
 		}
 		return
 	}
 	defer txn.Rollback(ctx)
 
-	result, err := internal.UpsertEntity(ctx, txn, "plans", requestUuid, internal.PlanKindDirect, bodyRaw)
+	result, version, err := internal.UpsertEntity(ctx, txn, "plans", requestUuid, internal.PlanKindDirect, bodyRaw, internal.Preconditions{
+		IfMatch:     request.Params.IfMatch,
+		IfNoneMatch: request.Params.IfNoneMatch,
+	})
 	if errors.Is(err, internal.ErrUpsertType) {
 		outResp = vcrest.PutDirectPlan409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "plan with given UUID already exists with different kind",
 		}
 		return
+	} else if errors.Is(err, internal.ErrLibraryConflict) {
+		outResp = vcrest.PutDirectPlan409JSONResponse{
+			Code:    internal.CodeLibraryConflict,
+			Message: "plan with given UUID already exists in a different library",
+		}
+		return
+	} else if errors.Is(err, internal.ErrPreconditionFailed) {
+		outResp = vcrest.PutDirectPlan412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "plan does not match the If-Match or If-None-Match precondition",
+		}
+		return
 	} else if err != nil {
 		outResp = vcrest.PutDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to insert/update plan: %v", err),
 		}
 		return
 	}
 
-	if err := internal.UpdatePlanInputs(ctx, txn, requestUuid, sourceUuid); err != nil {
+	if err := internal.UpdatePlanInputs(ctx, txn, requestUuid, sourceUuid); errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.PutDirectPlan409JSONResponse(missingReference(err))
+		return
+	} else if err != nil {
 		outResp = vcrest.PutDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: err.Error(),
 		}
 		return
 	}
 
-	if err := internal.UpdatePlanOutputs(ctx, txn, requestUuid, workUuid); err != nil {
+	if err := internal.UpdatePlanOutputs(ctx, txn, requestUuid, workUuid); errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.PutDirectPlan409JSONResponse(missingReference(err))
+		return
+	} else if err != nil {
 		outResp = vcrest.PutDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: err.Error(),
 		}
 		return
@@ -109,15 +129,24 @@ func (s *Server) PutDirectPlan(ctx context.Context, request vcrest.PutDirectPlan
 
 	if err := txn.Commit(ctx); err != nil {
 		outResp = vcrest.PutDirectPlan500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to commit transaction: %v", err),
 		}
 		return
 	}
 
 	if result == internal.UpsertCreated {
-		outResp = vcrest.PutDirectPlan201Response{}
+		outResp = vcrest.PutDirectPlan201Response{
+			Headers: vcrest.PutDirectPlan201ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	} else {
-		outResp = vcrest.PutDirectPlan200Response{}
+		outResp = vcrest.PutDirectPlan200Response{
+			Headers: vcrest.PutDirectPlan200ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	}
 	return
 }
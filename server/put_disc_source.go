@@ -15,13 +15,12 @@ func (s *Server) PutDiscSource(ctx context.Context, request vcrest.PutDiscSource
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PutDiscSource400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PutDiscSource400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PutDiscSource400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
@@ -31,9 +30,7 @@ func (s *Server) PutDiscSource(ctx context.Context, request vcrest.PutDiscSource
 		internal.FieldNotNull(request.Body.OrigDirName),
 		internal.FieldNotEmpty(request.Body.OrigDirName),
 	); err != nil {
-		outResp = vcrest.PutDiscSource400JSONResponse{
-			Message: fmt.Sprintf("OrigDirName: %v", err),
-		}
+		outResp = vcrest.PutDiscSource400JSONResponse(fieldError("origDirName", err))
 		return
 	}
 
@@ -42,16 +39,12 @@ func (s *Server) PutDiscSource(ctx context.Context, request vcrest.PutDiscSource
 		internal.FieldNotNull(request.Body.Path),
 		internal.FieldNotEmpty(request.Body.Path),
 	); err != nil {
-		outResp = vcrest.PutDiscSource400JSONResponse{
-			Message: fmt.Sprintf("Path: %v", err),
-		}
+		outResp = vcrest.PutDiscSource400JSONResponse(fieldError("path", err))
 		return
 	}
 
 	if err := internal.FieldNotNull(request.Body.AllFilesAdded); err != nil {
-		outResp = vcrest.PutDiscSource400JSONResponse{
-			Message: fmt.Sprintf("AllFilesAdded: %v", err),
-		}
+		outResp = vcrest.PutDiscSource400JSONResponse(fieldError("allFilesAdded", err))
 		return
 	}
 
@@ -64,28 +57,73 @@ func (s *Server) PutDiscSource(ctx context.Context, request vcrest.PutDiscSource
 	bodyRaw, err := json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PutDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
 	}
 
-	result, err := internal.UpsertEntity(ctx, s.Pool, "sources", requestUuid, internal.SourceKindDisc, bodyRaw)
+	// The preconditions are checked and the row written under the same lock.
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PutDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	result, version, err := internal.UpsertEntity(ctx, txn, "sources", requestUuid, internal.SourceKindDisc, bodyRaw, internal.Preconditions{
+		IfMatch:     request.Params.IfMatch,
+		IfNoneMatch: request.Params.IfNoneMatch,
+	})
 	if errors.Is(err, internal.ErrUpsertType) {
 		outResp = vcrest.PutDiscSource409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "source with given UUID already exists with different kind",
 		}
 		return
+	} else if errors.Is(err, internal.ErrLibraryConflict) {
+		outResp = vcrest.PutDiscSource409JSONResponse{
+			Code:    internal.CodeLibraryConflict,
+			Message: "source with given UUID already exists in a different library",
+		}
+		return
+	} else if errors.Is(err, internal.ErrPreconditionFailed) {
+		outResp = vcrest.PutDiscSource412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "source does not match the If-Match or If-None-Match precondition",
+		}
+		return
 	} else if err != nil {
 		outResp = vcrest.PutDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to insert/update source: %v", err),
 		}
 		return
 	}
 
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PutDiscSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
 	if result == internal.UpsertCreated {
-		outResp = vcrest.PutDiscSource201Response{}
+		outResp = vcrest.PutDiscSource201Response{
+			Headers: vcrest.PutDiscSource201ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	} else {
-		outResp = vcrest.PutDiscSource200Response{}
+		outResp = vcrest.PutDiscSource200Response{
+			Headers: vcrest.PutDiscSource200ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	}
 	return
 }
@@ -15,13 +15,12 @@ func (s *Server) PutFileSource(ctx context.Context, request vcrest.PutFileSource
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PutFileSource400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PutFileSource400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PutFileSource400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
@@ -31,41 +30,106 @@ func (s *Server) PutFileSource(ctx context.Context, request vcrest.PutFileSource
 		internal.FieldNotNull(request.Body.Path),
 		internal.FieldNotEmpty(request.Body.Path),
 	); err != nil {
-		outResp = vcrest.PutFileSource400JSONResponse{
-			Message: fmt.Sprintf("Path: %v", err),
-		}
+		outResp = vcrest.PutFileSource400JSONResponse(fieldError("path", err))
+		return
+	}
+	if err := internal.FieldValidUUID(request.Body.DiscUuid); err != nil {
+		outResp = vcrest.PutFileSource400JSONResponse(fieldError("discUuid", err))
 		return
 	}
 
 	body := internal.FileSource{
-		Path: request.Body.Path.MustGet(),
+		Path:     request.Body.Path.MustGet(),
+		DiscUUID: internal.FieldMayUUID(request.Body.DiscUuid),
 	}
 
 	bodyRaw, err := json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PutFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
 	}
 
-	result, err := internal.UpsertEntity(ctx, s.Pool, "sources", requestUuid, internal.SourceKindFile, bodyRaw)
+	// The preconditions are checked and the row written under the same lock.
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PutFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	if body.DiscUUID != nil {
+		err := internal.CheckParent(ctx, txn, "sources", requestUuid, *body.DiscUUID, internal.SourceKindDisc)
+		if errors.Is(err, internal.ErrMissingReference) {
+			outResp = vcrest.PutFileSource409JSONResponse(missingReference(internal.NewFieldError("discUuid", err)))
+			return
+		} else if err != nil {
+			outResp = vcrest.PutFileSource500JSONResponse{
+				Code:    internal.CodeInternal,
+				Message: err.Error(),
+			}
+			return
+		}
+	}
+
+	result, version, err := internal.UpsertEntity(ctx, txn, "sources", requestUuid, internal.SourceKindFile, bodyRaw, internal.Preconditions{
+		IfMatch:     request.Params.IfMatch,
+		IfNoneMatch: request.Params.IfNoneMatch,
+	})
 	if errors.Is(err, internal.ErrUpsertType) {
 		outResp = vcrest.PutFileSource409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "source with given UUID already exists with different kind",
 		}
 		return
+	} else if errors.Is(err, internal.ErrLibraryConflict) {
+		outResp = vcrest.PutFileSource409JSONResponse{
+			Code:    internal.CodeLibraryConflict,
+			Message: "source with given UUID already exists in a different library",
+		}
+		return
+	} else if errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.PutFileSource409JSONResponse(missingReference(internal.NewFieldError("discUuid", err)))
+		return
+	} else if errors.Is(err, internal.ErrPreconditionFailed) {
+		outResp = vcrest.PutFileSource412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "source does not match the If-Match or If-None-Match precondition",
+		}
+		return
 	} else if err != nil {
 		outResp = vcrest.PutFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to insert/update source: %v", err),
 		}
 		return
 	}
 
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PutFileSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
 	if result == internal.UpsertCreated {
-		outResp = vcrest.PutFileSource201Response{}
+		outResp = vcrest.PutFileSource201Response{
+			Headers: vcrest.PutFileSource201ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	} else {
-		outResp = vcrest.PutFileSource200Response{}
+		outResp = vcrest.PutFileSource200Response{
+			Headers: vcrest.PutFileSource200ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	}
 	return
 }
@@ -15,13 +15,12 @@ func (s *Server) PutMovieEdition(ctx context.Context, request vcrest.PutMovieEdi
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PutMovieEdition400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PutMovieEdition400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PutMovieEdition400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
@@ -31,41 +30,106 @@ func (s *Server) PutMovieEdition(ctx context.Context, request vcrest.PutMovieEdi
 		internal.FieldNotNull(request.Body.EditionType),
 		internal.FieldNotEmpty(request.Body.EditionType),
 	); err != nil {
-		outResp = vcrest.PutMovieEdition400JSONResponse{
-			Message: fmt.Sprintf("EditionType: %v", err),
-		}
+		outResp = vcrest.PutMovieEdition400JSONResponse(fieldError("editionType", err))
+		return
+	}
+	if err := internal.FieldValidUUID(request.Body.MovieUuid); err != nil {
+		outResp = vcrest.PutMovieEdition400JSONResponse(fieldError("movieUuid", err))
 		return
 	}
 
 	body := internal.MovieEditionWork{
 		EditionType: request.Body.EditionType.MustGet(),
+		MovieUUID:   internal.FieldMayUUID(request.Body.MovieUuid),
 	}
 
 	bodyRaw, err := json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PutMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
 	}
 
-	result, err := internal.UpsertEntity(ctx, s.Pool, "works", requestUuid, internal.WorkKindMovieEdition, bodyRaw)
+	// The preconditions are checked and the row written under the same lock.
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PutMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	if body.MovieUUID != nil {
+		err := internal.CheckParent(ctx, txn, "works", requestUuid, *body.MovieUUID, internal.WorkKindMovie)
+		if errors.Is(err, internal.ErrMissingReference) {
+			outResp = vcrest.PutMovieEdition409JSONResponse(missingReference(internal.NewFieldError("movieUuid", err)))
+			return
+		} else if err != nil {
+			outResp = vcrest.PutMovieEdition500JSONResponse{
+				Code:    internal.CodeInternal,
+				Message: err.Error(),
+			}
+			return
+		}
+	}
+
+	result, version, err := internal.UpsertEntity(ctx, txn, "works", requestUuid, internal.WorkKindMovieEdition, bodyRaw, internal.Preconditions{
+		IfMatch:     request.Params.IfMatch,
+		IfNoneMatch: request.Params.IfNoneMatch,
+	})
 	if errors.Is(err, internal.ErrUpsertType) {
 		outResp = vcrest.PutMovieEdition409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "work with given UUID already exists with different kind",
 		}
 		return
+	} else if errors.Is(err, internal.ErrLibraryConflict) {
+		outResp = vcrest.PutMovieEdition409JSONResponse{
+			Code:    internal.CodeLibraryConflict,
+			Message: "work with given UUID already exists in a different library",
+		}
+		return
+	} else if errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.PutMovieEdition409JSONResponse(missingReference(internal.NewFieldError("movieUuid", err)))
+		return
+	} else if errors.Is(err, internal.ErrPreconditionFailed) {
+		outResp = vcrest.PutMovieEdition412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "work does not match the If-Match or If-None-Match precondition",
+		}
+		return
 	} else if err != nil {
 		outResp = vcrest.PutMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to insert/update work: %v", err),
 		}
 		return
 	}
 
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PutMovieEdition500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
 	if result == internal.UpsertCreated {
-		outResp = vcrest.PutMovieEdition201Response{}
+		outResp = vcrest.PutMovieEdition201Response{
+			Headers: vcrest.PutMovieEdition201ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	} else {
-		outResp = vcrest.PutMovieEdition200Response{}
+		outResp = vcrest.PutMovieEdition200Response{
+			Headers: vcrest.PutMovieEdition200ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	}
 	return
 }
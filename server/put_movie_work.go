@@ -15,13 +15,12 @@ func (s *Server) PutMovieWork(ctx context.Context, request vcrest.PutMovieWorkRe
 	// Validate request.
 	requestUuid, err := internal.AsUUID(request.Uuid)
 	if err != nil {
-		outResp = vcrest.PutMovieWork400JSONResponse{
-			Message: "invalid UUID format",
-		}
+		outResp = vcrest.PutMovieWork400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
 		return
 	}
 	if request.Body == nil {
 		outResp = vcrest.PutMovieWork400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
 			Message: "request body is required",
 		}
 		return
@@ -31,42 +30,104 @@ func (s *Server) PutMovieWork(ctx context.Context, request vcrest.PutMovieWorkRe
 		internal.FieldNotNull(request.Body.Title),
 		internal.FieldNotEmpty(request.Body.Title),
 	); err != nil {
-		outResp = vcrest.PutMovieWork400JSONResponse{
-			Message: fmt.Sprintf("Title: %v", err),
-		}
+		outResp = vcrest.PutMovieWork400JSONResponse(fieldError("title", err))
+		return
+	}
+	if err := internal.FieldNotEmpty(request.Body.OriginalTitle); err != nil {
+		outResp = vcrest.PutMovieWork400JSONResponse(fieldError("originalTitle", err))
+		return
+	}
+	if err := internal.FieldNotEmpty(request.Body.SortTitle); err != nil {
+		outResp = vcrest.PutMovieWork400JSONResponse(fieldError("sortTitle", err))
 		return
 	}
+	var alternateTitles []internal.AlternateTitle
+	if alt := internal.FieldMay(request.Body.AlternateTitles); alt != nil {
+		alternateTitles, err = internal.AlternateTitlesFromAPI(*alt)
+		if err != nil {
+			outResp = vcrest.PutMovieWork400JSONResponse(invalidRequest(err))
+			return
+		}
+	}
 	body := internal.MovieWork{
-		Title: request.Body.Title.MustGet(),
+		Title:           request.Body.Title.MustGet(),
+		AlternateTitles: alternateTitles,
 	}
+	internal.FieldSetPtr(request.Body.OriginalTitle, &body.OriginalTitle)
+	internal.FieldSetPtr(request.Body.SortTitle, &body.SortTitle)
 	internal.FieldSetPtr(request.Body.ReleaseYear, &body.ReleaseYear)
 	internal.FieldSetPtr(request.Body.TmdbId, &body.TmdbId)
 
 	bodyRaw, err := json.Marshal(body)
 	if err != nil {
 		outResp = vcrest.PutMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to marshal database body: %v", err),
 		}
 		return
 	}
 
-	result, err := internal.UpsertEntity(ctx, s.Pool, "works", requestUuid, internal.WorkKindMovie, bodyRaw)
+	// The preconditions are checked and the row written under the same lock.
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PutMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	result, version, err := internal.UpsertEntity(ctx, txn, "works", requestUuid, internal.WorkKindMovie, bodyRaw, internal.Preconditions{
+		IfMatch:     request.Params.IfMatch,
+		IfNoneMatch: request.Params.IfNoneMatch,
+	})
 	if errors.Is(err, internal.ErrUpsertType) {
 		outResp = vcrest.PutMovieWork409JSONResponse{
+			Code:    internal.CodeKindConflict,
 			Message: "work with given UUID already exists with different kind",
 		}
 		return
+	} else if errors.Is(err, internal.ErrLibraryConflict) {
+		outResp = vcrest.PutMovieWork409JSONResponse{
+			Code:    internal.CodeLibraryConflict,
+			Message: "work with given UUID already exists in a different library",
+		}
+		return
+	} else if errors.Is(err, internal.ErrPreconditionFailed) {
+		outResp = vcrest.PutMovieWork412JSONResponse{
+			Code:    internal.CodePreconditionFailed,
+			Message: "work does not match the If-Match or If-None-Match precondition",
+		}
+		return
 	} else if err != nil {
 		outResp = vcrest.PutMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
 			Message: fmt.Sprintf("failed to insert/update work: %v", err),
 		}
 		return
 	}
 
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PutMovieWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
 	if result == internal.UpsertCreated {
-		outResp = vcrest.PutMovieWork201Response{}
+		outResp = vcrest.PutMovieWork201Response{
+			Headers: vcrest.PutMovieWork201ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	} else {
-		outResp = vcrest.PutMovieWork200Response{}
+		outResp = vcrest.PutMovieWork200Response{
+			Headers: vcrest.PutMovieWork200ResponseHeaders{
+				ETag: internal.ETag(version),
+			},
+		}
 	}
 	return
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// PutPerson adds or updates a person with the given UUID
+func (s *Server) PutPerson(ctx context.Context, request vcrest.PutPersonRequestObject) (outResp vcrest.PutPersonResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.PutPerson400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	if request.Body == nil {
+		outResp = vcrest.PutPerson400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
+			Message: "request body is required",
+		}
+		return
+	}
+	if err := errors.Join(
+		internal.FieldRequired(request.Body.Name),
+		internal.FieldNotNull(request.Body.Name),
+		internal.FieldNotEmpty(request.Body.Name),
+	); err != nil {
+		outResp = vcrest.PutPerson400JSONResponse(fieldError("name", err))
+		return
+	}
+	body := internal.Person{
+		Name: request.Body.Name.MustGet(),
+	}
+	internal.FieldSetPtr(request.Body.TmdbId, &body.TmdbId)
+
+	bodyRaw, err := json.Marshal(body)
+	if err != nil {
+		outResp = vcrest.PutPerson500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to marshal database body: %v", err),
+		}
+		return
+	}
+
+	var xmax uint32
+	err = s.Pool.QueryRow(ctx, `
+		INSERT INTO persons (uuid, body)
+		VALUES ($1, $2)
+		ON CONFLICT (uuid) DO UPDATE
+		SET body = EXCLUDED.body
+		RETURNING xmax
+	`, requestUuid, bodyRaw).Scan(&xmax)
+	if err != nil {
+		outResp = vcrest.PutPerson500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to insert/update person: %v", err),
+		}
+		return
+	}
+
+	if xmax == 0 {
+		outResp = vcrest.PutPerson201Response{}
+	} else {
+		outResp = vcrest.PutPerson200Response{}
+	}
+	return
+}
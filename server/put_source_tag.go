@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// PutSourceTag adds a tag to the source with the given UUID
+func (s *Server) PutSourceTag(ctx context.Context, request vcrest.PutSourceTagRequestObject) (outResp vcrest.PutSourceTagResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.PutSourceTag400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	if err := internal.ValidLabel(request.Tag); err != nil {
+		outResp = vcrest.PutSourceTag400JSONResponse(fieldError("tag", err))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PutSourceTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	added, err := internal.SourceTags.Add(ctx, txn, requestUuid, request.Tag)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.PutSourceTag404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "source not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.PutSourceTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to add tag: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PutSourceTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	if added {
+		outResp = vcrest.PutSourceTag201Response{}
+	} else {
+		outResp = vcrest.PutSourceTag200Response{}
+	}
+	return
+}
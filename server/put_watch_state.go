@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// PutWatchState replaces the caller's watch state of the work with the given UUID.
+func (s *Server) PutWatchState(ctx context.Context, request vcrest.PutWatchStateRequestObject) (outResp vcrest.PutWatchStateResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.PutWatchState400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	if request.Body == nil {
+		outResp = vcrest.PutWatchState400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
+			Message: "request body is required",
+		}
+		return
+	}
+	if err := internal.ValidateWatchState(request.Body); err != nil {
+		outResp = vcrest.PutWatchState400JSONResponse(invalidRequest(err))
+		return
+	}
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		outResp = vcrest.PutWatchState500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PutWatchState500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	state, err := internal.PutWatchState(ctx, txn, userID, requestUuid, request.Body)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.PutWatchState404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.PutWatchState500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PutWatchState500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.PutWatchState200JSONResponse(*state)
+	return
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// PutWorkCredits replaces the credits of the work with the given UUID
+func (s *Server) PutWorkCredits(ctx context.Context, request vcrest.PutWorkCreditsRequestObject) (outResp vcrest.PutWorkCreditsResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.PutWorkCredits400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	if request.Body == nil {
+		outResp = vcrest.PutWorkCredits400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
+			Message: "request body is required",
+		}
+		return
+	}
+	credits, err := internal.CreditsFromAPI(requestUuid, request.Body.Credits)
+	if err != nil {
+		outResp = vcrest.PutWorkCredits400JSONResponse(invalidRequest(err))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PutWorkCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	var exists bool
+	err = txn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM works WHERE uuid = $1 AND deleted_at IS NULL AND `+internal.LibraryCondition("library_uuid", 2)+`)`, requestUuid, internal.LibraryFromContext(ctx)).Scan(&exists)
+	if err != nil {
+		outResp = vcrest.PutWorkCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query work: %v", err),
+		}
+		return
+	} else if !exists {
+		outResp = vcrest.PutWorkCredits404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	}
+
+	err = internal.ReplaceWorkCredits(ctx, txn, requestUuid, credits)
+	if errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.PutWorkCredits400JSONResponse(missingReference(err))
+		return
+	} else if err != nil {
+		outResp = vcrest.PutWorkCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to replace credits: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PutWorkCredits500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.PutWorkCredits200Response{}
+	return
+}
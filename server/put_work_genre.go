@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// PutWorkGenre adds a genre to the work with the given UUID
+func (s *Server) PutWorkGenre(ctx context.Context, request vcrest.PutWorkGenreRequestObject) (outResp vcrest.PutWorkGenreResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.PutWorkGenre400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	if err := internal.ValidLabel(request.Genre); err != nil {
+		outResp = vcrest.PutWorkGenre400JSONResponse(fieldError("genre", err))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PutWorkGenre500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	added, err := internal.WorkGenres.Add(ctx, txn, requestUuid, request.Genre)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.PutWorkGenre404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	} else if errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.PutWorkGenre400JSONResponse(fieldError("genre", internal.ErrInvalid))
+		return
+	} else if err != nil {
+		outResp = vcrest.PutWorkGenre500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to add genre: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PutWorkGenre500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	if added {
+		outResp = vcrest.PutWorkGenre201Response{}
+	} else {
+		outResp = vcrest.PutWorkGenre200Response{}
+	}
+	return
+}
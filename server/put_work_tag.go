@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// PutWorkTag adds a tag to the work with the given UUID
+func (s *Server) PutWorkTag(ctx context.Context, request vcrest.PutWorkTagRequestObject) (outResp vcrest.PutWorkTagResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.PutWorkTag400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	if err := internal.ValidLabel(request.Tag); err != nil {
+		outResp = vcrest.PutWorkTag400JSONResponse(fieldError("tag", err))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.PutWorkTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	added, err := internal.WorkTags.Add(ctx, txn, requestUuid, request.Tag)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.PutWorkTag404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.PutWorkTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to add tag: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.PutWorkTag500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	if added {
+		outResp = vcrest.PutWorkTag201Response{}
+	} else {
+		outResp = vcrest.PutWorkTag200Response{}
+	}
+	return
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// RecordPlay records that the caller watched the work with the given UUID.
+func (s *Server) RecordPlay(ctx context.Context, request vcrest.RecordPlayRequestObject) (outResp vcrest.RecordPlayResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.RecordPlay400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+	if request.Body == nil {
+		outResp = vcrest.RecordPlay400JSONResponse{
+			Code:    internal.CodeInvalidRequest,
+			Message: "request body is required",
+		}
+		return
+	}
+	watchedAt := time.Now()
+	if request.Body.WatchedAt != nil {
+		watchedAt = *request.Body.WatchedAt
+	}
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		outResp = vcrest.RecordPlay500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.RecordPlay500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	state, err := internal.RecordPlay(ctx, txn, userID, requestUuid, watchedAt)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.RecordPlay404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.RecordPlay500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.RecordPlay500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.RecordPlay200JSONResponse(*state)
+	return
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// responseRecorder captures the response to a request that the server makes to itself.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// copyTo replays the recorded response to w.
+func (w *responseRecorder) copyTo(out http.ResponseWriter) {
+	for key, values := range w.header {
+		out.Header()[key] = values
+	}
+	out.WriteHeader(w.status)
+	out.Write(w.body.Bytes())
+}
+
+// serveInternal passes a request made by the server itself to handler and returns the recorded response.
+// A body is sent as JSON if it is not nil.
+func serveInternal(ctx context.Context, handler http.Handler, method, path string, header http.Header, body []byte) (*responseRecorder, error) {
+	var bodyReader io.Reader = http.NoBody
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, path, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	w := &responseRecorder{header: http.Header{}}
+	handler.ServeHTTP(w, req)
+	w.WriteHeader(http.StatusOK)
+	return w, nil
+}
+
+// writeError writes an error response in the same form as the generated handlers.
+func writeError(w http.ResponseWriter, status int, body vcrest.Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
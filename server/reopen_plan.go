@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ReopenPlan clears the completion time of the plan with the given UUID
+func (s *Server) ReopenPlan(ctx context.Context, request vcrest.ReopenPlanRequestObject) (outResp vcrest.ReopenPlanResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.ReopenPlan400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ReopenPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.SetPlanCompleted(ctx, txn, requestUuid, false)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.ReopenPlan404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "plan not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.ReopenPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ReopenPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.ReopenPlan200Response{}
+	return
+}
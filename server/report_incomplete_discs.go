@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ReportIncompleteDiscs lists the discs that do not have all of their files added.
+func (s *Server) ReportIncompleteDiscs(ctx context.Context, request vcrest.ReportIncompleteDiscsRequestObject) (outResp vcrest.ReportIncompleteDiscsResponseObject, _ error) {
+	// Validate request.
+	pageSize, lastUUID, err := reportPageParams(request.Params.PageSize, request.Params.PageToken)
+	if err != nil {
+		outResp = vcrest.ReportIncompleteDiscs400JSONResponse{
+			Code:    internal.CodeInvalidPageToken,
+			Message: fmt.Sprintf("invalid page token: %v", err),
+		}
+		return
+	}
+	var olderThanDays int32
+	if request.Params.OlderThanDays != nil {
+		olderThanDays = *request.Params.OlderThanDays
+	}
+	if olderThanDays < 0 {
+		outResp = vcrest.ReportIncompleteDiscs400JSONResponse(fieldError("olderThanDays", internal.ErrNegative))
+		return
+	}
+	createdBefore := time.Now().AddDate(0, 0, -int(olderThanDays))
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ReportIncompleteDiscs500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Fetch one extra to determine if there's a next page.
+	sources, err := internal.IncompleteDiscs(ctx, txn, createdBefore, lastUUID, pageSize+1)
+	if err != nil {
+		outResp = vcrest.ReportIncompleteDiscs500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ReportIncompleteDiscs500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	response := vcrest.ReportIncompleteDiscs200JSONResponse{
+		Sources: make([]vcrest.Source, 0, min(len(sources), pageSize)),
+	}
+	for _, source := range sources[:min(len(sources), pageSize)] {
+		response.Sources = append(response.Sources, *source)
+	}
+	if len(sources) > pageSize {
+		token := encodeReportPageToken(uuid.UUID(response.Sources[pageSize-1].Uuid))
+		response.NextPageToken = &token
+	}
+
+	outResp = response
+	return
+}
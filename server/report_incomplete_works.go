@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ReportIncompleteWorks lists the works that are output by at least one plan that is not complete.
+func (s *Server) ReportIncompleteWorks(ctx context.Context, request vcrest.ReportIncompleteWorksRequestObject) (outResp vcrest.ReportIncompleteWorksResponseObject, _ error) {
+	// Validate request.
+	pageSize, lastUUID, err := reportPageParams(request.Params.PageSize, request.Params.PageToken)
+	if err != nil {
+		outResp = vcrest.ReportIncompleteWorks400JSONResponse{
+			Code:    internal.CodeInvalidPageToken,
+			Message: fmt.Sprintf("invalid page token: %v", err),
+		}
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ReportIncompleteWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Fetch one extra to determine if there's a next page.
+	works, err := internal.IncompleteWorks(ctx, txn, lastUUID, pageSize+1)
+	if err != nil {
+		outResp = vcrest.ReportIncompleteWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ReportIncompleteWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	response := vcrest.ReportIncompleteWorks200JSONResponse{
+		Works: make([]vcrest.Work, 0, min(len(works), pageSize)),
+	}
+	for _, work := range works[:min(len(works), pageSize)] {
+		response.Works = append(response.Works, *work)
+	}
+	if len(works) > pageSize {
+		token := encodeReportPageToken(uuid.UUID(response.Works[pageSize-1].Uuid))
+		response.NextPageToken = &token
+	}
+
+	outResp = response
+	return
+}
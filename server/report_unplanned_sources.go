@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ReportUnplannedSources lists the sources that no plan consumes.
+func (s *Server) ReportUnplannedSources(ctx context.Context, request vcrest.ReportUnplannedSourcesRequestObject) (outResp vcrest.ReportUnplannedSourcesResponseObject, _ error) {
+	// Validate request.
+	pageSize, lastUUID, err := reportPageParams(request.Params.PageSize, request.Params.PageToken)
+	if err != nil {
+		outResp = vcrest.ReportUnplannedSources400JSONResponse{
+			Code:    internal.CodeInvalidPageToken,
+			Message: fmt.Sprintf("invalid page token: %v", err),
+		}
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ReportUnplannedSources500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Fetch one extra to determine if there's a next page.
+	sources, err := internal.UnplannedSources(ctx, txn, lastUUID, pageSize+1)
+	if err != nil {
+		outResp = vcrest.ReportUnplannedSources500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ReportUnplannedSources500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	response := vcrest.ReportUnplannedSources200JSONResponse{
+		Sources: make([]vcrest.Source, 0, min(len(sources), pageSize)),
+	}
+	for _, source := range sources[:min(len(sources), pageSize)] {
+		response.Sources = append(response.Sources, *source)
+	}
+	if len(sources) > pageSize {
+		token := encodeReportPageToken(uuid.UUID(response.Sources[pageSize-1].Uuid))
+		response.NextPageToken = &token
+	}
+
+	outResp = response
+	return
+}
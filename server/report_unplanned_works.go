@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// ReportUnplannedWorks lists the works that no plan outputs.
+func (s *Server) ReportUnplannedWorks(ctx context.Context, request vcrest.ReportUnplannedWorksRequestObject) (outResp vcrest.ReportUnplannedWorksResponseObject, _ error) {
+	// Validate request.
+	pageSize, lastUUID, err := reportPageParams(request.Params.PageSize, request.Params.PageToken)
+	if err != nil {
+		outResp = vcrest.ReportUnplannedWorks400JSONResponse{
+			Code:    internal.CodeInvalidPageToken,
+			Message: fmt.Sprintf("invalid page token: %v", err),
+		}
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.ReportUnplannedWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Fetch one extra to determine if there's a next page.
+	works, err := internal.UnplannedWorks(ctx, txn, lastUUID, pageSize+1)
+	if err != nil {
+		outResp = vcrest.ReportUnplannedWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.ReportUnplannedWorks500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	response := vcrest.ReportUnplannedWorks200JSONResponse{
+		Works: make([]vcrest.Work, 0, min(len(works), pageSize)),
+	}
+	for _, work := range works[:min(len(works), pageSize)] {
+		response.Works = append(response.Works, *work)
+	}
+	if len(works) > pageSize {
+		token := encodeReportPageToken(uuid.UUID(response.Works[pageSize-1].Uuid))
+		response.NextPageToken = &token
+	}
+
+	outResp = response
+	return
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const reportPageTokenMagic = uint32(0x52455054) // "REPT" in ASCII
+
+// reportPageParams returns the page size and the UUID to continue after for a report request.
+func reportPageParams(pageSize *int32, pageToken *string) (int, uuid.UUID, error) {
+	size := defaultPageSize
+	if pageSize != nil {
+		size = min(max(int(*pageSize), minPageSize), maxPageSize)
+	}
+	if pageToken == nil || *pageToken == "" {
+		return size, uuid.Nil, nil
+	}
+
+	buf, err := base64.URLEncoding.DecodeString(*pageToken)
+	if err != nil {
+		return 0, uuid.Nil, fmt.Errorf("invalid page token encoding: %w", err)
+	}
+	if len(buf) != 20 {
+		return 0, uuid.Nil, fmt.Errorf("invalid page token length: expected 20, got %d", len(buf))
+	}
+	magic := binary.BigEndian.Uint32(buf[0:4])
+	if magic != reportPageTokenMagic {
+		return 0, uuid.Nil, fmt.Errorf("invalid page token magic: expected 0x%08x, got 0x%08x", reportPageTokenMagic, magic)
+	}
+	var lastUUID uuid.UUID
+	copy(lastUUID[:], buf[4:])
+	return size, lastUUID, nil
+}
+
+// encodeReportPageToken returns the token for the report page that follows lastUUID.
+func encodeReportPageToken(lastUUID uuid.UUID) string {
+	buf := make([]byte, 4+16) // 4 bytes for magic + 16 bytes for UUID
+	binary.BigEndian.PutUint32(buf[0:4], reportPageTokenMagic)
+	copy(buf[4:], lastUUID[:])
+	return base64.URLEncoding.EncodeToString(buf)
+}
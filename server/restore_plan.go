@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// RestorePlan takes the plan with the given UUID back out of the trash
+func (s *Server) RestorePlan(ctx context.Context, request vcrest.RestorePlanRequestObject) (outResp vcrest.RestorePlanResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.RestorePlan400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.RestorePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.RestoreEntity(ctx, txn, "plans", requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.RestorePlan404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "plan not found",
+		}
+		return
+	} else if errors.Is(err, internal.ErrNotDeleted) {
+		outResp = vcrest.RestorePlan409JSONResponse{
+			Code:    internal.CodeNotDeleted,
+			Message: "plan is not deleted",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.RestorePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.RestorePlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.RestorePlan200Response{}
+	return
+}
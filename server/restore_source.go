@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// RestoreSource takes the source with the given UUID back out of the trash
+func (s *Server) RestoreSource(ctx context.Context, request vcrest.RestoreSourceRequestObject) (outResp vcrest.RestoreSourceResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.RestoreSource400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.RestoreSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.RestoreEntity(ctx, txn, "sources", requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.RestoreSource404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "source not found",
+		}
+		return
+	} else if errors.Is(err, internal.ErrNotDeleted) {
+		outResp = vcrest.RestoreSource409JSONResponse{
+			Code:    internal.CodeNotDeleted,
+			Message: "source is not deleted",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.RestoreSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.RestoreSource500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.RestoreSource200Response{}
+	return
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// RestoreWork takes the work with the given UUID back out of the trash
+func (s *Server) RestoreWork(ctx context.Context, request vcrest.RestoreWorkRequestObject) (outResp vcrest.RestoreWorkResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.RestoreWork400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.RestoreWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	err = internal.RestoreEntity(ctx, txn, "works", requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.RestoreWork404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "work not found",
+		}
+		return
+	} else if errors.Is(err, internal.ErrNotDeleted) {
+		outResp = vcrest.RestoreWork409JSONResponse{
+			Code:    internal.CodeNotDeleted,
+			Message: "work is not deleted",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.RestoreWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.RestoreWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.RestoreWork200Response{}
+	return
+}
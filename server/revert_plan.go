@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// RevertPlan restores the plan with the given UUID to the version recorded by a history entry
+func (s *Server) RevertPlan(ctx context.Context, request vcrest.RevertPlanRequestObject) (outResp vcrest.RevertPlanResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.RevertPlan400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.RevertPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	_, body, err := internal.RevertEntity(ctx, txn, "plans", requestUuid, request.HistoryId)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.RevertPlan404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: err.Error(),
+		}
+		return
+	} else if errors.Is(err, internal.ErrUpsertType) {
+		outResp = vcrest.RevertPlan409JSONResponse{
+			Code:    internal.CodeKindConflict,
+			Message: "history entry is for a different kind of plan",
+		}
+		return
+	} else if errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.RevertPlan409JSONResponse{
+			Code:    internal.CodeReferenceMissing,
+			Message: fmt.Sprintf("cannot revert plan: %v", err),
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.RevertPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	// The plan's inputs and outputs are derived from its body, so they have to follow it back.
+	err = internal.SyncPlanLinks(ctx, txn, requestUuid, body)
+	if errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.RevertPlan409JSONResponse{
+			Code:    internal.CodeReferenceMissing,
+			Message: fmt.Sprintf("cannot revert plan: %v", err),
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.RevertPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.RevertPlan500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.RevertPlan200Response{}
+	return
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// RevertWork restores the work with the given UUID to the version recorded by a history entry
+func (s *Server) RevertWork(ctx context.Context, request vcrest.RevertWorkRequestObject) (outResp vcrest.RevertWorkResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.RevertWork400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.RevertWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	_, _, err = internal.RevertEntity(ctx, txn, "works", requestUuid, request.HistoryId)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.RevertWork404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: err.Error(),
+		}
+		return
+	} else if errors.Is(err, internal.ErrUpsertType) {
+		outResp = vcrest.RevertWork409JSONResponse{
+			Code:    internal.CodeKindConflict,
+			Message: "history entry is for a different kind of work",
+		}
+		return
+	} else if errors.Is(err, internal.ErrMissingReference) {
+		outResp = vcrest.RevertWork409JSONResponse{
+			Code:    internal.CodeReferenceMissing,
+			Message: fmt.Sprintf("cannot revert work: %v", err),
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.RevertWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.RevertWork500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	outResp = vcrest.RevertWork200Response{}
+	return
+}
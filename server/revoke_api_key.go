@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// RevokeApiKey revokes an API key, so that it can no longer be used
+func (s *Server) RevokeApiKey(ctx context.Context, request vcrest.RevokeApiKeyRequestObject) (outResp vcrest.RevokeApiKeyResponseObject, _ error) {
+	// Validate request.
+	requestUuid, err := internal.AsUUID(request.Uuid)
+	if err != nil {
+		outResp = vcrest.RevokeApiKey400JSONResponse(fieldError("uuid", internal.ErrInvalidUUID))
+		return
+	}
+
+	apiKey, err := internal.RevokeAPIKey(ctx, s.Pool, requestUuid)
+	if errors.Is(err, internal.ErrNotFound) {
+		outResp = vcrest.RevokeApiKey404JSONResponse{
+			Code:    internal.CodeNotFound,
+			Message: "API key not found",
+		}
+		return
+	} else if err != nil {
+		outResp = vcrest.RevokeApiKey500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: err.Error(),
+		}
+		return
+	}
+
+	outResp = vcrest.RevokeApiKey200JSONResponse(*apiKey)
+	return
+}
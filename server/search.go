@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	searchPageTokenMagic = uint32(0x53524348) // "SRCH" in ASCII
+)
+
+// Search results are ordered by relevance rather than by a stable key, so search page tokens record
+// the offset of the next result instead of the last UUID seen.
+func encodeSearchPageToken(offset int) string {
+	buf := make([]byte, 4+4) // 4 bytes for magic + 4 bytes for offset
+	binary.BigEndian.PutUint32(buf[0:4], searchPageTokenMagic)
+	binary.BigEndian.PutUint32(buf[4:], uint32(offset))
+	return base64.URLEncoding.EncodeToString(buf)
+}
+
+func decodeSearchPageToken(tokenStr string) (int, error) {
+	buf, err := base64.URLEncoding.DecodeString(tokenStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid page token encoding: %w", err)
+	}
+	if len(buf) != 8 {
+		return 0, fmt.Errorf("invalid page token length: expected 8, got %d", len(buf))
+	}
+	magic := binary.BigEndian.Uint32(buf[0:4])
+	if magic != searchPageTokenMagic {
+		return 0, fmt.Errorf("invalid page token magic: expected 0x%08x, got 0x%08x", searchPageTokenMagic, magic)
+	}
+	return int(binary.BigEndian.Uint32(buf[4:])), nil
+}
+
+// Search performs a full-text search across works and sources.
+func (s *Server) Search(ctx context.Context, request vcrest.SearchRequestObject) (outResp vcrest.SearchResponseObject, _ error) {
+	// Validate request.
+	q := strings.TrimSpace(request.Params.Q)
+	if q == "" {
+		outResp = vcrest.Search400JSONResponse(fieldError("q", internal.ErrEmpty))
+		return
+	}
+
+	// Determine page size with reasonable bounds
+	pageSize := defaultPageSize
+	if request.Params.PageSize != nil {
+		ps := *request.Params.PageSize
+		if ps < minPageSize {
+			pageSize = minPageSize
+		} else if ps > maxPageSize {
+			pageSize = maxPageSize
+		} else {
+			pageSize = int(ps)
+		}
+	}
+
+	// Decode page token if provided
+	offset := 0
+	if request.Params.PageToken != nil && *request.Params.PageToken != "" {
+		var err error
+		offset, err = decodeSearchPageToken(*request.Params.PageToken)
+		if err != nil {
+			outResp = vcrest.Search400JSONResponse{
+				Code:    internal.CodeInvalidPageToken,
+				Message: fmt.Sprintf("invalid page token: %v", err),
+			}
+			return
+		}
+	}
+
+	txn, err := s.Pool.Begin(ctx)
+	if err != nil {
+		outResp = vcrest.Search500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to begin transaction: %v", err),
+		}
+		return
+	}
+	defer txn.Rollback(ctx)
+
+	// Full-text matches catch whole words, while trigram word similarity catches partial words and
+	// paths, which the text search parser treats as single tokens.  The expressions here must match
+	// the ones used by the search indexes.  Equally relevant results are ordered by sort title.
+	rows, err := txn.Query(ctx, `
+		WITH query AS (
+			SELECT websearch_to_tsquery('simple', $1) AS tsq
+		), hits AS (
+			SELECT 'work' AS entity, w.uuid, w.kind, w.body, work_search_text(w.body) AS text, work_sort_title(w.body) AS sort_title
+			FROM works w, query
+			WHERE w.deleted_at IS NULL AND `+internal.LibraryCondition("w.library_uuid", 4)+`
+				AND (to_tsvector('simple', work_search_text(w.body)) @@ query.tsq
+					OR $1 <% work_search_text(w.body))
+			UNION ALL
+			SELECT 'source' AS entity, s.uuid, s.kind, s.body, source_search_text(s.body) AS text, lower(source_search_text(s.body)) AS sort_title
+			FROM sources s, query
+			WHERE s.deleted_at IS NULL AND `+internal.LibraryCondition("s.library_uuid", 4)+`
+				AND (to_tsvector('simple', source_search_text(s.body)) @@ query.tsq
+					OR $1 <% source_search_text(s.body))
+		)
+		SELECT
+			hits.entity,
+			hits.uuid,
+			hits.kind,
+			hits.body,
+			(ts_rank(to_tsvector('simple', hits.text), query.tsq) + word_similarity($1, hits.text))::real AS score,
+			ts_headline('simple', hits.text, query.tsq) AS highlight
+		FROM hits, query
+		ORDER BY score DESC, hits.sort_title, hits.uuid
+		LIMIT $2 OFFSET $3
+	`, q, pageSize+1, offset, internal.LibraryFromContext(ctx)) // Fetch one extra to determine if there's a next page
+	if err != nil {
+		outResp = vcrest.Search500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query search results: %v", err),
+		}
+		return
+	}
+
+	type searchRow struct {
+		entity    string
+		uuid      uuid.UUID
+		kind      string
+		bodyRaw   json.RawMessage
+		score     float32
+		highlight string
+	}
+
+	results := []vcrest.SearchResult{}
+	hasMore := false
+	var row searchRow
+	_, err = pgx.ForEachRow(rows, []any{&row.entity, &row.uuid, &row.kind, &row.bodyRaw, &row.score, &row.highlight}, func() error {
+		if len(results) >= pageSize {
+			hasMore = true
+			return nil
+		}
+
+		result := vcrest.SearchResult{
+			Uuid:      openapi_types.UUID(row.uuid),
+			Score:     row.score,
+			Highlight: row.highlight,
+		}
+
+		switch row.entity {
+		case "work":
+			work, err := internal.WorkToAPI(row.uuid, internal.WorkKind(row.kind), row.bodyRaw)
+			if err != nil {
+				return err
+			}
+			result.Work = work
+		case "source":
+			source, err := internal.SourceToAPI(row.uuid, internal.SourceKind(row.kind), row.bodyRaw)
+			if err != nil {
+				return err
+			}
+			result.Source = source
+		default:
+			return fmt.Errorf("unexpected search entity: %s", row.entity)
+		}
+
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		outResp = vcrest.Search500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to query and scan search results: %v", err),
+		}
+		return
+	}
+
+	if err := txn.Commit(ctx); err != nil {
+		outResp = vcrest.Search500JSONResponse{
+			Code:    internal.CodeInternal,
+			Message: fmt.Sprintf("failed to commit transaction: %v", err),
+		}
+		return
+	}
+
+	// Build response
+	response := vcrest.Search200JSONResponse{
+		Results: results,
+	}
+
+	// Add next page token if there are more results
+	if hasMore {
+		token := encodeSearchPageToken(offset + len(results))
+		response.NextPageToken = &token
+	}
+
+	outResp = response
+	return
+}
@@ -1,11 +1,44 @@
 package main
 
 import (
-	"github.com/jackc/pgx/v5/pgxpool"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+	"github.com/riverqueue/river"
 )
 
 type Server struct {
 	Config *internal.Config
-	Pool   *pgxpool.Pool
+	Pool   internal.DB
+
+	// Notifier wakes up event streams when the catalog changes.  Without it they only poll.
+	Notifier *internal.ChangeNotifier
+
+	// Tokens verifies bearer tokens.  Without it only API keys are accepted.
+	Tokens *internal.TokenVerifier
+
+	// Jobs runs the background jobs.  The server is not ready without it.
+	Jobs *river.Client[pgx.Tx]
+
+	// Stopping is closed when the server starts shutting down, which ends the event streams so that they
+	// do not hold up the shutdown.  Clients reconnect to another server with Last-Event-ID.
+	Stopping <-chan struct{}
+}
+
+// Handler returns the HTTP handler that routes API requests to the server.
+func (s *Server) Handler() http.Handler {
+	middlewares := []vcrest.StrictMiddlewareFunc{s.authorize}
+	strict := vcrest.NewStrictHandlerWithOptions(s, middlewares, vcrest.StrictHTTPServerOptions{
+		RequestErrorHandlerFunc:  writeRequestError,
+		ResponseErrorHandlerFunc: writeResponseError,
+	})
+	api := vcrest.HandlerWithOptions(strict, vcrest.StdHTTPServerOptions{
+		ErrorHandlerFunc: writeRequestError,
+	})
+	// Authentication comes first, so that nothing about the catalog or the API is revealed to callers
+	// without credentials, not even whether their request is valid.  Their scopes are checked by the
+	// strict middleware, which knows the operation without routing the request again.
+	return s.withAuthentication(s.withLibrary(s.withPatchFormats(s.withValidation(api))))
 }
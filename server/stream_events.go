@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+const (
+	// eventStreamPollInterval is how often an event stream reads the feed without being notified.
+	// Notifications can arrive before the changes they announce are visible in the feed, which only shows
+	// changes once every older transaction has finished.
+	eventStreamPollInterval = 5 * time.Second
+
+	// eventStreamKeepAliveInterval is how often an idle event stream sends a comment, so that proxies do
+	// not close the connection.
+	eventStreamKeepAliveInterval = 15 * time.Second
+
+	// eventStreamBatchSize is how many changes an event stream reads from the feed at once.
+	eventStreamBatchSize = 500
+)
+
+// StreamEvents streams the writes to works, sources and plans as Server-Sent Events
+func (s *Server) StreamEvents(ctx context.Context, request vcrest.StreamEventsRequestObject) (outResp vcrest.StreamEventsResponseObject, _ error) {
+	// Validate request.
+	stream := &eventStream{
+		server: s,
+		ctx:    ctx,
+		typ:    request.Params.Type,
+		kind:   request.Params.Kind,
+	}
+	if stream.typ != nil && *stream.typ != "work" && *stream.typ != "source" && *stream.typ != "plan" {
+		outResp = vcrest.StreamEvents400JSONResponse(fieldError("type", fmt.Errorf("%w, expected work, source or plan", internal.ErrInvalid)))
+		return
+	}
+	if request.Params.LastEventID != nil && *request.Params.LastEventID != "" {
+		var err error
+		stream.cursor, err = decodeChangeCursor(*request.Params.LastEventID)
+		if err != nil {
+			outResp = vcrest.StreamEvents400JSONResponse{
+				Code:    internal.CodeInvalidPageToken,
+				Message: fmt.Sprintf("invalid Last-Event-ID: %v", err),
+			}
+			return
+		}
+	} else {
+		var err error
+		stream.cursor, err = internal.LatestChangeCursor(ctx, s.Pool)
+		if err != nil {
+			outResp = vcrest.StreamEvents500JSONResponse{
+				Code:    internal.CodeInternal,
+				Message: err.Error(),
+			}
+			return
+		}
+	}
+
+	outResp = stream
+	return
+}
+
+// eventStream writes the changes after a cursor to a response as they happen, until the request ends.
+type eventStream struct {
+	server *Server
+	ctx    context.Context
+	typ    *string
+	kind   *string
+	cursor internal.ChangeCursor
+}
+
+func (e *eventStream) matches(change *vcrest.Change) bool {
+	if e.typ != nil && change.Type != *e.typ {
+		return false
+	}
+	if e.kind != nil && (change.Kind == nil || *change.Kind != *e.kind) {
+		return false
+	}
+	return true
+}
+
+func (e *eventStream) VisitStreamEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	// The stream stays open for as long as the client wants, so the read and write timeouts of the server
+	// do not apply.  Writers that cannot clear the deadlines, such as the recorder of a batch, have none.
+	if err := rc.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if err := rc.Flush(); err != nil {
+		return err
+	}
+
+	var notified <-chan struct{}
+	if e.server.Notifier != nil {
+		ch, unsubscribe := e.server.Notifier.Subscribe()
+		defer unsubscribe()
+		notified = ch
+	}
+	poll := time.NewTicker(eventStreamPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(eventStreamKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		sent, err := e.send(w)
+		if e.ctx.Err() != nil {
+			return nil
+		} else if err != nil {
+			// The status has already been sent, so the stream just ends and the client reconnects.
+			log.Printf("Event stream ended: %v", err)
+			return nil
+		}
+		if sent {
+			if err := rc.Flush(); err != nil {
+				return nil
+			}
+			keepAlive.Reset(eventStreamKeepAliveInterval)
+		}
+
+		select {
+		case <-e.ctx.Done():
+			return nil
+		case <-e.server.Stopping:
+			return nil
+		case <-notified:
+		case <-poll.C:
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			if err := rc.Flush(); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// send writes the changes after the cursor that match the filters, reporting whether it wrote any.
+func (e *eventStream) send(w http.ResponseWriter) (bool, error) {
+	sent := false
+	for {
+		entries, err := e.load()
+		if err != nil {
+			return sent, err
+		}
+		for _, entry := range entries {
+			e.cursor = entry.Cursor
+			if !e.matches(&entry.Change) {
+				continue
+			}
+			data, err := json.Marshal(entry.Change)
+			if err != nil {
+				return sent, fmt.Errorf("failed to marshal change: %w", err)
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: change\ndata: %s\n\n", encodeChangeCursor(entry.Cursor), data); err != nil {
+				return sent, err
+			}
+			sent = true
+		}
+		if len(entries) < eventStreamBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+func (e *eventStream) load() ([]internal.ChangeEntry, error) {
+	txn, err := e.server.Pool.Begin(e.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer txn.Rollback(e.ctx)
+	return internal.LoadChanges(e.ctx, txn, e.cursor, eventStreamBatchSize)
+}
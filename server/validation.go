@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/google/uuid"
+	"github.com/krelinga/video-catalog/internal"
+	"github.com/krelinga/video-catalog/vcrest"
+)
+
+// apiRouter matches requests to the operations of the API specification.  It is built once because
+// the server builds a handler for each batch and patch request.
+var apiRouter = sync.OnceValues(newAPIRouter)
+
+func newAPIRouter() (routers.Router, error) {
+	openapi3.DefineStringFormatValidator("uuid", openapi3.NewCallbackValidator(func(value string) error {
+		_, err := uuid.Parse(value)
+		return err
+	}))
+
+	doc, err := vcrest.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API specification: %w", err)
+	}
+	// Match requests regardless of the host they were sent to.
+	doc.Servers = openapi3.Servers{{URL: "/"}}
+	disallowUnknownFields(doc)
+	return gorillamux.NewRouter(doc)
+}
+
+// disallowUnknownFields makes every object schema with declared properties reject any other property.
+// The specification leaves additionalProperties at its default, which would let misspelled fields be
+// silently ignored.
+func disallowUnknownFields(doc *openapi3.T) {
+	seen := map[*openapi3.Schema]bool{}
+	var visit func(ref *openapi3.SchemaRef)
+	visit = func(ref *openapi3.SchemaRef) {
+		if ref == nil || ref.Value == nil || seen[ref.Value] {
+			return
+		}
+		schema := ref.Value
+		seen[schema] = true
+		if len(schema.Properties) > 0 && schema.AdditionalProperties.Has == nil && schema.AdditionalProperties.Schema == nil {
+			schema.AdditionalProperties.Has = openapi3.Ptr(false)
+		}
+		for _, property := range schema.Properties {
+			visit(property)
+		}
+		visit(schema.Items)
+		visit(schema.AdditionalProperties.Schema)
+	}
+	visitContent := func(content openapi3.Content) {
+		for _, mediaType := range content {
+			visit(mediaType.Schema)
+		}
+	}
+
+	for _, ref := range doc.Components.Schemas {
+		visit(ref)
+	}
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				visitContent(op.RequestBody.Value.Content)
+			}
+			for _, response := range op.Responses.Map() {
+				if response.Value != nil {
+					visitContent(response.Value.Content)
+				}
+			}
+		}
+	}
+}
+
+// routeRequest matches a request to an operation of the API specification.  HEAD requests are matched to
+// the GET operation of their path, as the mux serves them with its handler.
+func routeRequest(router routers.Router, r *http.Request) (*http.Request, *routers.Route, map[string]string, error) {
+	if r.Method == http.MethodHead {
+		r = r.Clone(r.Context())
+		r.Method = http.MethodGet
+	}
+	route, pathParams, err := router.FindRoute(r)
+	return r, route, pathParams, err
+}
+
+// writeRouteError reports a request that does not match any operation of the API specification.
+func writeRouteError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, routers.ErrPathNotFound):
+		writeError(w, http.StatusNotFound, apiError(internal.CodeNotFound, fmt.Sprintf("no operation at %s", r.URL.Path)))
+	case errors.Is(err, routers.ErrMethodNotAllowed):
+		writeError(w, http.StatusMethodNotAllowed, apiError(internal.CodeInvalidRequest, fmt.Sprintf("method %s is not allowed at %s", r.Method, r.URL.Path)))
+	default:
+		writeError(w, http.StatusBadRequest, apiError(internal.CodeInvalidRequest, err.Error()))
+	}
+}
+
+// withValidation checks requests against the API specification before they reach next, and rejects
+// those that do not match with a 400 error.  Requests for paths or methods that the specification does
+// not know about are rejected with a 404 or 405 error rather than being left to the mux, so that no request
+// reaches a handler without being validated.  HEAD requests are validated as GET requests.  If the server is
+// configured to validate responses, they are checked as well and a response that does not match the
+// specification is replaced with a 500 error.
+func (s *Server) withValidation(next http.Handler) http.Handler {
+	router, err := apiRouter()
+	if err != nil {
+		panic(fmt.Errorf("failed to build API router: %w", err))
+	}
+	validateResponses := s.Config != nil && s.Config.ValidateResponses
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routed, route, pathParams, err := routeRequest(router, r)
+		if err != nil {
+			writeRouteError(w, r, err)
+			return
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    routed,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				// withAuthentication has already checked the credentials, before the request got here, so
+				// that callers without them are rejected before they can learn anything from validation errors.
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			if body, ok := requestTooLarge(err); ok {
+				writeError(w, http.StatusRequestEntityTooLarge, body)
+				return
+			}
+			writeError(w, http.StatusBadRequest, validationError(err))
+			return
+		}
+
+		// Responses to HEAD requests have no body, so they cannot be checked against the GET operation.
+		if !validateResponses || r.Method == http.MethodHead || isStreamingOperation(route.Operation) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &responseRecorder{header: http.Header{}}
+		next.ServeHTTP(rec, r)
+		rec.WriteHeader(http.StatusOK)
+		if err := validateResponse(r.Context(), input, rec); err != nil {
+			log.Printf("Response to %s %s does not match the API specification: %v", r.Method, r.URL.Path, err)
+			writeError(w, http.StatusInternalServerError, apiError(internal.CodeInternal, fmt.Sprintf("response does not match the API specification: %v", err)))
+			return
+		}
+		rec.copyTo(w)
+	})
+}
+
+// validateResponse checks a recorded response against the operation that the request was matched to.
+func validateResponse(ctx context.Context, input *openapi3filter.RequestValidationInput, rec *responseRecorder) error {
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 rec.status,
+		Header:                 rec.header,
+		Options:                input.Options,
+	}
+	responseInput.SetBodyBytes(rec.body.Bytes())
+	return openapi3filter.ValidateResponse(ctx, responseInput)
+}
+
+// isStreamingOperation reports whether an operation responds with a stream of events, which cannot
+// be recorded and validated as a whole.
+func isStreamingOperation(op *openapi3.Operation) bool {
+	for _, response := range op.Responses.Map() {
+		if response.Value != nil && response.Value.Content.Get("text/event-stream") != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// specError is the reason kin-openapi gives for a field not matching the specification, classified by
+// the validation sentinel that it corresponds to.
+type specError struct {
+	reason string
+	kind   error
+}
+
+func (e *specError) Error() string {
+	return e.reason
+}
+
+func (e *specError) Unwrap() error {
+	return e.kind
+}
+
+// validationError builds the body of the response to a request that does not match the specification,
+// in the same form as the errors the handlers report for invalid fields.
+func validationError(err error) vcrest.Error {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return apiError(internal.CodeInvalidRequest, err.Error())
+	}
+	if errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired) && reqErr.Parameter == nil {
+		return apiError(internal.CodeInvalidRequest, "request body is required")
+	}
+
+	field, where := "", "invalid request"
+	fieldErr := &specError{reason: reqErr.Reason, kind: internal.ErrInvalid}
+	switch {
+	case reqErr.Parameter != nil:
+		field = reqErr.Parameter.Name
+		where = fmt.Sprintf("invalid %s parameter", reqErr.Parameter.In)
+		if errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired) {
+			fieldErr = &specError{reason: internal.ErrRequired.Error(), kind: internal.ErrRequired}
+		} else if reqErr.Err != nil && (fieldErr.reason == "" || errors.As(reqErr.Err, new(*openapi3filter.ParseError))) {
+			fieldErr = &specError{reason: reqErr.Err.Error(), kind: internal.ErrWrongType}
+		}
+	case reqErr.RequestBody != nil:
+		where = "invalid request body"
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(reqErr.Err, &schemaErr) {
+		field = joinFieldPath(field, schemaErr.JSONPointer())
+		fieldErr = &specError{reason: schemaErr.Reason, kind: internal.ErrInvalid}
+		var property string
+		switch {
+		case schemaErr.SchemaField == "type":
+			fieldErr.kind = internal.ErrWrongType
+		case schemaErr.SchemaField == "nullable":
+			fieldErr.kind = internal.ErrNull
+		case schemaErr.SchemaField == "format" && schemaErr.Schema != nil && schemaErr.Schema.Format == "uuid":
+			fieldErr.kind = internal.ErrInvalidUUID
+		case schemaErr.SchemaField == "required":
+			fieldErr = &specError{reason: internal.ErrRequired.Error(), kind: internal.ErrRequired}
+		case schemaErr.SchemaField == "properties":
+			// The pointer is to the object, so the property is only named in the reason.
+			fmt.Sscanf(schemaErr.Reason, "property %q is unsupported", &property)
+			fieldErr = &specError{reason: internal.ErrUnknownField.Error(), kind: internal.ErrUnknownField}
+		}
+		if property != "" {
+			field = joinFieldPath(field, []string{property})
+		}
+	} else if reqErr.Parameter == nil {
+		// The body could not be decoded, so there is no field to blame.
+		return apiError(internal.CodeInvalidRequest, fmt.Sprintf("%s: %v", where, reqErr.Err))
+	}
+
+	body := invalidRequest(internal.NewFieldError(field, fieldErr))
+	body.Message = fmt.Sprintf("%s: %s", where, body.Message)
+	return body
+}
+
+// joinFieldPath appends the segments of a JSON pointer to a field path in the form used by
+// internal.FieldError, such as alternateTitles[0].title.
+func joinFieldPath(field string, pointer []string) string {
+	for _, segment := range pointer {
+		if _, err := strconv.Atoi(segment); err == nil {
+			field += "[" + segment + "]"
+		} else if field == "" {
+			field = segment
+		} else {
+			field += "." + segment
+		}
+	}
+	return field
+}